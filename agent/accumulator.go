@@ -13,6 +13,11 @@ type MetricMaker interface {
 	Log() telegraf.Logger
 }
 
+// errorRecorder is implemented by plugins keeping track of their errors.
+type errorRecorder interface {
+	RecordError(err error)
+}
+
 type accumulator struct {
 	maker     MetricMaker
 	metrics   chan<- telegraf.Metric
@@ -103,6 +108,9 @@ func (ac *accumulator) AddError(err error) {
 		return
 	}
 	ac.maker.Log().Errorf("Error in plugin: %v", err)
+	if r, ok := ac.maker.(errorRecorder); ok {
+		r.RecordError(err)
+	}
 }
 
 func (ac *accumulator) SetPrecision(precision time.Duration) {
@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"runtime"
+	"sort"
 	"sync"
 	"time"
 
@@ -24,12 +25,24 @@ import (
 // Agent runs a set of plugins.
 type Agent struct {
 	Config *config.Config
+
+	// Units of the running agent used to apply configuration changes, nil if
+	// the agent is not running
+	running   *runningUnits
+	runningMu sync.Mutex
+
+	// Secret-stores running background tasks, guarded by runningMu
+	secretStores []telegraf.SecretStoreService
+
+	// Limits of the concurrent Gather calls
+	scheduler *gatherScheduler
 }
 
 // NewAgent returns an Agent for the given Config.
 func NewAgent(cfg *config.Config) *Agent {
 	a := &Agent{
-		Config: cfg,
+		Config:    cfg,
+		scheduler: newGatherScheduler(cfg.Agent.MaxConcurrentGathers, cfg.Agent.MaxConcurrentGathersPerPlugin),
 	}
 	return a
 }
@@ -48,6 +61,22 @@ func NewAgent(cfg *config.Config) *Agent {
 type inputUnit struct {
 	dst    chan<- telegraf.Metric
 	inputs []*models.RunningInput
+
+	// Gather loops of the running inputs, started by runInputs
+	gatherers map[*models.RunningInput]*gatherWorker
+	started   bool
+	wg        sync.WaitGroup
+
+	// Processor chains attached to individual inputs
+	chains map[*models.RunningInput]*processorGeneration
+}
+
+// gatherWorker controls the gather loop of a single input.
+type gatherWorker struct {
+	cancel  context.CancelFunc
+	ticker  Ticker
+	trigger chan struct{}
+	done    chan struct{}
 }
 
 //  ______     ┌───────────┐     ______
@@ -99,6 +128,28 @@ type aggregatorUnit struct {
 type outputUnit struct {
 	src     <-chan telegraf.Metric
 	outputs []*models.RunningOutput
+
+	// Protects the outputs from being modified on configuration reload while
+	// metrics are dispatched
+	sync.RWMutex
+	ctx      context.Context
+	flushers map[*models.RunningOutput]*flushWorker
+	wg       sync.WaitGroup
+}
+
+// flushWorker controls the flush loop of a single output.
+type flushWorker struct {
+	cancel  context.CancelFunc
+	trigger chan struct{}
+	done    chan struct{}
+}
+
+// runningUnits references the units of a running agent.
+type runningUnits struct {
+	ctx       context.Context
+	inputs    *inputUnit
+	processor *processorChain
+	outputs   *outputUnit
 }
 
 // Run starts and runs the Agent until the context is done.
@@ -122,6 +173,13 @@ func (a *Agent) Run(ctx context.Context) error {
 		return err
 	}
 
+	log.Printf("D! [agent] Starting secret-stores")
+	err := a.startSecretStores(a.Config.SecretStores)
+	defer a.stopSecretStores()
+	if err != nil {
+		return err
+	}
+
 	if a.Config.Persister != nil {
 		log.Printf("D! [agent] Initializing plugin states")
 		if err := a.initPersister(); err != nil {
@@ -135,6 +193,29 @@ func (a *Agent) Run(ctx context.Context) error {
 		}
 	}
 
+	// Listen on the control address before starting the plugins to fail
+	// early on invalid addresses
+	var control *controlServer
+	if a.Config.Agent.ControlAddress != "" {
+		var err error
+		control, err = newControlServer(a, a.Config.Agent.ControlAddress)
+		if err != nil {
+			return err
+		}
+		defer control.stop()
+	}
+
+	// Serve the internal statistics before connecting the outputs to be able
+	// to observe the agent even if outputs fail to connect
+	if a.Config.Agent.SelfstatAddress != "" {
+		selfstats, err := newSelfstatServer(a.Config.Agent.SelfstatAddress)
+		if err != nil {
+			return err
+		}
+		defer selfstats.stop()
+		selfstats.start()
+	}
+
 	startTime := time.Now()
 
 	log.Printf("D! [agent] Connecting outputs")
@@ -157,12 +238,11 @@ func (a *Agent) Run(ctx context.Context) error {
 		next, au = a.startAggregators(aggC, next, a.Config.Aggregators)
 	}
 
-	var pu []*processorUnit
-	if len(a.Config.Processors) != 0 {
-		next, pu, err = a.startProcessors(next, a.Config.Processors)
-		if err != nil {
-			return err
-		}
+	// Always use a processor chain, even without processors, to be able to
+	// add processors when reloading the configuration.
+	next, pc, err := a.startProcessorChain(next, a.Config.Processors)
+	if err != nil {
+		return err
 	}
 
 	iu, err := a.startInputs(next, a.Config.Inputs)
@@ -170,6 +250,19 @@ func (a *Agent) Run(ctx context.Context) error {
 		return err
 	}
 
+	a.runningMu.Lock()
+	a.running = &runningUnits{
+		ctx:       ctx,
+		inputs:    iu,
+		processor: pc,
+		outputs:   ou,
+	}
+	a.runningMu.Unlock()
+
+	if control != nil {
+		control.start()
+	}
+
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
@@ -177,28 +270,34 @@ func (a *Agent) Run(ctx context.Context) error {
 		a.runOutputs(ou)
 	}()
 
-	if au != nil {
+	if a.Config.Persister != nil && a.Config.Agent.StatefileInterval > 0 {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			a.runProcessors(apu)
+			a.checkpointStates(ctx, time.Duration(a.Config.Agent.StatefileInterval))
 		}()
+	}
 
+	if au != nil {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			a.runAggregators(startTime, au)
+			a.runProcessors(apu)
 		}()
-	}
 
-	if pu != nil {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			a.runProcessors(pu)
+			a.runAggregators(startTime, au)
 		}()
 	}
 
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		a.runProcessorChain(pc)
+	}()
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -221,13 +320,8 @@ func (a *Agent) Run(ctx context.Context) error {
 // InitPlugins runs the Init function on plugins.
 func (a *Agent) InitPlugins() error {
 	for _, input := range a.Config.Inputs {
-		// Share the snmp translator setting with plugins that need it.
-		if tp, ok := input.Input.(snmp.TranslatorPlugin); ok {
-			tp.SetTranslator(a.Config.Agent.SnmpTranslator)
-		}
-		err := input.Init()
-		if err != nil {
-			return fmt.Errorf("could not initialize input %s: %w", input.LogName(), err)
+		if err := a.initInput(input); err != nil {
+			return err
 		}
 	}
 	for _, processor := range a.Config.Processors {
@@ -256,9 +350,83 @@ func (a *Agent) InitPlugins() error {
 			return fmt.Errorf("could not initialize output %s: %w", output.LogName(), err)
 		}
 	}
+	return checkForwards(a.Config.Outputs)
+}
+
+// startSecretStores starts the background tasks of the given secret-stores,
+// e.g. renewing leases, and keeps track of the stores to stop them when the
+// agent stops. The stores keep running when reloading the configuration as
+// the reloaded configuration reuses the running secret-stores.
+func (a *Agent) startSecretStores(stores map[string]telegraf.SecretStore) error {
+	a.runningMu.Lock()
+	defer a.runningMu.Unlock()
+
+	ids := make([]string, 0, len(stores))
+	for id := range stores {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		service, ok := stores[id].(telegraf.SecretStoreService)
+		if !ok {
+			continue
+		}
+		if err := service.Start(); err != nil {
+			return fmt.Errorf("starting secret-store %q failed: %w", id, err)
+		}
+		a.secretStores = append(a.secretStores, service)
+	}
 	return nil
 }
 
+// stopSecretStores stops the background tasks of all started secret-stores.
+func (a *Agent) stopSecretStores() {
+	a.runningMu.Lock()
+	defer a.runningMu.Unlock()
+
+	for _, service := range a.secretStores {
+		service.Stop()
+	}
+	a.secretStores = nil
+}
+
+// initInput runs the Init function of the given input.
+func (a *Agent) initInput(input *models.RunningInput) error {
+	// Share the snmp translator setting with plugins that need it.
+	if tp, ok := input.Input.(snmp.TranslatorPlugin); ok {
+		tp.SetTranslator(a.Config.Agent.SnmpTranslator)
+	}
+	if err := input.Init(); err != nil {
+		return fmt.Errorf("could not initialize input %s: %w", input.LogName(), err)
+	}
+	for _, processor := range input.Processors {
+		if err := processor.Init(); err != nil {
+			return fmt.Errorf("could not initialize processor %s of input %s: %w", processor.LogName(), input.LogName(), err)
+		}
+	}
+	return nil
+}
+
+// checkpointStates periodically stores the plugin states until the context
+// is cancelled, so the states survive a crash of Telegraf.
+func (a *Agent) checkpointStates(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			log.Printf("D! [agent] Checkpointing plugin states")
+			if err := a.Config.Persister.Store(); err != nil {
+				log.Printf("E! [agent] Storing plugin states failed: %v", err)
+			}
+		}
+	}
+}
+
 // initPersister initializes the persister and registers the plugins.
 func (a *Agent) initPersister() error {
 	if err := a.Config.Persister.Init(); err != nil {
@@ -278,6 +446,21 @@ func (a *Agent) initPersister() error {
 		}
 	}
 
+	for _, input := range a.Config.Inputs {
+		for _, processor := range input.Processors {
+			plugin, ok := unwrapProcessor(processor).(telegraf.StatefulPlugin)
+			if !ok {
+				continue
+			}
+
+			name := processor.LogName()
+			id := processor.ID()
+			if err := a.Config.Persister.Register(id, plugin); err != nil {
+				return fmt.Errorf("could not register processor %s of input %s: %w", name, input.LogName(), err)
+			}
+		}
+	}
+
 	for _, processor := range a.Config.Processors {
 		var plugin telegraf.StatefulPlugin
 		if p, ok := processor.Processor.(processors.HasUnwrap); ok {
@@ -341,11 +524,12 @@ func (a *Agent) initPersister() error {
 	return nil
 }
 
-func (*Agent) startInputs(dst chan<- telegraf.Metric, inputs []*models.RunningInput) (*inputUnit, error) {
+func (a *Agent) startInputs(dst chan<- telegraf.Metric, inputs []*models.RunningInput) (*inputUnit, error) {
 	log.Printf("D! [agent] Starting service inputs")
 
 	unit := &inputUnit{
-		dst: dst,
+		dst:       dst,
+		gatherers: make(map[*models.RunningInput]*gatherWorker, len(inputs)),
 	}
 
 	for _, input := range inputs {
@@ -361,10 +545,20 @@ func (*Agent) startInputs(dst chan<- telegraf.Metric, inputs []*models.RunningIn
 			precision = input.Config.Precision
 		}
 
-		acc := NewAccumulator(input, dst)
+		inputDst, err := a.startInputProcessors(unit, input)
+		if err != nil {
+			stopRunningInputs(unit.inputs)
+			stopAllInputProcessors(unit)
+
+			return nil, fmt.Errorf("starting processors of input %s: %w", input.LogName(), err)
+		}
+
+		acc := NewAccumulator(input, inputDst)
 		acc.SetPrecision(getPrecision(precision, interval))
 
 		if err := input.Start(acc); err != nil {
+			stopInputProcessors(unit, input)
+
 			// If the model tells us to remove the plugin we do so without error
 			var fatalErr *internal.FatalError
 			if errors.As(err, &fatalErr) {
@@ -373,6 +567,7 @@ func (*Agent) startInputs(dst chan<- telegraf.Metric, inputs []*models.RunningIn
 			}
 
 			stopRunningInputs(unit.inputs)
+			stopAllInputProcessors(unit)
 
 			return nil, fmt.Errorf("starting input %s: %w", input.LogName(), err)
 		}
@@ -380,6 +575,7 @@ func (*Agent) startInputs(dst chan<- telegraf.Metric, inputs []*models.RunningIn
 			// Probe failures are non-fatal to the agent but should only remove the plugin
 			log.Printf("I! [agent] Failed to probe %s, shutting down plugin: %s", input.LogName(), err)
 			input.Stop()
+			stopInputProcessors(unit, input)
 			continue
 		}
 		unit.inputs = append(unit.inputs, input)
@@ -397,67 +593,183 @@ func (a *Agent) runInputs(
 	startTime time.Time,
 	unit *inputUnit,
 ) {
-	var wg sync.WaitGroup
-	tickers := make([]Ticker, 0, len(unit.inputs))
+	// Inputs added by a reload before this point are already part of the
+	// unit, inputs added afterwards start their gather loop in addInput.
+	a.runningMu.Lock()
 	for _, input := range unit.inputs {
-		// Overwrite agent interval if this plugin has its own.
-		interval := time.Duration(a.Config.Agent.Interval)
-		if input.Config.Interval != 0 {
-			interval = input.Config.Interval
-		}
+		a.startGatherLoop(ctx, unit, input, startTime)
+	}
+	unit.started = true
+	a.runningMu.Unlock()
 
-		// Overwrite agent precision if this plugin has its own.
-		precision := time.Duration(a.Config.Agent.Precision)
-		if input.Config.Precision != 0 {
-			precision = input.Config.Precision
-		}
+	// Wait for the agent to stop and prevent any further configuration
+	// changes from being applied before shutting down the inputs.
+	<-ctx.Done()
+	a.runningMu.Lock()
+	a.running = nil
+	a.runningMu.Unlock()
 
-		// Overwrite agent collection_jitter if this plugin has its own.
-		jitter := time.Duration(a.Config.Agent.CollectionJitter)
-		if input.Config.CollectionJitter != 0 {
-			jitter = input.Config.CollectionJitter
-		}
+	unit.wg.Wait()
+	for _, gatherer := range unit.gatherers {
+		gatherer.ticker.Stop()
+	}
 
-		// Overwrite agent collection_offset if this plugin has its own.
-		offset := time.Duration(a.Config.Agent.CollectionOffset)
-		if input.Config.CollectionOffset != 0 {
-			offset = input.Config.CollectionOffset
-		}
+	log.Printf("D! [agent] Stopping service inputs")
+	stopRunningInputs(unit.inputs)
+	stopAllInputProcessors(unit)
 
-		var ticker Ticker
-		if a.Config.Agent.RoundInterval {
-			ticker = NewAlignedTicker(startTime, interval, jitter, offset)
-		} else {
-			ticker = NewUnalignedTicker(interval, jitter, offset)
+	close(unit.dst)
+	log.Printf("D! [agent] Input channel closed")
+}
+
+// startGatherLoop starts the periodic gather for the given input using a
+// ticker according to the input's interval settings.
+func (a *Agent) startGatherLoop(
+	ctx context.Context,
+	unit *inputUnit,
+	input *models.RunningInput,
+	startTime time.Time,
+) {
+	interval := a.inputInterval(input, startTime)
+
+	// Overwrite agent precision if this plugin has its own.
+	precision := time.Duration(a.Config.Agent.Precision)
+	if input.Config.Precision != 0 {
+		precision = input.Config.Precision
+	}
+
+	// Overwrite agent collection_jitter if this plugin has its own.
+	jitter := time.Duration(a.Config.Agent.CollectionJitter)
+	if input.Config.CollectionJitter != 0 {
+		jitter = input.Config.CollectionJitter
+	}
+
+	// Overwrite agent collection_offset if this plugin has its own.
+	offset := time.Duration(a.Config.Agent.CollectionOffset)
+	if input.Config.CollectionOffset != 0 {
+		offset = input.Config.CollectionOffset
+	}
+
+	if a.Config.Agent.CollectionSpread {
+		offset += spreadOffset(input.ID(), interval)
+	}
+
+	var ticker Ticker
+	if input.Config.Schedule != nil {
+		ticker = NewScheduleTicker(input.Config.Schedule, jitter, offset)
+	} else if a.Config.Agent.RoundInterval {
+		ticker = NewAlignedTicker(startTime, interval, jitter, offset)
+	} else {
+		ticker = NewUnalignedTicker(interval, jitter, offset)
+	}
+
+	acc := NewAccumulator(input, unit.inputDst(input))
+	acc.SetPrecision(getPrecision(precision, interval))
+
+	ctx, cancel := context.WithCancel(ctx)
+	gatherer := &gatherWorker{
+		cancel:  cancel,
+		ticker:  ticker,
+		trigger: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	unit.gatherers[input] = gatherer
+
+	unit.wg.Add(1)
+	go func() {
+		defer unit.wg.Done()
+		defer close(gatherer.done)
+		a.gatherLoop(ctx, acc, input, ticker, gatherer.trigger, interval)
+	}()
+}
+
+// inputInterval returns the interval of the given input used for the
+// precision, the collection spread, the backoff and the warning about slow
+// gathers. For scheduled inputs this is the shortest time between two
+// scheduled collections after the given time.
+func (a *Agent) inputInterval(input *models.RunningInput, t time.Time) time.Duration {
+	if input.Config.Schedule != nil {
+		if interval := input.Config.Schedule.Interval(t); interval > 0 {
+			return interval
 		}
-		tickers = append(tickers, ticker)
+	}
 
-		acc := NewAccumulator(input, unit.dst)
-		acc.SetPrecision(getPrecision(precision, interval))
+	// Overwrite agent interval if this plugin has its own.
+	if input.Config.Interval != 0 {
+		return input.Config.Interval
+	}
+	return time.Duration(a.Config.Agent.Interval)
+}
 
-		wg.Add(1)
-		go func(input *models.RunningInput) {
-			defer wg.Done()
-			a.gatherLoop(ctx, acc, input, ticker, interval)
-		}(input)
+// stopGatherLoop stops the periodic gather of the given input and waits for
+// an ongoing Gather call to complete.
+func stopGatherLoop(unit *inputUnit, input *models.RunningInput) {
+	gatherer, found := unit.gatherers[input]
+	if !found {
+		return
 	}
-	defer stopTickers(tickers)
-	wg.Wait()
+	delete(unit.gatherers, input)
 
-	log.Printf("D! [agent] Stopping service inputs")
-	stopRunningInputs(unit.inputs)
+	gatherer.cancel()
+	<-gatherer.done
+	gatherer.ticker.Stop()
+}
 
-	close(unit.dst)
-	log.Printf("D! [agent] Input channel closed")
+// startInputProcessors starts the processors attached to the given input and
+// returns the channel the input should write its metrics to. Inputs without
+// processors directly write to the destination of the unit.
+func (a *Agent) startInputProcessors(unit *inputUnit, input *models.RunningInput) (chan<- telegraf.Metric, error) {
+	if len(input.Processors) == 0 {
+		return unit.dst, nil
+	}
+
+	chain, err := a.startProcessorGeneration(unit.dst, input.Processors)
+	if err != nil {
+		return nil, err
+	}
+	if unit.chains == nil {
+		unit.chains = make(map[*models.RunningInput]*processorGeneration)
+	}
+	unit.chains[input] = chain
+	return chain.head, nil
+}
+
+// inputDst returns the channel the given input writes its metrics to.
+func (unit *inputUnit) inputDst(input *models.RunningInput) chan<- telegraf.Metric {
+	if chain, found := unit.chains[input]; found {
+		return chain.head
+	}
+	return unit.dst
+}
+
+// stopInputProcessors stops the processors attached to the given input and
+// waits for all metrics to be passed on. The input must be stopped already.
+func stopInputProcessors(unit *inputUnit, input *models.RunningInput) {
+	chain, found := unit.chains[input]
+	if !found {
+		return
+	}
+	delete(unit.chains, input)
+
+	close(chain.head)
+	<-chain.done
+}
+
+// stopAllInputProcessors stops the processors of all inputs of the unit.
+func stopAllInputProcessors(unit *inputUnit) {
+	for input := range unit.chains {
+		stopInputProcessors(unit, input)
+	}
 }
 
 // testStartInputs is a variation of startInputs for use in --test and --once mode.
 // It differs by logging Start errors and returning only plugins successfully started.
-func (*Agent) testStartInputs(dst chan<- telegraf.Metric, inputs []*models.RunningInput) *inputUnit {
+func (a *Agent) testStartInputs(dst chan<- telegraf.Metric, inputs []*models.RunningInput) *inputUnit {
 	log.Printf("D! [agent] Starting service inputs")
 
 	unit := &inputUnit{
-		dst: dst,
+		dst:       dst,
+		gatherers: make(map[*models.RunningInput]*gatherWorker, len(inputs)),
 	}
 
 	for _, input := range inputs {
@@ -465,11 +777,18 @@ func (*Agent) testStartInputs(dst chan<- telegraf.Metric, inputs []*models.Runni
 		// This only applies to the accumulator passed to Start(), the
 		// Gather() accumulator does apply rounding according to the
 		// precision agent setting.
-		acc := NewAccumulator(input, dst)
+		inputDst, err := a.startInputProcessors(unit, input)
+		if err != nil {
+			log.Printf("E! [agent] Starting processors of input %s: %v", input.LogName(), err)
+			continue
+		}
+
+		acc := NewAccumulator(input, inputDst)
 		acc.SetPrecision(time.Nanosecond)
 
 		if err := input.Start(acc); err != nil {
 			log.Printf("E! [agent] Starting input %s: %v", input.LogName(), err)
+			stopInputProcessors(unit, input)
 			continue
 		}
 
@@ -500,11 +819,13 @@ func (a *Agent) testRunInputs(
 		go func(input *models.RunningInput) {
 			defer wg.Done()
 
-			// Overwrite agent interval if this plugin has its own.
-			interval := time.Duration(a.Config.Agent.Interval)
-			if input.Config.Interval != 0 {
-				interval = input.Config.Interval
+			release, err := a.scheduler.acquire(ctx, input.Config.Name)
+			if err != nil {
+				return
 			}
+			defer release()
+
+			interval := a.inputInterval(input, time.Now())
 
 			// Overwrite agent precision if this plugin has its own.
 			precision := time.Duration(a.Config.Agent.Precision)
@@ -525,7 +846,7 @@ func (a *Agent) testRunInputs(
 				time.Sleep(500 * time.Millisecond)
 			}
 
-			acc := NewAccumulator(input, unit.dst)
+			acc := NewAccumulator(input, unit.inputDst(input))
 			acc.SetPrecision(getPrecision(precision, interval))
 
 			if err := input.Input.Gather(acc); err != nil {
@@ -541,6 +862,7 @@ func (a *Agent) testRunInputs(
 
 	log.Printf("D! [agent] Stopping service inputs")
 	stopRunningInputs(unit.inputs)
+	stopAllInputProcessors(unit)
 
 	close(unit.dst)
 	log.Printf("D! [agent] Input channel closed")
@@ -560,30 +882,72 @@ func stopRunningOutputs(outputs []*models.RunningOutput) {
 	}
 }
 
-// gather runs an input's gather function periodically until the context is
-// done.
+// gather runs an input's gather function periodically and on request until
+// the context is done.
 func (a *Agent) gatherLoop(
 	ctx context.Context,
 	acc telegraf.Accumulator,
 	input *models.RunningInput,
 	ticker Ticker,
+	trigger <-chan struct{},
 	interval time.Duration,
 ) {
+	// Pass on the metrics aggregated due to the cardinality limit when
+	// stopping the input
+	defer input.AddCardinalityOverflow(acc, true)
+
 	for {
 		select {
-		case <-ticker.Elapsed():
-			err := a.gatherOnce(acc, input, ticker, interval)
-			if err != nil {
-				acc.AddError(err)
+		case tick := <-ticker.Elapsed():
+			if input.BackingOff(tick) {
+				log.Printf("D! [%s] Backing off after failures; scheduled collection skipped", input.LogName())
+				continue
 			}
+			if err := a.scheduledGather(ctx, acc, input, ticker, interval); err != nil {
+				return
+			}
+			input.UpdateBackoff(tick, interval)
+		case <-trigger:
+			start := time.Now()
+			if err := a.scheduledGather(ctx, acc, input, ticker, interval); err != nil {
+				return
+			}
+			input.UpdateBackoff(start, interval)
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
+// scheduledGather runs the input's Gather function once as soon as the limits
+// of concurrent Gather calls allow. An error is only returned if the context
+// is done before gathering.
+func (a *Agent) scheduledGather(
+	ctx context.Context,
+	acc telegraf.Accumulator,
+	input *models.RunningInput,
+	ticker Ticker,
+	interval time.Duration,
+) error {
+	release, err := a.scheduler.acquire(ctx, input.Config.Name)
+	if err != nil {
+		return err
+	}
+
+	// Release the limits as soon as the Gather call exceeds the interval to
+	// not starve other inputs if the call hangs
+	release = sync.OnceFunc(release)
+	defer release()
+
+	if err := a.gatherOnce(acc, input, ticker, interval, release); err != nil {
+		acc.AddError(err)
+	}
+	return nil
+}
+
 // gatherOnce runs the input's Gather function once, logging a warning each interval it fails to complete before.
-func (*Agent) gatherOnce(acc telegraf.Accumulator, input *models.RunningInput, ticker Ticker, interval time.Duration) error {
+// The given timeout function is called each time the warning is logged if not nil.
+func (*Agent) gatherOnce(acc telegraf.Accumulator, input *models.RunningInput, ticker Ticker, interval time.Duration, timeout func()) error {
 	done := make(chan error)
 	go func() {
 		defer panicRecover(input)
@@ -604,6 +968,9 @@ func (*Agent) gatherOnce(acc telegraf.Accumulator, input *models.RunningInput, t
 			log.Printf("W! [%s] Collection took longer than expected; not complete after interval of %s",
 				input.LogName(), interval)
 			input.IncrGatherTimeouts()
+			if timeout != nil {
+				timeout()
+			}
 		case <-ticker.Elapsed():
 			log.Printf("D! [%s] Previous collection has not completed; scheduled collection skipped",
 				input.LogName())
@@ -670,6 +1037,132 @@ func (*Agent) runProcessors(units []*processorUnit) {
 	wg.Wait()
 }
 
+//  ______     ┌ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ┐     ______
+// ()_____)──▶   Processor ──▶ ... ──▶ Processor      ──▶ ()_____)
+//             └ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ─ ┘
+//                          (replaceable)
+
+// processorChain decouples a chain of processor units from its source and
+// destination channel, allowing to replace the processors while metrics are
+// flowing.
+type processorChain struct {
+	src <-chan telegraf.Metric
+	dst chan<- telegraf.Metric
+
+	sync.Mutex
+	current *processorGeneration
+	closed  bool
+}
+
+// processorGeneration is one instance of the processor units in a chain.
+type processorGeneration struct {
+	head  chan<- telegraf.Metric
+	units []*processorUnit
+	done  chan struct{}
+}
+
+// startProcessorChain sets up a replaceable processor chain writing to the
+// given destination and returns the source channel of the chain.
+func (a *Agent) startProcessorChain(dst chan<- telegraf.Metric, runningProcessors models.RunningProcessors) (chan<- telegraf.Metric, *processorChain, error) {
+	generation, err := a.startProcessorGeneration(dst, runningProcessors)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	src := make(chan telegraf.Metric, 100)
+	chain := &processorChain{
+		src:     src,
+		dst:     dst,
+		current: generation,
+	}
+	return src, chain, nil
+}
+
+// startProcessorGeneration starts the given processors and forwards the
+// resulting metrics to the destination without closing it.
+func (a *Agent) startProcessorGeneration(dst chan<- telegraf.Metric, runningProcessors models.RunningProcessors) (*processorGeneration, error) {
+	tail := make(chan telegraf.Metric, 100)
+	head := chan<- telegraf.Metric(tail)
+	var units []*processorUnit
+	if len(runningProcessors) > 0 {
+		var err error
+		head, units, err = a.startProcessors(tail, runningProcessors)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	generation := &processorGeneration{
+		head:  head,
+		units: units,
+		done:  make(chan struct{}),
+	}
+
+	go func() {
+		defer close(generation.done)
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runProcessors(units)
+		}()
+
+		for m := range tail {
+			dst <- m
+		}
+		wg.Wait()
+	}()
+
+	return generation, nil
+}
+
+// runProcessorChain passes metrics to the current processors of the chain
+// until the source channel is closed and all metrics have been processed.
+func (*Agent) runProcessorChain(chain *processorChain) {
+	for m := range chain.src {
+		chain.Lock()
+		chain.current.head <- m
+		chain.Unlock()
+	}
+
+	chain.Lock()
+	chain.closed = true
+	generation := chain.current
+	chain.Unlock()
+
+	close(generation.head)
+	<-generation.done
+
+	close(chain.dst)
+	log.Printf("D! [agent] Processor chain closed")
+}
+
+// replaceProcessors replaces the processors of a running chain. Metrics
+// already passed to the previous processors are processed to completion.
+func (a *Agent) replaceProcessors(chain *processorChain, runningProcessors models.RunningProcessors) error {
+	generation, err := a.startProcessorGeneration(chain.dst, runningProcessors)
+	if err != nil {
+		return err
+	}
+
+	chain.Lock()
+	if chain.closed {
+		chain.Unlock()
+		close(generation.head)
+		<-generation.done
+		return errors.New("processor chain already closed")
+	}
+	previous := chain.current
+	chain.current = generation
+	chain.Unlock()
+
+	close(previous.head)
+	<-previous.done
+
+	return nil
+}
+
 // startAggregators sets up the aggregator unit and returns the source channel.
 func (*Agent) startAggregators(aggC, outputC chan<- telegraf.Metric, aggregators []*models.RunningAggregator) (chan<- telegraf.Metric, *aggregatorUnit) {
 	src := make(chan telegraf.Metric, 100)
@@ -829,39 +1322,21 @@ func (*Agent) connectOutput(ctx context.Context, output *models.RunningOutput) e
 func (a *Agent) runOutputs(
 	unit *outputUnit,
 ) {
-	var wg sync.WaitGroup
-
-	// Start flush loop
-	interval := time.Duration(a.Config.Agent.FlushInterval)
-	jitter := time.Duration(a.Config.Agent.FlushJitter)
-
 	ctx, cancel := context.WithCancel(context.Background())
 
+	unit.Lock()
+	unit.ctx = ctx
+	unit.flushers = make(map[*models.RunningOutput]*flushWorker, len(unit.outputs))
 	for _, output := range unit.outputs {
-		interval := interval
-		// Overwrite agent flush_interval if this plugin has its own.
-		if output.Config.FlushInterval != 0 {
-			interval = output.Config.FlushInterval
-		}
-
-		jitter := jitter
-		// Overwrite agent flush_jitter if this plugin has its own.
-		if output.Config.FlushJitter != 0 {
-			jitter = output.Config.FlushJitter
-		}
-
-		wg.Add(1)
-		go func(output *models.RunningOutput) {
-			defer wg.Done()
-
-			ticker := NewRollingTicker(interval, jitter)
-			defer ticker.Stop()
-
-			a.flushLoop(ctx, output, ticker)
-		}(output)
+		a.startFlushLoop(unit, output)
 	}
+	unit.Unlock()
 
 	for metric := range unit.src {
+		unit.RLock()
+		if len(unit.outputs) == 0 {
+			metric.Drop()
+		}
 		for i, output := range unit.outputs {
 			if i == len(unit.outputs)-1 {
 				output.AddMetricNoCopy(metric)
@@ -869,22 +1344,66 @@ func (a *Agent) runOutputs(
 				output.AddMetric(metric)
 			}
 		}
+		unit.RUnlock()
 	}
 
 	log.Println("I! [agent] Hang on, flushing any cached metrics before shutdown")
 	cancel()
-	wg.Wait()
+	unit.wg.Wait()
 
 	log.Println("I! [agent] Stopping running outputs")
 	stopRunningOutputs(unit.outputs)
 }
 
-// flushLoop runs an output's flush function periodically until the context is
-// done.
+// startFlushLoop starts the periodic flush of the given output. The caller
+// must hold the lock of the unit.
+func (a *Agent) startFlushLoop(unit *outputUnit, output *models.RunningOutput) {
+	// Overwrite agent flush_interval if this plugin has its own.
+	interval := time.Duration(a.Config.Agent.FlushInterval)
+	if output.Config.FlushInterval != 0 {
+		interval = output.Config.FlushInterval
+	}
+
+	// Overwrite agent flush_jitter if this plugin has its own.
+	jitter := time.Duration(a.Config.Agent.FlushJitter)
+	if output.Config.FlushJitter != 0 {
+		jitter = output.Config.FlushJitter
+	}
+
+	ctx, cancel := context.WithCancel(unit.ctx)
+	flusher := &flushWorker{
+		cancel:  cancel,
+		trigger: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	unit.flushers[output] = flusher
+
+	if output.Config.Retry != nil && output.Config.Retry.DeadLetter != "" {
+		output.DeadLetter = forwarder(unit, output, output.Config.Retry.DeadLetter)
+	}
+	if output.Config.OnReject != "" {
+		output.OnReject = forwarder(unit, output, output.Config.OnReject)
+	}
+
+	unit.wg.Add(1)
+	go func() {
+		defer unit.wg.Done()
+		defer close(flusher.done)
+
+		ticker := NewRollingTicker(interval, jitter)
+		defer ticker.Stop()
+
+		a.flushLoop(ctx, output, ticker, flusher.trigger)
+	}()
+}
+
+// flushLoop runs an output's flush function periodically and on request until
+// the context is done.
 func (a *Agent) flushLoop(
 	ctx context.Context,
 	output *models.RunningOutput,
 	ticker Ticker,
+	trigger <-chan struct{},
 ) {
 	logError := func(err error) {
 		if err != nil {
@@ -897,23 +1416,27 @@ func (a *Agent) flushLoop(
 	watchForFlushSignal(flushRequested)
 	defer stopListeningForFlushSignal(flushRequested)
 
+	// Final and requested flushes ignore the delay of outputs backing off
+	// after failed writes as otherwise the metrics might be lost.
 	for {
 		// Favor shutdown over other methods.
 		select {
 		case <-ctx.Done():
-			logError(a.flushOnce(output, ticker, output.Write))
+			logError(a.flushOnce(output, ticker, output.Flush))
 			return
 		default:
 		}
 
 		select {
 		case <-ctx.Done():
-			logError(a.flushOnce(output, ticker, output.Write))
+			logError(a.flushOnce(output, ticker, output.Flush))
 			return
 		case <-ticker.Elapsed():
 			logError(a.flushOnce(output, ticker, output.Write))
 		case <-flushRequested:
-			logError(a.flushOnce(output, ticker, output.Write))
+			logError(a.flushOnce(output, ticker, output.Flush))
+		case <-trigger:
+			logError(a.flushOnce(output, ticker, output.Flush))
 		case <-output.BatchReady:
 			logError(a.flushBatch(output, output.WriteBatch))
 		}
@@ -1207,9 +1730,3 @@ func panicRecover(input *models.RunningInput) {
 			"https://github.com/influxdata/telegraf/issues/new/choose")
 	}
 }
-
-func stopTickers(tickers []Ticker) {
-	for _, ticker := range tickers {
-		ticker.Stop()
-	}
-}
@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -228,6 +229,85 @@ func TestCases(t *testing.T) {
 	}
 }
 
+func TestAgent_FlushOnStopWhileBackingOff(t *testing.T) {
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadConfigData([]byte(`
+[agent]
+  interval = "100ms"
+  flush_interval = "1h"
+  skip_processors_after_aggregators = true
+
+[[inputs.mock]]
+  metric_name = "test"
+  [[inputs.mock.constant]]
+    name = "value"
+    value = 1
+`), config.EmptySourcePath))
+
+	// Fail the first write to delay all further writes for an hour
+	plugin := &failingOutput{failures: 1}
+	output := models.NewRunningOutput(plugin, &models.OutputConfig{
+		Name:  "failing",
+		Retry: &models.RetryConfig{InitialDelay: time.Hour},
+	}, 1, 100)
+	cfg.Outputs = append(cfg.Outputs, output)
+	a := NewAgent(cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		plugin.Lock()
+		defer plugin.Unlock()
+		return plugin.writes > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Stopping the agent must write the buffered metrics despite the delay
+	cancel()
+	require.NoError(t, <-done)
+
+	plugin.Lock()
+	defer plugin.Unlock()
+	require.Greater(t, plugin.writes, 1)
+	require.NotEmpty(t, plugin.metrics)
+}
+
+type failingOutput struct {
+	failures int
+	writes   int
+	metrics  []telegraf.Metric
+	sync.Mutex
+}
+
+func (*failingOutput) SampleConfig() string {
+	return ""
+}
+
+func (*failingOutput) Connect() error {
+	return nil
+}
+
+func (*failingOutput) Close() error {
+	return nil
+}
+
+func (o *failingOutput) Write(metrics []telegraf.Metric) error {
+	o.Lock()
+	defer o.Unlock()
+
+	o.writes++
+	if o.writes <= o.failures {
+		return errors.New("failed")
+	}
+	o.metrics = append(o.metrics, metrics...)
+	return nil
+}
+
 // Implement a "test-mode" like call but collect the metrics
 func collect(ctx context.Context, a *Agent, wait time.Duration) ([]telegraf.Metric, error) {
 	var received []telegraf.Metric
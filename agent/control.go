@@ -0,0 +1,256 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// controlServer serves the local control API of a running agent.
+type controlServer struct {
+	agent    *Agent
+	listener net.Listener
+	server   *http.Server
+}
+
+// pluginStatus is the API representation of a running plugin.
+type pluginStatus struct {
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	Alias         string     `json:"alias,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorTime *time.Time `json:"last_error_time,omitempty"`
+	BufferLength  *int       `json:"buffer_length,omitempty"`
+	BufferLimit   *int       `json:"buffer_limit,omitempty"`
+	// Processors attached to an input
+	Processors []pluginStatus `json:"processors,omitempty"`
+}
+
+// pluginsStatus is the API representation of all running plugins.
+type pluginsStatus struct {
+	Inputs      []pluginStatus `json:"inputs"`
+	Processors  []pluginStatus `json:"processors"`
+	Aggregators []pluginStatus `json:"aggregators"`
+	Outputs     []pluginStatus `json:"outputs"`
+}
+
+// newControlServer creates the control API server listening on the given
+// address. The server does not handle requests before calling start.
+func newControlServer(a *Agent, address string) (*controlServer, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("listening on control address %q failed: %w", address, err)
+	}
+
+	s := &controlServer{
+		agent:    a,
+		listener: listener,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /plugins", s.handlePlugins)
+	mux.HandleFunc("POST /gather", s.handleGather)
+	mux.HandleFunc("POST /inputs/{id}/gather", s.handleGather)
+	mux.HandleFunc("POST /flush", s.handleFlush)
+	mux.HandleFunc("POST /outputs/{id}/flush", s.handleFlush)
+
+	s.server = &http.Server{
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+
+	return s, nil
+}
+
+// start serves the control API in the background.
+func (s *controlServer) start() {
+	log.Printf("I! [agent] Serving control API on %s", s.listener.Addr())
+	go func() {
+		if err := s.server.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("E! [agent] Serving control API failed: %v", err)
+		}
+	}()
+}
+
+// stop shuts down the control API waiting for active requests to complete.
+func (s *controlServer) stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := s.server.Shutdown(ctx); err != nil {
+		log.Printf("E! [agent] Stopping control API failed: %v", err)
+	}
+	// The listener is not closed by the server if it was never served
+	s.listener.Close()
+}
+
+func (s *controlServer) handlePlugins(w http.ResponseWriter, _ *http.Request) {
+	status, err := s.agent.pluginsStatus()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		log.Printf("E! [agent] Encoding control API response failed: %v", err)
+	}
+}
+
+func (s *controlServer) handleGather(w http.ResponseWriter, r *http.Request) {
+	triggered, err := s.agent.triggerGather(r.PathValue("id"))
+	s.respondTriggered(w, triggered, err)
+}
+
+func (s *controlServer) handleFlush(w http.ResponseWriter, r *http.Request) {
+	triggered, err := s.agent.triggerFlush(r.PathValue("id"))
+	s.respondTriggered(w, triggered, err)
+}
+
+func (*controlServer) respondTriggered(w http.ResponseWriter, triggered int, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if triggered == 0 {
+		http.Error(w, "no matching plugin found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// pluginsStatus returns the status of the running plugins.
+func (a *Agent) pluginsStatus() (*pluginsStatus, error) {
+	a.runningMu.Lock()
+	defer a.runningMu.Unlock()
+
+	running := a.running
+	if running == nil {
+		return nil, errors.New("agent is not running")
+	}
+
+	status := &pluginsStatus{
+		Inputs:      make([]pluginStatus, 0, len(running.inputs.inputs)),
+		Processors:  make([]pluginStatus, 0, len(a.Config.Processors)),
+		Aggregators: make([]pluginStatus, 0, len(a.Config.Aggregators)),
+	}
+	for _, input := range running.inputs.inputs {
+		s := pluginStatus{
+			ID:    input.ID(),
+			Name:  input.Config.Name,
+			Alias: input.Config.Alias,
+		}
+		if ts, err := input.LastError(); err != nil {
+			s.LastError = err.Error()
+			s.LastErrorTime = &ts
+		}
+		for _, processor := range input.Processors {
+			s.Processors = append(s.Processors, pluginStatus{
+				ID:    processor.ID(),
+				Name:  processor.Config.Name,
+				Alias: processor.Config.Alias,
+			})
+		}
+		status.Inputs = append(status.Inputs, s)
+	}
+	for _, processor := range a.Config.Processors {
+		status.Processors = append(status.Processors, pluginStatus{
+			ID:    processor.ID(),
+			Name:  processor.Config.Name,
+			Alias: processor.Config.Alias,
+		})
+	}
+	for _, aggregator := range a.Config.Aggregators {
+		status.Aggregators = append(status.Aggregators, pluginStatus{
+			ID:    aggregator.ID(),
+			Name:  aggregator.Config.Name,
+			Alias: aggregator.Config.Alias,
+		})
+	}
+
+	unit := running.outputs
+	unit.RLock()
+	defer unit.RUnlock()
+
+	status.Outputs = make([]pluginStatus, 0, len(unit.outputs))
+	for _, output := range unit.outputs {
+		length := output.BufferLength()
+		s := pluginStatus{
+			ID:           output.ID(),
+			Name:         output.Config.Name,
+			Alias:        output.Config.Alias,
+			BufferLength: &length,
+			BufferLimit:  &output.MetricBufferLimit,
+		}
+		if ts, err := output.LastError(); err != nil {
+			s.LastError = err.Error()
+			s.LastErrorTime = &ts
+		}
+		status.Outputs = append(status.Outputs, s)
+	}
+
+	return status, nil
+}
+
+// triggerGather requests an immediate gather of the inputs with the given ID
+// or of all inputs if the ID is empty. The number of triggered inputs is
+// returned.
+func (a *Agent) triggerGather(id string) (int, error) {
+	a.runningMu.Lock()
+	defer a.runningMu.Unlock()
+
+	running := a.running
+	if running == nil {
+		return 0, errors.New("agent is not running")
+	}
+
+	var triggered int
+	for input, gatherer := range running.inputs.gatherers {
+		if id != "" && input.ID() != id {
+			continue
+		}
+		// A pending request is sufficient to gather once more
+		select {
+		case gatherer.trigger <- struct{}{}:
+		default:
+		}
+		triggered++
+	}
+	return triggered, nil
+}
+
+// triggerFlush requests an immediate flush of the outputs with the given ID
+// or of all outputs if the ID is empty. The number of triggered outputs is
+// returned.
+func (a *Agent) triggerFlush(id string) (int, error) {
+	a.runningMu.Lock()
+	running := a.running
+	a.runningMu.Unlock()
+	if running == nil {
+		return 0, errors.New("agent is not running")
+	}
+
+	unit := running.outputs
+	unit.RLock()
+	defer unit.RUnlock()
+
+	var triggered int
+	for output, flusher := range unit.flushers {
+		if id != "" && output.ID() != id {
+			continue
+		}
+		// A pending request is sufficient to flush once more
+		select {
+		case flusher.trigger <- struct{}{}:
+		default:
+		}
+		triggered++
+	}
+	return triggered, nil
+}
@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+func TestControlServer(t *testing.T) {
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadConfigData([]byte(`
+[agent]
+  interval = "1h"
+  flush_interval = "1h"
+  skip_processors_after_aggregators = true
+
+[[inputs.mock]]
+  alias = "foo"
+  metric_name = "foo"
+  [[inputs.mock.constant]]
+    name = "value"
+    value = 1
+
+  [[inputs.mock.processors.rename]]
+    alias = "bar"
+
+[[outputs.discard]]
+`), config.EmptySourcePath))
+	a := NewAgent(cfg)
+
+	s, err := newControlServer(a, "127.0.0.1:0")
+	require.NoError(t, err)
+	defer s.stop()
+
+	request := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.server.Handler.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	// Requests must fail if the agent is not running
+	require.Equal(t, http.StatusServiceUnavailable, request(http.MethodGet, "/plugins").Code)
+	require.Equal(t, http.StatusServiceUnavailable, request(http.MethodPost, "/gather").Code)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Run(ctx)
+	}()
+
+	// Wait for the inputs and outputs to be running
+	require.Eventually(t, func() bool {
+		return request(http.MethodPost, "/gather").Code == http.StatusAccepted &&
+			request(http.MethodPost, "/flush").Code == http.StatusAccepted
+	}, 5*time.Second, 10*time.Millisecond)
+
+	w := request(http.MethodGet, "/plugins")
+	require.Equal(t, http.StatusOK, w.Code)
+	var status pluginsStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	require.Len(t, status.Inputs, 1)
+	require.Equal(t, "mock", status.Inputs[0].Name)
+	require.Equal(t, "foo", status.Inputs[0].Alias)
+	require.Equal(t, cfg.Inputs[0].ID(), status.Inputs[0].ID)
+	require.Empty(t, status.Inputs[0].LastError)
+	require.Len(t, status.Inputs[0].Processors, 1)
+	require.Equal(t, "rename", status.Inputs[0].Processors[0].Name)
+	require.Equal(t, "bar", status.Inputs[0].Processors[0].Alias)
+	require.Equal(t, cfg.Inputs[0].Processors[0].ID(), status.Inputs[0].Processors[0].ID)
+	require.Empty(t, status.Processors)
+	require.Len(t, status.Outputs, 1)
+	require.Equal(t, "discard", status.Outputs[0].Name)
+	require.NotNil(t, status.Outputs[0].BufferLength)
+	require.Equal(t, cfg.Outputs[0].MetricBufferLimit, *status.Outputs[0].BufferLimit)
+
+	require.Equal(t, http.StatusAccepted, request(http.MethodPost, "/inputs/"+cfg.Inputs[0].ID()+"/gather").Code)
+	require.Equal(t, http.StatusNotFound, request(http.MethodPost, "/inputs/unknown/gather").Code)
+	require.Equal(t, http.StatusAccepted, request(http.MethodPost, "/outputs/"+cfg.Outputs[0].ID()+"/flush").Code)
+	require.Equal(t, http.StatusNotFound, request(http.MethodPost, "/outputs/unknown/flush").Code)
+	require.Equal(t, http.StatusMethodNotAllowed, request(http.MethodGet, "/gather").Code)
+
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,167 @@
+package agent
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+// Protects forwarding files shared by multiple outputs
+var forwardFileMu sync.Mutex
+
+// findOutput returns the output with the given alias or, if no alias matches,
+// with the given name.
+func findOutput(outputs []*models.RunningOutput, key string) *models.RunningOutput {
+	for _, output := range outputs {
+		if output.Config.Alias == key {
+			return output
+		}
+	}
+	for _, output := range outputs {
+		if output.Config.Name == key {
+			return output
+		}
+	}
+	return nil
+}
+
+// checkForward verifies the destination of the given output setting used to
+// forward metrics. Destinations referencing other outputs have the form
+// "outputs.<alias or name>", all other destinations are files. The referenced
+// output is returned, nil for files.
+func checkForward(outputs []*models.RunningOutput, output *models.RunningOutput, setting, destination string) (*models.RunningOutput, error) {
+	key, ok := strings.CutPrefix(destination, "outputs.")
+	if !ok {
+		return nil, nil
+	}
+	target := findOutput(outputs, key)
+	if target == nil {
+		return nil, fmt.Errorf("%s output %q of %s not found", setting, key, output.LogName())
+	}
+	if target == output {
+		return nil, fmt.Errorf("%s output of %s must not be the output itself", setting, output.LogName())
+	}
+	return target, nil
+}
+
+// checkForwards verifies the forwarding destinations of all outputs. Outputs
+// forwarding metrics in a cycle are rejected as the metrics rejected by all
+// outputs of the cycle would be passed around forever.
+func checkForwards(outputs []*models.RunningOutput) error {
+	targets := make(map[*models.RunningOutput][]*models.RunningOutput, len(outputs))
+	for _, output := range outputs {
+		if output.Config.Retry != nil {
+			target, err := checkForward(outputs, output, "dead_letter", output.Config.Retry.DeadLetter)
+			if err != nil {
+				return err
+			}
+			if target != nil {
+				targets[output] = append(targets[output], target)
+			}
+		}
+		target, err := checkForward(outputs, output, "on_reject", output.Config.OnReject)
+		if err != nil {
+			return err
+		}
+		if target != nil {
+			targets[output] = append(targets[output], target)
+		}
+	}
+
+	// Follow the forwarding destinations depth-first and stop at outputs
+	// already on the current path
+	done := make(map[*models.RunningOutput]bool, len(outputs))
+	var path []*models.RunningOutput
+	var visit func(output *models.RunningOutput) error
+	visit = func(output *models.RunningOutput) error {
+		if idx := slices.Index(path, output); idx >= 0 {
+			names := make([]string, 0, len(path)-idx+1)
+			for _, o := range path[idx:] {
+				names = append(names, o.LogName())
+			}
+			names = append(names, output.LogName())
+			return fmt.Errorf("outputs forward metrics in a cycle: %s", strings.Join(names, " -> "))
+		}
+		if done[output] {
+			return nil
+		}
+
+		path = append(path, output)
+		for _, target := range targets[output] {
+			if err := visit(target); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		done[output] = true
+
+		return nil
+	}
+	for _, output := range outputs {
+		if err := visit(output); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// forwarder returns the function passing metrics of the given output to the
+// destination. Outputs are looked up on each call to follow configuration
+// reloads.
+func forwarder(unit *outputUnit, output *models.RunningOutput, destination string) func([]telegraf.Metric) {
+	if key, ok := strings.CutPrefix(destination, "outputs."); ok {
+		return func(metrics []telegraf.Metric) {
+			unit.RLock()
+			defer unit.RUnlock()
+
+			target := findOutput(unit.outputs, key)
+			if target == nil || target == output {
+				log.Printf("E! [agent] Forwarding output %q of %s not found, dropping %d metrics", key, output.LogName(), len(metrics))
+				return
+			}
+			for _, m := range metrics {
+				target.AddMetricNoCopy(m)
+			}
+		}
+	}
+
+	return func(metrics []telegraf.Metric) {
+		if err := writeForwardFile(destination, metrics); err != nil {
+			log.Printf("E! [agent] Forwarding %d metrics of %s to %q failed: %v", len(metrics), output.LogName(), destination, err)
+		}
+	}
+}
+
+// writeForwardFile appends the given metrics to the file in InfluxDB line
+// protocol.
+func writeForwardFile(filename string, metrics []telegraf.Metric) error {
+	s := &influx.Serializer{SortFields: true, UintSupport: true}
+	if err := s.Init(); err != nil {
+		return err
+	}
+	octets, err := s.SerializeBatch(metrics)
+	if err != nil {
+		return err
+	}
+
+	forwardFileMu.Lock()
+	defer forwardFileMu.Unlock()
+
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(octets); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,73 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/models"
+)
+
+func TestCheckForwards(t *testing.T) {
+	tests := []struct {
+		name     string
+		outputs  []*models.OutputConfig
+		expected string
+	}{
+		{
+			name: "chain",
+			outputs: []*models.OutputConfig{
+				{Name: "file", Alias: "a", OnReject: "outputs.b"},
+				{Name: "file", Alias: "b", Retry: &models.RetryConfig{DeadLetter: "outputs.c"}},
+				{Name: "file", Alias: "c", OnReject: "/var/lib/telegraf/rejected.influx"},
+			},
+		},
+		{
+			name: "shared destination",
+			outputs: []*models.OutputConfig{
+				{Name: "file", Alias: "a", OnReject: "outputs.c"},
+				{Name: "file", Alias: "b", OnReject: "outputs.c"},
+				{Name: "file", Alias: "c"},
+			},
+		},
+		{
+			name: "missing output",
+			outputs: []*models.OutputConfig{
+				{Name: "file", Alias: "a", OnReject: "outputs.b"},
+			},
+			expected: `on_reject output "b" of outputs.file::a not found`,
+		},
+		{
+			name: "self",
+			outputs: []*models.OutputConfig{
+				{Name: "file", Alias: "a", OnReject: "outputs.a"},
+			},
+			expected: "on_reject output of outputs.file::a must not be the output itself",
+		},
+		{
+			name: "cycle",
+			outputs: []*models.OutputConfig{
+				{Name: "file", Alias: "a", OnReject: "outputs.b"},
+				{Name: "file", Alias: "b", Retry: &models.RetryConfig{DeadLetter: "outputs.c"}},
+				{Name: "file", Alias: "c", OnReject: "outputs.b"},
+			},
+			expected: "outputs forward metrics in a cycle: outputs.file::b -> outputs.file::c -> outputs.file::b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputs := make([]*models.RunningOutput, 0, len(tt.outputs))
+			for _, cfg := range tt.outputs {
+				outputs = append(outputs, &models.RunningOutput{Config: cfg})
+			}
+
+			err := checkForwards(outputs)
+			if tt.expected == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,384 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+// ErrRestartRequired is returned by Reload if the configuration changes cannot
+// be applied to the running agent and the agent must be restarted instead.
+var ErrRestartRequired = errors.New("restart required")
+
+// Reload applies the plugin changes of the given configuration to the running
+// agent. Plugins are matched using their IDs, so only inputs and outputs that
+// were added, removed or modified are stopped and started. Untouched outputs
+// keep their buffer and connection. The processor chain is replaced as a
+// whole if any of the processors changed.
+//
+// An error wrapping ErrRestartRequired is returned if the configuration
+// contains changes that cannot be applied to the running agent, e.g. modified
+// agent settings, global tags, secret-stores or aggregators. In this case no
+// changes are applied.
+func (a *Agent) Reload(cfg *config.Config) error {
+	a.runningMu.Lock()
+	defer a.runningMu.Unlock()
+
+	running := a.running
+	if running == nil || running.ctx.Err() != nil {
+		return fmt.Errorf("%w: agent is not running", ErrRestartRequired)
+	}
+
+	if cfg.Agent.SkipProcessorsAfterAggregators == nil {
+		cfg.Agent.SkipProcessorsAfterAggregators = a.Config.Agent.SkipProcessorsAfterAggregators
+	}
+	if reason := a.restartReason(cfg); reason != "" {
+		return fmt.Errorf("%w: %s", ErrRestartRequired, reason)
+	}
+
+	// Determine the plugins to add and remove
+	inputs, addedInputs, removedInputs := diffPlugins(running.inputs.inputs, cfg.Inputs)
+	outputs, addedOutputs, removedOutputs := diffPlugins(running.outputs.outputs, cfg.Outputs)
+	replaceProcessors := !slices.Equal(pluginIDs(a.Config.Processors), pluginIDs(cfg.Processors))
+
+	// Initialize the new plugins before touching the running ones so errors
+	// leave the agent in its current state
+	for _, input := range addedInputs {
+		if err := a.initInput(input); err != nil {
+			return err
+		}
+	}
+	if replaceProcessors {
+		for _, processor := range cfg.Processors {
+			if err := processor.Init(); err != nil {
+				return fmt.Errorf("could not initialize processor %s: %w", processor.LogName(), err)
+			}
+		}
+	}
+	for _, output := range addedOutputs {
+		if err := output.Init(); err != nil {
+			return fmt.Errorf("could not initialize output %s: %w", output.LogName(), err)
+		}
+	}
+	if err := checkForwards(cfg.Outputs); err != nil {
+		return err
+	}
+
+	// The configuration might only use memory buffers to not open the buffer
+	// files of the running outputs a second time, so open the buffers of the
+	// added outputs now
+	for i, output := range addedOutputs {
+		if err := output.OpenBuffer(); err != nil {
+			discardOutputs(addedOutputs[:i])
+			return fmt.Errorf("could not open buffer of output %s: %w", output.LogName(), err)
+		}
+	}
+
+	log.Printf("I! [agent] Reloading plugins: %d inputs added, %d inputs removed, %d outputs added, %d outputs removed, processors replaced: %t",
+		len(addedInputs), len(removedInputs), len(addedOutputs), len(removedOutputs), replaceProcessors)
+
+	// Replace the processors first as this is the only step that might fail
+	if replaceProcessors {
+		log.Printf("D! [agent] Replacing processors")
+		if err := a.replaceProcessors(running.processor, cfg.Processors); err != nil {
+			discardOutputs(addedOutputs)
+			return fmt.Errorf("replacing processors failed: %w", err)
+		}
+		for _, processor := range a.Config.Processors {
+			a.unregisterState(processor.ID())
+		}
+		for _, processor := range cfg.Processors {
+			a.registerState(processor.ID(), processor.LogName(), unwrapProcessor(processor))
+		}
+		a.Config.Processors = cfg.Processors
+	}
+
+	// Stop the removed inputs before touching the outputs to not produce
+	// metrics for removed outputs anymore
+	for _, input := range removedInputs {
+		log.Printf("D! [agent] Stopping input %s", input.LogName())
+		stopGatherLoop(running.inputs, input)
+		input.Stop()
+		stopInputProcessors(running.inputs, input)
+		a.unregisterState(input.ID())
+		for _, processor := range input.Processors {
+			a.unregisterState(processor.ID())
+		}
+	}
+	running.inputs.inputs = slices.DeleteFunc(running.inputs.inputs, func(input *models.RunningInput) bool {
+		return slices.Contains(removedInputs, input)
+	})
+
+	// Add the new outputs before removing the old ones to allow for a
+	// seamless transition when replacing an output.
+	for _, output := range addedOutputs {
+		if !a.addOutput(running, output) {
+			outputs = slices.DeleteFunc(outputs, func(o *models.RunningOutput) bool { return o == output })
+		}
+	}
+	for _, output := range removedOutputs {
+		log.Printf("D! [agent] Stopping output %s", output.LogName())
+		a.removeOutput(running, output)
+		a.unregisterState(output.ID())
+	}
+
+	for _, input := range addedInputs {
+		if !a.addInput(running, input) {
+			inputs = slices.DeleteFunc(inputs, func(i *models.RunningInput) bool { return i == input })
+		}
+	}
+
+	// Release the resources of the unused, identical plugins of the new
+	// configuration
+	for _, output := range cfg.Outputs {
+		if !slices.Contains(outputs, output) {
+			output.Discard()
+		}
+	}
+
+	a.Config.Inputs = inputs
+	a.Config.Outputs = outputs
+
+	// Notify only the running plugins about changed secrets
+	plugins := a.runningPlugins()
+	a.Config.UpdateSecretChangeHandlers(cfg, func(plugin any) bool { return plugins[plugin] })
+
+	return nil
+}
+
+// runningPlugins returns the underlying plugins of the configured inputs,
+// processors, aggregators and outputs.
+func (a *Agent) runningPlugins() map[any]bool {
+	plugins := make(map[any]bool)
+	for _, input := range a.Config.Inputs {
+		plugins[input.Input] = true
+		for _, processor := range input.Processors {
+			plugins[unwrapProcessor(processor)] = true
+		}
+	}
+	for _, processor := range a.Config.Processors {
+		plugins[unwrapProcessor(processor)] = true
+	}
+	for _, processor := range a.Config.AggProcessors {
+		plugins[unwrapProcessor(processor)] = true
+	}
+	for _, aggregator := range a.Config.Aggregators {
+		plugins[aggregator.Aggregator] = true
+	}
+	for _, output := range a.Config.Outputs {
+		plugins[output.Output] = true
+	}
+	return plugins
+}
+
+// restartReason returns a non-empty reason if the given configuration
+// contains changes that cannot be applied to the running agent.
+func (a *Agent) restartReason(cfg *config.Config) string {
+	if !reflect.DeepEqual(a.Config.Agent, cfg.Agent) {
+		return "agent settings changed"
+	}
+	if !reflect.DeepEqual(a.Config.Tags, cfg.Tags) {
+		return "global tags changed"
+	}
+	if !reflect.DeepEqual(a.Config.SecretStoreIDs(), cfg.SecretStoreIDs()) {
+		return "secret-stores changed"
+	}
+	if !reflect.DeepEqual(a.Config.Routes, cfg.Routes) {
+		return "routes changed"
+	}
+	if !slices.Equal(pluginIDs(a.Config.Aggregators), pluginIDs(cfg.Aggregators)) {
+		return "aggregators changed"
+	}
+	if len(cfg.Aggregators) > 0 && !*cfg.Agent.SkipProcessorsAfterAggregators {
+		if !slices.Equal(pluginIDs(a.Config.AggProcessors), pluginIDs(cfg.AggProcessors)) {
+			return "processors running after aggregators changed"
+		}
+	}
+	if a.Config.Persister != nil && cfg.Persister != nil && a.Config.Persister.Filename != cfg.Persister.Filename {
+		return "state file changed"
+	}
+	return ""
+}
+
+// addInput starts the given input and its gather loop. False is returned if
+// the input could not be started.
+func (a *Agent) addInput(running *runningUnits, input *models.RunningInput) bool {
+	log.Printf("D! [agent] Starting input %s", input.LogName())
+
+	// Service input plugins are not subject to timestamp rounding unless
+	// precision is set on the input plugin, see startInputs.
+	dst, err := a.startInputProcessors(running.inputs, input)
+	if err != nil {
+		log.Printf("E! [agent] Starting processors of input %s failed, skipping plugin: %v", input.LogName(), err)
+		return false
+	}
+
+	acc := NewAccumulator(input, dst)
+	acc.SetPrecision(getPrecision(input.Config.Precision, 0))
+
+	if err := input.Start(acc); err != nil {
+		stopInputProcessors(running.inputs, input)
+		var fatalErr *internal.FatalError
+		if errors.As(err, &fatalErr) {
+			log.Printf("I! [agent] Failed to start %s, shutting down plugin: %s", input.LogName(), err)
+		} else {
+			log.Printf("E! [agent] Starting input %s failed, skipping plugin: %v", input.LogName(), err)
+		}
+		return false
+	}
+	if err := input.Probe(); err != nil {
+		log.Printf("I! [agent] Failed to probe %s, shutting down plugin: %s", input.LogName(), err)
+		input.Stop()
+		stopInputProcessors(running.inputs, input)
+		return false
+	}
+
+	a.registerState(input.ID(), input.LogName(), input.Input)
+	for _, processor := range input.Processors {
+		a.registerState(processor.ID(), processor.LogName(), unwrapProcessor(processor))
+	}
+
+	running.inputs.inputs = append(running.inputs.inputs, input)
+
+	// The gather loops are started by runInputs if it did not run yet
+	if running.inputs.started {
+		a.startGatherLoop(running.ctx, running.inputs, input, time.Now())
+	}
+	return true
+}
+
+// addOutput connects the given output and adds it to the running outputs.
+// False is returned if the output could not be connected.
+func (a *Agent) addOutput(running *runningUnits, output *models.RunningOutput) bool {
+	if err := a.connectOutput(running.ctx, output); err != nil {
+		var fatalErr *internal.FatalError
+		if errors.As(err, &fatalErr) {
+			log.Printf("I! [agent] Failed to connect to [%s], error was %q;  shutting down plugin...", output.LogName(), err)
+		} else {
+			log.Printf("E! [agent] Connecting output %s failed, skipping plugin: %v", output.LogName(), err)
+		}
+		output.Close()
+		return false
+	}
+
+	a.registerState(output.ID(), output.LogName(), output.Output)
+
+	unit := running.outputs
+	unit.Lock()
+	defer unit.Unlock()
+
+	unit.outputs = append(unit.outputs, output)
+
+	// The flush loops are started by runOutputs if it did not run yet
+	if unit.ctx != nil {
+		a.startFlushLoop(unit, output)
+	}
+	return true
+}
+
+// removeOutput removes the given output from the running outputs, writes the
+// remaining metrics one last time and closes the output.
+func (*Agent) removeOutput(running *runningUnits, output *models.RunningOutput) {
+	unit := running.outputs
+	unit.Lock()
+	unit.outputs = slices.DeleteFunc(unit.outputs, func(o *models.RunningOutput) bool { return o == output })
+	flusher := unit.flushers[output]
+	delete(unit.flushers, output)
+	unit.Unlock()
+
+	if flusher != nil {
+		flusher.cancel()
+		<-flusher.done
+	}
+	output.Close()
+}
+
+// discardOutputs releases the buffers of outputs that will not be added.
+func discardOutputs(outputs []*models.RunningOutput) {
+	for _, output := range outputs {
+		output.Discard()
+	}
+}
+
+// registerState registers a stateful plugin with the persister, if any.
+func (a *Agent) registerState(id, name string, plugin any) {
+	if a.Config.Persister == nil {
+		return
+	}
+	stateful, ok := plugin.(telegraf.StatefulPlugin)
+	if !ok {
+		return
+	}
+	if err := a.Config.Persister.Register(id, stateful); err != nil {
+		log.Printf("E! [agent] Could not register %s for persisting its state: %v", name, err)
+	}
+}
+
+// unregisterState removes the plugin with the given ID from the persister,
+// if any.
+func (a *Agent) unregisterState(id string) {
+	if a.Config.Persister == nil {
+		return
+	}
+	a.Config.Persister.Unregister(id)
+}
+
+// unwrapProcessor returns the underlying plugin of a processor.
+func unwrapProcessor(processor *models.RunningProcessor) any {
+	if p, ok := processor.Processor.(processors.HasUnwrap); ok {
+		return p.Unwrap()
+	}
+	return processor.Processor
+}
+
+// pluginIDs returns the IDs of the given plugins in order.
+func pluginIDs[T interface{ ID() string }](plugins []T) []string {
+	ids := make([]string, 0, len(plugins))
+	for _, p := range plugins {
+		ids = append(ids, p.ID())
+	}
+	return ids
+}
+
+// diffPlugins matches the running plugins with the configured ones using the
+// plugin IDs. It returns the resulting list of plugins in configuration order,
+// where running plugins are kept, as well as the plugins to add and to remove.
+func diffPlugins[T interface {
+	comparable
+	ID() string
+}](current, configured []T) (result, added, removed []T) {
+	available := make(map[string][]T, len(current))
+	for _, p := range current {
+		id := p.ID()
+		available[id] = append(available[id], p)
+	}
+
+	result = make([]T, 0, len(configured))
+	for _, p := range configured {
+		id := p.ID()
+		if candidates := available[id]; len(candidates) > 0 {
+			result = append(result, candidates[0])
+			available[id] = candidates[1:]
+			continue
+		}
+		result = append(result, p)
+		added = append(added, p)
+	}
+
+	for _, p := range current {
+		if slices.Contains(available[p.ID()], p) {
+			removed = append(removed, p)
+		}
+	}
+
+	return result, added, removed
+}
@@ -0,0 +1,203 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+type idPlugin struct {
+	id string
+}
+
+func (p *idPlugin) ID() string {
+	return p.id
+}
+
+func TestDiffPlugins(t *testing.T) {
+	kept := &idPlugin{id: "a"}
+	duplicate := &idPlugin{id: "a"}
+	removed := &idPlugin{id: "b"}
+	current := []*idPlugin{kept, duplicate, removed}
+
+	added := &idPlugin{id: "c"}
+	superseded := &idPlugin{id: "a"}
+	configured := []*idPlugin{added, superseded}
+
+	result, toAdd, toRemove := diffPlugins(current, configured)
+	require.Equal(t, []*idPlugin{added, kept}, result)
+	require.Same(t, kept, result[1])
+	require.Equal(t, []*idPlugin{added}, toAdd)
+	require.Equal(t, []*idPlugin{duplicate, removed}, toRemove)
+}
+
+func TestReload(t *testing.T) {
+	initial := `
+[agent]
+  interval = "1s"
+  flush_interval = "1s"
+  skip_processors_after_aggregators = true
+
+[[inputs.mock]]
+  alias = "unchanged"
+  metric_name = "unchanged"
+  [[inputs.mock.constant]]
+    name = "value"
+    value = 1
+
+[[inputs.mock]]
+  alias = "removed"
+  metric_name = "removed"
+  [[inputs.mock.constant]]
+    name = "value"
+    value = 2
+
+[[outputs.discard]]
+  alias = "unchanged"
+
+[[outputs.discard]]
+  alias = "removed"
+`
+	modified := `
+[agent]
+  interval = "1s"
+  flush_interval = "1s"
+  skip_processors_after_aggregators = true
+
+[[inputs.mock]]
+  alias = "unchanged"
+  metric_name = "unchanged"
+  [[inputs.mock.constant]]
+    name = "value"
+    value = 1
+
+[[inputs.mock]]
+  alias = "added"
+  metric_name = "added"
+  [[inputs.mock.constant]]
+    name = "value"
+    value = 3
+
+[[processors.rename]]
+  alias = "added"
+
+[[outputs.discard]]
+  alias = "unchanged"
+
+[[outputs.discard]]
+  alias = "added"
+`
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadConfigData([]byte(initial), config.EmptySourcePath))
+	a := NewAgent(cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		a.runningMu.Lock()
+		defer a.runningMu.Unlock()
+		return a.running != nil && a.running.inputs.started
+	}, 5*time.Second, 10*time.Millisecond)
+
+	unchangedInput := cfg.Inputs[0]
+	unchangedOutput := cfg.Outputs[0]
+
+	newCfg := config.NewConfig()
+	require.NoError(t, newCfg.LoadConfigData([]byte(modified), config.EmptySourcePath))
+	require.NoError(t, a.Reload(newCfg))
+
+	require.Len(t, a.Config.Inputs, 2)
+	require.Same(t, unchangedInput, a.Config.Inputs[0])
+	require.Equal(t, "added", a.Config.Inputs[1].Config.Alias)
+
+	require.Len(t, a.Config.Outputs, 2)
+	require.Same(t, unchangedOutput, a.Config.Outputs[0])
+	require.Equal(t, "added", a.Config.Outputs[1].Config.Alias)
+
+	require.Len(t, a.Config.Processors, 1)
+	require.Equal(t, "added", a.Config.Processors[0].Config.Alias)
+
+	a.runningMu.Lock()
+	running := a.running
+	a.runningMu.Unlock()
+	require.ElementsMatch(t, a.Config.Inputs, running.inputs.inputs)
+	require.Len(t, running.inputs.gatherers, len(a.Config.Inputs))
+	for _, input := range a.Config.Inputs {
+		require.Contains(t, running.inputs.gatherers, input)
+	}
+	require.ElementsMatch(t, a.Config.Outputs, running.outputs.outputs)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestReloadRestartRequired(t *testing.T) {
+	initial := `
+[agent]
+  interval = "1s"
+  flush_interval = "1s"
+
+[[inputs.mock]]
+  metric_name = "mock"
+  [[inputs.mock.constant]]
+    name = "value"
+    value = 1
+
+[[outputs.discard]]
+`
+	modified := `
+[agent]
+  interval = "5s"
+  flush_interval = "1s"
+
+[[inputs.mock]]
+  metric_name = "mock"
+  [[inputs.mock.constant]]
+    name = "value"
+    value = 1
+
+[[outputs.discard]]
+`
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadConfigData([]byte(initial), config.EmptySourcePath))
+	a := NewAgent(cfg)
+
+	// Reloading is not possible if the agent is not running
+	newCfg := config.NewConfig()
+	require.NoError(t, newCfg.LoadConfigData([]byte(initial), config.EmptySourcePath))
+	require.ErrorIs(t, a.Reload(newCfg), ErrRestartRequired)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		a.runningMu.Lock()
+		defer a.runningMu.Unlock()
+		return a.running != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	newCfg = config.NewConfig()
+	require.NoError(t, newCfg.LoadConfigData([]byte(modified), config.EmptySourcePath))
+	require.ErrorIs(t, a.Reload(newCfg), ErrRestartRequired)
+
+	// Nothing must be changed
+	require.Equal(t, config.Duration(time.Second), a.Config.Agent.Interval)
+
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// gatherScheduler limits the number of concurrent Gather calls of all inputs
+// and of the inputs of each plugin type.
+type gatherScheduler struct {
+	global    chan struct{}
+	perPlugin int
+
+	plugins map[string]chan struct{}
+	sync.Mutex
+}
+
+// newGatherScheduler creates a scheduler with the given limits, zero means
+// unlimited.
+func newGatherScheduler(global, perPlugin int) *gatherScheduler {
+	s := &gatherScheduler{
+		perPlugin: perPlugin,
+		plugins:   make(map[string]chan struct{}),
+	}
+	if global > 0 {
+		s.global = make(chan struct{}, global)
+	}
+	return s
+}
+
+// acquire blocks until a Gather call of an input of the given plugin type is
+// allowed or the context is done. The returned function must be called after
+// the Gather call finished.
+func (s *gatherScheduler) acquire(ctx context.Context, plugin string) (func(), error) {
+	// Wait for the plugin limit first to not block other plugins while waiting
+	sem := s.pluginSemaphore(plugin)
+	if sem != nil {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if s.global != nil {
+		select {
+		case s.global <- struct{}{}:
+		case <-ctx.Done():
+			if sem != nil {
+				<-sem
+			}
+			return nil, ctx.Err()
+		}
+	}
+
+	return func() {
+		if s.global != nil {
+			<-s.global
+		}
+		if sem != nil {
+			<-sem
+		}
+	}, nil
+}
+
+func (s *gatherScheduler) pluginSemaphore(plugin string) chan struct{} {
+	if s.perPlugin <= 0 {
+		return nil
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	sem, found := s.plugins[plugin]
+	if !found {
+		sem = make(chan struct{}, s.perPlugin)
+		s.plugins[plugin] = sem
+	}
+	return sem
+}
+
+// spreadOffset returns an offset within the interval derived from the given
+// plugin ID to distribute the collection of inputs sharing the same interval.
+// The offset is stable across restarts of the agent.
+func spreadOffset(id string, interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(id))
+	return time.Duration(h.Sum64() % uint64(interval))
+}
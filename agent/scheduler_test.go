@@ -0,0 +1,164 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+)
+
+func TestGatherSchedulerLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		global    int
+		perPlugin int
+		plugins   []string
+		expected  int64
+	}{
+		{
+			name:     "global",
+			global:   2,
+			plugins:  []string{"http", "http", "snmp", "snmp"},
+			expected: 2,
+		},
+		{
+			name:      "per plugin",
+			perPlugin: 1,
+			plugins:   []string{"http", "http", "http", "snmp", "snmp"},
+			expected:  2,
+		},
+		{
+			name:      "global and per plugin",
+			global:    3,
+			perPlugin: 2,
+			plugins:   []string{"http", "http", "http", "snmp", "snmp", "snmp"},
+			expected:  3,
+		},
+		{
+			name:     "unlimited",
+			plugins:  []string{"http", "http", "http"},
+			expected: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newGatherScheduler(tt.global, tt.perPlugin)
+
+			var running, peak atomic.Int64
+			perPlugin := make(map[string]*atomic.Int64)
+			for _, p := range tt.plugins {
+				perPlugin[p] = &atomic.Int64{}
+			}
+
+			var wg sync.WaitGroup
+			for _, plugin := range tt.plugins {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					release, err := s.acquire(t.Context(), plugin)
+					if !assert.NoError(t, err) {
+						return
+					}
+					defer release()
+
+					n := running.Add(1)
+					for {
+						p := peak.Load()
+						if n <= p || peak.CompareAndSwap(p, n) {
+							break
+						}
+					}
+					if tt.perPlugin > 0 && perPlugin[plugin].Add(1) > int64(tt.perPlugin) {
+						t.Errorf("more than %d concurrent gathers of %q", tt.perPlugin, plugin)
+					}
+					time.Sleep(50 * time.Millisecond)
+					perPlugin[plugin].Add(-1)
+					running.Add(-1)
+				}()
+			}
+			wg.Wait()
+
+			require.Equal(t, tt.expected, peak.Load())
+		})
+	}
+}
+
+func TestGatherSchedulerCancel(t *testing.T) {
+	s := newGatherScheduler(1, 0)
+
+	release, err := s.acquire(t.Context(), "http")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	_, err = s.acquire(ctx, "http")
+	require.ErrorIs(t, err, context.Canceled)
+
+	release()
+	release, err = s.acquire(t.Context(), "http")
+	require.NoError(t, err)
+	release()
+}
+
+type hangingInput struct {
+	release chan struct{}
+}
+
+func (*hangingInput) SampleConfig() string {
+	return ""
+}
+
+func (i *hangingInput) Gather(telegraf.Accumulator) error {
+	<-i.release
+	return nil
+}
+
+func TestScheduledGatherReleasesHangingGather(t *testing.T) {
+	a := &Agent{scheduler: newGatherScheduler(1, 1)}
+
+	plugin := &hangingInput{release: make(chan struct{})}
+	input := models.NewRunningInput(plugin, &models.InputConfig{Name: "hanging"})
+	acc := NewAccumulator(input, make(chan telegraf.Metric, 10))
+	ticker := NewUnalignedTicker(time.Hour, 0, 0)
+	defer ticker.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, a.scheduledGather(context.Background(), acc, input, ticker, 10*time.Millisecond))
+	}()
+
+	// Other inputs must be able to gather after the interval of the hanging
+	// gather even though it did not complete
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	release, err := a.scheduler.acquire(ctx, "hanging")
+	require.NoError(t, err)
+	release()
+
+	close(plugin.release)
+	<-done
+}
+
+func TestSpreadOffset(t *testing.T) {
+	interval := 10 * time.Second
+
+	offsets := make(map[time.Duration]bool)
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		offset := spreadOffset(id, interval)
+		require.GreaterOrEqual(t, offset, time.Duration(0))
+		require.Less(t, offset, interval)
+		require.Equal(t, offset, spreadOffset(id, interval))
+		offsets[offset] = true
+	}
+	require.Greater(t, len(offsets), 1)
+	require.Zero(t, spreadOffset("a", 0))
+}
@@ -0,0 +1,128 @@
+//go:build !custom || agent.selfstat_server
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	internal_prometheus "github.com/influxdata/telegraf/internal/prometheus"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// selfstatServer serves the internal statistics of the agent in the
+// Prometheus and OpenMetrics exposition formats.
+type selfstatServer struct {
+	listener net.Listener
+	server   *http.Server
+}
+
+// newSelfstatServer creates the internal statistics server listening on the
+// given address. The server does not handle requests before calling start.
+func newSelfstatServer(address string) (*selfstatServer, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("listening on selfstat address %q failed: %w", address, err)
+	}
+
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(&selfstatCollector{}); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("registering selfstat collector failed: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+		ErrorLog:          &selfstatErrorLogger{},
+		ErrorHandling:     promhttp.ContinueOnError,
+		EnableOpenMetrics: true,
+	}))
+
+	s := &selfstatServer{
+		listener: listener,
+		server: &http.Server{
+			Handler:      mux,
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 10 * time.Second,
+		},
+	}
+
+	return s, nil
+}
+
+// start serves the internal statistics in the background.
+func (s *selfstatServer) start() {
+	log.Printf("I! [agent] Serving internal statistics on %s", s.listener.Addr())
+	go func() {
+		if err := s.server.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("E! [agent] Serving internal statistics failed: %v", err)
+		}
+	}()
+}
+
+// stop shuts down the server waiting for active requests to complete.
+func (s *selfstatServer) stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := s.server.Shutdown(ctx); err != nil {
+		log.Printf("E! [agent] Stopping internal statistics server failed: %v", err)
+	}
+	// The listener is not closed by the server if it was never served
+	s.listener.Close()
+}
+
+// selfstatErrorLogger logs errors of the Prometheus handler.
+type selfstatErrorLogger struct{}
+
+func (*selfstatErrorLogger) Println(v ...interface{}) {
+	log.Printf("E! [agent] Serving internal statistics failed: %s", fmt.Sprint(v...))
+}
+
+// selfstatCollector is an unchecked Prometheus collector exporting all stats
+// of the selfstat registry. Regular stats are exported as untyped metrics as
+// the registry does not distinguish counters from gauges, timing stats are
+// exported as histograms.
+type selfstatCollector struct{}
+
+// Describe does not send any descriptions as the stats are only known when
+// collecting.
+func (*selfstatCollector) Describe(chan<- *prometheus.Desc) {}
+
+func (*selfstatCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, sample := range selfstat.Samples() {
+		name, ok := internal_prometheus.SanitizeMetricName(sample.Measurement + "_" + sample.Field)
+		if !ok {
+			continue
+		}
+
+		labels := make(prometheus.Labels, len(sample.Tags))
+		for k, v := range sample.Tags {
+			if label, ok := internal_prometheus.SanitizeLabelName(k); ok {
+				labels[label] = v
+			}
+		}
+
+		desc := prometheus.NewDesc(name, "Telegraf internal statistic "+sample.Measurement+" "+sample.Field, nil, labels)
+		var m prometheus.Metric
+		var err error
+		if sample.Histogram != nil {
+			m, err = prometheus.NewConstHistogram(desc, sample.Histogram.Count, float64(sample.Histogram.Sum), sample.Histogram.Buckets)
+		} else {
+			m, err = prometheus.NewConstMetric(desc, prometheus.UntypedValue, float64(sample.Value))
+		}
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(desc, err)
+			continue
+		}
+		ch <- m
+	}
+}
@@ -0,0 +1,17 @@
+//go:build custom && !agent.selfstat_server
+
+package agent
+
+import "errors"
+
+// selfstatServer is not available in customized builds without the
+// "agent.selfstat_server" build tag.
+type selfstatServer struct{}
+
+func newSelfstatServer(string) (*selfstatServer, error) {
+	return nil, errors.New("internal statistics server not available, build with the 'agent.selfstat_server' tag")
+}
+
+func (*selfstatServer) start() {}
+
+func (*selfstatServer) stop() {}
@@ -0,0 +1,57 @@
+//go:build !custom || agent.selfstat_server
+
+package agent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+func TestSelfstatServer(t *testing.T) {
+	tags := map[string]string{"input": "selfstat_server_test"}
+	selfstat.Register("selfstat_server_test", "metrics_gathered", tags).Incr(4)
+	selfstat.RegisterTiming("selfstat_server_test", "gather_time_ns", tags).Incr(30_000_000)
+
+	s, err := newSelfstatServer("127.0.0.1:0")
+	require.NoError(t, err)
+	defer s.stop()
+
+	request := func(accept string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if accept != "" {
+			r.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		s.server.Handler.ServeHTTP(w, r)
+		return w
+	}
+
+	// Prometheus text format
+	w := request("")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+	body := w.Body.String()
+	require.Contains(t, body, "# TYPE internal_selfstat_server_test_metrics_gathered untyped\n")
+	require.Contains(t, body, `internal_selfstat_server_test_metrics_gathered{input="selfstat_server_test"} 4`+"\n")
+	require.Contains(t, body, "# TYPE internal_selfstat_server_test_gather_time_ns histogram\n")
+	require.Contains(t, body, `internal_selfstat_server_test_gather_time_ns_bucket{input="selfstat_server_test",le="2.5e+07"} 0`+"\n")
+	require.Contains(t, body, `internal_selfstat_server_test_gather_time_ns_bucket{input="selfstat_server_test",le="5e+07"} 1`+"\n")
+	require.Contains(t, body, `internal_selfstat_server_test_gather_time_ns_count{input="selfstat_server_test"} 1`+"\n")
+
+	// OpenMetrics format
+	w = request("application/openmetrics-text; version=1.0.0")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Header().Get("Content-Type"), "application/openmetrics-text")
+	body = w.Body.String()
+	require.Contains(t, body, "# TYPE internal_selfstat_server_test_metrics_gathered unknown\n")
+	require.Contains(t, body, "# TYPE internal_selfstat_server_test_gather_time_ns histogram\n")
+	require.Contains(t, body, "# EOF\n")
+
+	// Collecting must not reset the timing histogram
+	require.Contains(t, request("").Body.String(), `internal_selfstat_server_test_gather_time_ns_count{input="selfstat_server_test"} 1`+"\n")
+}
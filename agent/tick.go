@@ -279,3 +279,105 @@ func (t *RollingTicker) Stop() {
 	t.cancel()
 	t.wg.Wait()
 }
+
+// ScheduleTicker delivers ticks at the times of a cron schedule plus an
+// optional offset and jitter.
+//
+// The sleep time is limited, so changes of the system clock are noticed and
+// the ticks follow the wall-clock time of the schedule. Missed ticks, e.g. due
+// to a suspended system, are skipped.
+//
+// Ticks are dropped for slow consumers.
+type ScheduleTicker struct {
+	schedule *internal.Schedule
+	jitter   time.Duration
+	offset   time.Duration
+	maxSleep time.Duration
+	last     time.Time
+	ch       chan time.Time
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func NewScheduleTicker(schedule *internal.Schedule, jitter, offset time.Duration) *ScheduleTicker {
+	t := &ScheduleTicker{
+		schedule: schedule,
+		jitter:   jitter,
+		offset:   offset,
+		maxSleep: time.Minute,
+	}
+	t.start(clock.New())
+	return t
+}
+
+func (t *ScheduleTicker) start(clk clock.Clock) {
+	t.ch = make(chan time.Time, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+
+	target := t.next(clk.Now())
+	timer := clk.Timer(t.sleep(clk.Now(), target))
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		t.run(ctx, timer, target)
+	}()
+}
+
+// next returns the time of the next tick following the previously scheduled
+// one or the given time, whichever is later.
+func (t *ScheduleTicker) next(now time.Time) time.Time {
+	since := now.Add(-t.offset)
+	if since.Before(t.last) {
+		since = t.last
+	}
+
+	t.last = t.schedule.Next(since)
+	if t.last.IsZero() {
+		return t.last
+	}
+	return t.last.Add(t.offset + internal.RandomDuration(t.jitter))
+}
+
+// sleep returns the time to wait for the target limited to the maximum sleep
+// time.
+func (t *ScheduleTicker) sleep(now, target time.Time) time.Duration {
+	if target.IsZero() {
+		return t.maxSleep
+	}
+	return min(target.Sub(now), t.maxSleep)
+}
+
+func (t *ScheduleTicker) run(ctx context.Context, timer *clock.Timer, target time.Time) {
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case now := <-timer.C:
+			if target.IsZero() || now.Before(target) {
+				timer.Reset(t.sleep(now, target))
+				continue
+			}
+
+			select {
+			case t.ch <- now:
+			default:
+			}
+
+			target = t.next(now)
+			timer.Reset(t.sleep(now, target))
+		}
+	}
+}
+
+func (t *ScheduleTicker) Elapsed() <-chan time.Time {
+	return t.ch
+}
+
+func (t *ScheduleTicker) Stop() {
+	t.cancel()
+	t.wg.Wait()
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/benbjohnson/clock"
 	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/internal"
 )
 
 func TestAlignedTicker(t *testing.T) {
@@ -226,6 +228,94 @@ func TestRollingTicker(t *testing.T) {
 	require.Equal(t, expected, actual)
 }
 
+func TestScheduleTicker(t *testing.T) {
+	schedule, err := internal.ParseSchedule("*/10 * * * * *", "UTC")
+	require.NoError(t, err)
+
+	clk := clock.NewMock()
+	since := clk.Now()
+	until := since.Add(60 * time.Second)
+
+	ticker := &ScheduleTicker{
+		schedule: schedule,
+		maxSleep: time.Minute,
+	}
+	ticker.start(clk)
+	defer ticker.Stop()
+
+	expected := []time.Time{
+		time.Unix(10, 0).UTC(),
+		time.Unix(20, 0).UTC(),
+		time.Unix(30, 0).UTC(),
+		time.Unix(40, 0).UTC(),
+		time.Unix(50, 0).UTC(),
+		time.Unix(60, 0).UTC(),
+	}
+
+	actual := make([]time.Time, 0)
+	clk.Add(10 * time.Second)
+	for !clk.Now().After(until) {
+		tm := <-ticker.Elapsed()
+		actual = append(actual, tm.UTC())
+
+		clk.Add(10 * time.Second)
+	}
+
+	require.Equal(t, expected, actual)
+}
+
+func TestScheduleTickerOffset(t *testing.T) {
+	schedule, err := internal.ParseSchedule("0 2 * * *", "UTC")
+	require.NoError(t, err)
+
+	clk := clock.NewMock()
+	ticker := &ScheduleTicker{
+		schedule: schedule,
+		offset:   5 * time.Second,
+		maxSleep: time.Hour,
+	}
+	ticker.start(clk)
+	defer ticker.Stop()
+
+	// Sleeping is limited, so the long wait is split into multiple timers
+	for range 2 {
+		clk.Add(time.Hour)
+	}
+	select {
+	case tm := <-ticker.Elapsed():
+		require.Failf(t, "unexpected tick", "tick at %v", tm)
+	default:
+	}
+
+	clk.Add(5 * time.Second)
+	tm := <-ticker.Elapsed()
+	require.Equal(t, time.Unix(2*3600+5, 0).UTC(), tm.UTC())
+
+	clk.Add(24 * time.Hour)
+	tm = <-ticker.Elapsed()
+	require.Equal(t, time.Unix(26*3600+5, 0).UTC(), tm.UTC())
+}
+
+func TestScheduleTickerMissedTick(t *testing.T) {
+	schedule, err := internal.ParseSchedule("*/10 * * * * *", "UTC")
+	require.NoError(t, err)
+
+	clk := clock.NewMock()
+	ticker := &ScheduleTicker{
+		schedule: schedule,
+		maxSleep: time.Minute,
+	}
+	ticker.start(clk)
+	defer ticker.Stop()
+
+	clk.Add(25 * time.Second)
+	tm := <-ticker.Elapsed()
+	require.Equal(t, time.Unix(10, 0).UTC(), tm.UTC())
+	clk.Add(5 * time.Second)
+	tm = <-ticker.Elapsed()
+	require.Equal(t, time.Unix(30, 0).UTC(), tm.UTC())
+}
+
 // Simulates running the Ticker for an hour and displays stats about the
 // operation.
 func TestAlignedTickerDistribution(t *testing.T) {
@@ -0,0 +1,375 @@
+// Command handling for disk-buffer "buffer" command
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/logger"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+// loadBufferConfig determines the buffer directory and the configured outputs
+// from the given flags. The configuration is only loaded if required or if
+// no buffer directory is specified explicitly.
+func loadBufferConfig(cCtx *cli.Context, required bool) (string, []*models.RunningOutput, error) {
+	directory := cCtx.String("buffer-directory")
+
+	// Collect the given configuration files
+	configFiles := cCtx.StringSlice("config")
+	for _, fConfigDirectory := range cCtx.StringSlice("config-directory") {
+		files, err := config.WalkDirectory(fConfigDirectory)
+		if err != nil {
+			return "", nil, err
+		}
+		configFiles = append(configFiles, files...)
+	}
+	if directory != "" && len(configFiles) == 0 && !required {
+		return directory, nil, nil
+	}
+
+	// If no "config" or "config-directory" flag(s) was provided we should
+	// load default configuration files
+	if len(configFiles) == 0 {
+		paths, err := config.GetDefaultConfigPath()
+		if err != nil {
+			return "", nil, err
+		}
+		configFiles = paths
+	}
+
+	// Only load the outputs as we are not going to gather any data. The
+	// outputs must not open their buffer files as those are accessed directly
+	// and might belong to a running agent.
+	c := config.NewConfig()
+	c.Agent.Quiet = true
+	c.InputFilters = []string{"-"}
+	c.MemoryBuffers = true
+	if err := c.LoadAll(configFiles...); err != nil {
+		return "", nil, err
+	}
+
+	if directory == "" {
+		directory = c.Agent.BufferDirectory
+	}
+	if directory == "" {
+		return "", nil, errors.New("no buffer directory specified in the configuration or via '--buffer-directory'")
+	}
+	return directory, c.Outputs, nil
+}
+
+// bufferPath returns the path of the buffer files for the given output ID.
+func bufferPath(directory, id string) (string, error) {
+	path := filepath.Join(directory, id)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("no buffer found for ID %q: %w", id, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("no buffer found for ID %q", id)
+	}
+	return path, nil
+}
+
+// findOutput returns the output matching the given ID, alias or name.
+func findOutput(outputs []*models.RunningOutput, key string) (*models.RunningOutput, error) {
+	var found []*models.RunningOutput
+	for _, output := range outputs {
+		if output.ID() == key {
+			return output, nil
+		}
+		if output.Config.Alias == key || output.Config.Name == key {
+			found = append(found, output)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no output found for %q", key)
+	case 1:
+		return found[0], nil
+	}
+	return nil, fmt.Errorf("multiple outputs found for %q, please use the output ID", key)
+}
+
+func listBuffers(outputBuffer io.Writer, directory string, outputs []*models.RunningOutput) error {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return fmt.Errorf("reading buffer directory failed: %w", err)
+	}
+
+	names := make(map[string]string, len(outputs))
+	for _, output := range outputs {
+		names[output.ID()] = output.LogName()
+	}
+
+	w := tabwriter.NewWriter(outputBuffer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tOUTPUT\tMETRICS\tCORRUPT\tSIZE\tOLDEST\tNEWEST")
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		id := entry.Name()
+		path := filepath.Join(directory, id)
+
+		var count int
+		var oldest, newest time.Time
+		corrupt, err := models.ReadDiskBuffer(path, func(m telegraf.Metric) error {
+			count++
+			if ts := m.Time(); oldest.IsZero() || ts.Before(oldest) {
+				oldest = ts
+			}
+			if ts := m.Time(); ts.After(newest) {
+				newest = ts
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("reading buffer %q failed: %w", id, err)
+		}
+		size, err := models.DiskBufferSize(path)
+		if err != nil {
+			return fmt.Errorf("determining size of buffer %q failed: %w", id, err)
+		}
+
+		name := names[id]
+		if name == "" {
+			name = "-"
+		}
+		first, last := "-", "-"
+		if count > 0 {
+			first = oldest.UTC().Format(time.RFC3339)
+			last = newest.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%s\t%s\n", id, name, count, corrupt, size, first, last)
+	}
+	return w.Flush()
+}
+
+func dumpBuffer(outputBuffer io.Writer, path, format string) error {
+	creator, ok := serializers.Serializers[format]
+	if !ok {
+		return fmt.Errorf("unknown data format %q", format)
+	}
+	serializer := creator()
+	if p, ok := serializer.(telegraf.Initializer); ok {
+		if err := p.Init(); err != nil {
+			return fmt.Errorf("initializing serializer failed: %w", err)
+		}
+	}
+
+	corrupt, err := models.ReadDiskBuffer(path, func(m telegraf.Metric) error {
+		octets, err := serializer.Serialize(m)
+		if err != nil {
+			return fmt.Errorf("serializing metric failed: %w", err)
+		}
+		_, err = outputBuffer.Write(octets)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if corrupt > 0 {
+		fmt.Fprintf(os.Stderr, "Skipped %d corrupt entries\n", corrupt)
+	}
+	return nil
+}
+
+func replayBuffer(outputBuffer io.Writer, path string, output *models.RunningOutput) error {
+	if err := output.Init(); err != nil {
+		return fmt.Errorf("initializing output %s failed: %w", output.LogName(), err)
+	}
+	if err := output.Output.Connect(); err != nil {
+		return fmt.Errorf("connecting output %s failed: %w", output.LogName(), err)
+	}
+	defer output.Output.Close()
+
+	// Write the metrics as stored in the buffer, i.e. filtering and
+	// modifications of the output were already applied before buffering
+	var written int
+	batch := make([]telegraf.Metric, 0, output.MetricBatchSize)
+	write := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := output.Output.Write(batch); err != nil {
+			return fmt.Errorf("writing to output %s failed after %d metrics: %w", output.LogName(), written, err)
+		}
+		written += len(batch)
+		batch = make([]telegraf.Metric, 0, output.MetricBatchSize)
+		return nil
+	}
+
+	corrupt, err := models.ReadDiskBuffer(path, func(m telegraf.Metric) error {
+		batch = append(batch, m)
+		if len(batch) < output.MetricBatchSize {
+			return nil
+		}
+		return write()
+	})
+	if err != nil {
+		return err
+	}
+	if err := write(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(outputBuffer, "Replayed %d metrics to output %s, skipped %d corrupt entries\n", written, output.LogName(), corrupt)
+	return nil
+}
+
+func getBufferCommands(outputBuffer io.Writer) []*cli.Command {
+	bufferFlags := []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "config",
+			Usage: "configuration file to load",
+		},
+		&cli.StringSliceFlag{
+			Name:  "config-directory",
+			Usage: "directory containing additional *.conf files",
+		},
+		&cli.StringFlag{
+			Name:  "buffer-directory",
+			Usage: "directory containing the buffer files, overrides the 'buffer_directory' setting",
+		},
+	}
+
+	return []*cli.Command{
+		{
+			Name:  "buffer",
+			Usage: "commands for inspecting and replaying disk buffers of outputs",
+			Subcommands: []*cli.Command{
+				{
+					Name:  "list",
+					Usage: "list the disk buffers and their content",
+					Description: `
+The 'list' command shows all output buffers stored in the buffer directory
+including the number of metrics, the number of corrupt entries, the size on
+disk and the time range of the buffered metrics. The buffer directory is taken
+from the 'buffer_directory' setting of the configuration specified via
+'--config' or '--config-directory' or the default configuration locations.
+The configuration is also used to show the output a buffer belongs to.
+
+To list the buffers stored in '/var/lib/telegraf/buffer' use
+
+> telegraf buffer list --buffer-directory /var/lib/telegraf/buffer
+`,
+					Flags: bufferFlags,
+					Action: func(cCtx *cli.Context) error {
+						if err := logger.SetupLogging(&logger.Config{}); err != nil {
+							return err
+						}
+
+						directory, outputs, err := loadBufferConfig(cCtx, false)
+						if err != nil {
+							return err
+						}
+						return listBuffers(outputBuffer, directory, outputs)
+					},
+				},
+				{
+					Name:  "dump",
+					Usage: "print the metrics of a disk buffer",
+					Description: `
+The 'dump' command prints all metrics stored in the buffer of the output with
+the given ID using the specified data format. The metrics are not removed from
+the buffer. Use 'telegraf buffer list' to get the IDs of existing buffers.
+
+To print the metrics of a buffer as JSON use
+
+> telegraf buffer dump --data-format json <ID>
+`,
+					ArgsUsage: "<output ID>",
+					Flags: append([]cli.Flag{
+						&cli.StringFlag{
+							Name:  "data-format",
+							Usage: "serializer to format the metrics with",
+							Value: "influx",
+						},
+					}, bufferFlags...),
+					Action: func(cCtx *cli.Context) error {
+						if cCtx.NArg() != 1 {
+							return errors.New("exactly one output ID required")
+						}
+						if err := logger.SetupLogging(&logger.Config{}); err != nil {
+							return err
+						}
+
+						directory, _, err := loadBufferConfig(cCtx, false)
+						if err != nil {
+							return err
+						}
+						path, err := bufferPath(directory, cCtx.Args().First())
+						if err != nil {
+							return err
+						}
+						return dumpBuffer(outputBuffer, path, cCtx.String("data-format"))
+					},
+				},
+				{
+					Name:  "replay",
+					Usage: "write the metrics of a disk buffer to an output",
+					Description: `
+The 'replay' command writes all metrics stored in the buffer of the output with
+the given ID to an output of the configuration specified via '--config' or
+'--config-directory' or the default configuration locations. By default, the
+metrics are written to the output the buffer belongs to. Use '--output' to
+select another output by its ID, alias or name.
+The metrics are not removed from the buffer, so remove the buffer directory
+after a successful replay to avoid sending the metrics again when starting Telegraf.
+Make sure Telegraf is not running while replaying a buffer!
+
+To write the metrics of a buffer to the output with alias 'backup' use
+
+> telegraf buffer replay --config telegraf.conf --output backup <ID>
+`,
+					ArgsUsage: "<output ID>",
+					Flags: append([]cli.Flag{
+						&cli.StringFlag{
+							Name:  "output",
+							Usage: "ID, alias or name of the output to write the metrics to",
+						},
+					}, bufferFlags...),
+					Action: func(cCtx *cli.Context) error {
+						if cCtx.NArg() != 1 {
+							return errors.New("exactly one output ID required")
+						}
+						if err := logger.SetupLogging(&logger.Config{}); err != nil {
+							return err
+						}
+
+						directory, outputs, err := loadBufferConfig(cCtx, true)
+						if err != nil {
+							return err
+						}
+						id := cCtx.Args().First()
+						path, err := bufferPath(directory, id)
+						if err != nil {
+							return err
+						}
+
+						target := cCtx.String("output")
+						if target == "" {
+							target = id
+						}
+						output, err := findOutput(outputs, target)
+						if err != nil {
+							return err
+						}
+						return replayBuffer(outputBuffer, path, output)
+					},
+				},
+			},
+		},
+	}
+}
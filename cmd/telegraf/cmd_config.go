@@ -41,7 +41,8 @@ func getConfigCommands(configHandlingFlags []cli.Flag, outputBuffer io.Writer) [
 		The 'check' command reads the configuration files specified via '--config' or
 		'--config-directory' and tries to initialize, but not start, the plugins.
 		Syntax and semantic errors detectable without starting the plugins will
-		be reported.
+		be reported. The plugin options are additionally checked against the
+		schema of the plugins, see 'telegraf plugins schema'.
 		If no configuration file is	explicitly specified the command reads the
 		default locations and uses those configuration files.
 
@@ -81,6 +82,7 @@ func getConfigCommands(configHandlingFlags []cli.Flag, outputBuffer io.Writer) [
 						// Load the config and try to initialize the plugins
 						c := config.NewConfig()
 						c.Agent.Quiet = cCtx.Bool("quiet")
+						c.ValidateSchema = true
 						if err := c.LoadAll(configFiles...); err != nil {
 							return err
 						}
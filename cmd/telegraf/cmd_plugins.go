@@ -2,6 +2,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"sort"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/urfave/cli/v2"
 
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/aggregators"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/outputs"
@@ -168,6 +170,35 @@ func getPluginCommands(outputBuffer io.Writer) []*cli.Command {
 						return nil
 					},
 				},
+				{
+					Name:      "schema",
+					Usage:     "Print the JSON schema of the plugin options",
+					ArgsUsage: "[<category> or <category>.<plugin> ...]",
+					Description: `
+The 'schema' command prints a JSON schema document describing the options of
+all plugins including their types, defaults, allowed values and deprecations.
+The schema of each plugin is contained in the definitions named
+'<category>.<plugin>'. Specify categories like 'inputs' or plugins like
+'inputs.cpu' to limit the document to those plugins.
+
+To print the schema of the CPU input plugin use
+
+> telegraf plugins schema inputs.cpu
+`,
+					Action: func(cCtx *cli.Context) error {
+						schema, err := config.PluginSchemas(cCtx.Args().Slice()...)
+						if err != nil {
+							return err
+						}
+						buf, err := json.MarshalIndent(schema, "", "  ")
+						if err != nil {
+							return err
+						}
+						outputBuffer.Write(buf)
+						outputBuffer.Write([]byte("\n"))
+						return nil
+					},
+				},
 				{
 					Name:  "serializers",
 					Usage: "Print available serializer plugins",
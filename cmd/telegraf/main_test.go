@@ -2,19 +2,25 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/santhosh-tekuri/jsonschema/v5"
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
@@ -427,6 +433,64 @@ func TestCommandConfig(t *testing.T) {
 	}
 }
 
+func TestCommandBuffer(t *testing.T) {
+	directory := t.TempDir()
+	buffer, err := models.NewBuffer("test", "123", "", 0, "disk", directory, models.DiskBufferConfig{})
+	require.NoError(t, err)
+	buffer.Add(
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(1, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(2, 0)),
+	)
+	require.NoError(t, buffer.Close())
+
+	buf := new(bytes.Buffer)
+	args := append(os.Args[0:1], "buffer", "list", "--buffer-directory", directory)
+	require.NoError(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf()))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Equal(t, []string{"123", "-", "2", "0", "1970-01-01T00:00:01Z", "1970-01-01T00:00:02Z"}, slices.Delete(strings.Fields(lines[1]), 4, 5))
+
+	buf.Reset()
+	args = append(os.Args[0:1], "buffer", "dump", "--buffer-directory", directory, "123")
+	require.NoError(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf()))
+	require.Equal(t, "cpu value=1i 1000000000\ncpu value=2i 2000000000\n", buf.String())
+
+	args = append(os.Args[0:1], "buffer", "dump", "--buffer-directory", directory, "456")
+	require.ErrorContains(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf()), "no buffer found")
+}
+
+func TestCommandPluginsSchema(t *testing.T) {
+	buf := new(bytes.Buffer)
+	args := append(os.Args[0:1], "plugins", "schema")
+	require.NoError(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf()))
+
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &schema))
+	definitions := schema["$defs"].(map[string]interface{})
+	require.Contains(t, definitions, "inputs.cpu")
+	require.Contains(t, definitions, "outputs.file")
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	require.NoError(t, compiler.AddResource("schema.json", bytes.NewReader(buf.Bytes())))
+	for name, definition := range definitions {
+		t.Run(name, func(t *testing.T) {
+			plugin, err := compiler.Compile("schema.json#/$defs/" + name)
+			require.NoError(t, err)
+
+			// The defaults of the plugin must satisfy the restrictions
+			defaults := make(map[string]interface{})
+			properties := definition.(map[string]interface{})["properties"].(map[string]interface{})
+			for key, property := range properties {
+				if d, found := property.(map[string]interface{})["default"]; found {
+					defaults[key] = d
+				}
+			}
+			require.NoError(t, plugin.Validate(defaults))
+		})
+	}
+}
+
 func TestCommandVersion(t *testing.T) {
 	tests := []struct {
 		Version        string
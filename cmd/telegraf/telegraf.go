@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -81,6 +82,10 @@ type Telegraf struct {
 
 	cfg *config.Config
 
+	// Currently running agent used to apply configuration changes
+	agent   *agent.Agent
+	agentMu sync.Mutex
+
 	GlobalFlags
 	WindowFlags
 }
@@ -149,25 +154,10 @@ func (t *Telegraf) reloadLoop() error {
 		signals := make(chan os.Signal, 1)
 		signal.Notify(signals, os.Interrupt, syscall.SIGHUP,
 			syscall.SIGTERM, syscall.SIGINT)
+		changes := make(chan string, 1)
+		watched := make(map[string]bool)
 		if t.watchConfig != "" {
-			for _, fConfig := range t.configFiles {
-				if isURL(fConfig) {
-					continue
-				}
-
-				if _, err := os.Stat(fConfig); err != nil {
-					log.Printf("W! Cannot watch config %s: %s", fConfig, err)
-				} else {
-					go t.watchLocalConfig(ctx, signals, fConfig)
-				}
-			}
-			for _, fConfigDirectory := range t.configDir {
-				if _, err := os.Stat(fConfigDirectory); err != nil {
-					log.Printf("W! Cannot watch config directory %s: %s", fConfigDirectory, err)
-				} else {
-					go t.watchLocalConfig(ctx, signals, fConfigDirectory)
-				}
-			}
+			t.watchLocalConfigs(ctx, changes, watched)
 		}
 		if t.configURLWatchInterval > 0 {
 			remoteConfigs := make([]string, 0)
@@ -181,25 +171,50 @@ func (t *Telegraf) reloadLoop() error {
 			}
 		}
 		go func() {
-			select {
-			case sig := <-signals:
-				if sig == syscall.SIGHUP {
-					log.Println("I! Reloading Telegraf config")
-					// May need to update the list of known config files
-					// if a delete or create occured. That way on the reload
-					// we ensure we watch the correct files.
+			for {
+				select {
+				case sig := <-signals:
+					if sig == syscall.SIGHUP {
+						log.Println("I! Reloading Telegraf config")
+						// May need to update the list of known config files
+						// if a delete or create occured. That way on the reload
+						// we ensure we watch the correct files.
+						if err := t.getConfigFiles(); err != nil {
+							log.Println("E! Error loading config files: ", err)
+						}
+						<-reload
+						reload <- true
+					}
+					cancel()
+					return
+				case fConfig := <-changes:
+					// Try to apply the changes to the running agent and only
+					// restart the agent if this is not possible.
+					delete(watched, fConfig)
+					err := t.reloadPlugins()
+					if err == nil || !errors.Is(err, agent.ErrRestartRequired) {
+						if err != nil {
+							log.Printf("E! Reloading config failed, keeping the current config: %v", err)
+						}
+						t.watchLocalConfigs(ctx, changes, watched)
+						continue
+					}
+					log.Printf("I! Reloading Telegraf config: %v", err)
 					if err := t.getConfigFiles(); err != nil {
 						log.Println("E! Error loading config files: ", err)
 					}
 					<-reload
 					reload <- true
+					cancel()
+					return
+				case err := <-t.pprofErr:
+					log.Printf("E! pprof server failed: %v", err)
+					cancel()
+					return
+				case <-stop:
+					cancel()
+					return
 				}
-				cancel()
-			case err := <-t.pprofErr:
-				log.Printf("E! pprof server failed: %v", err)
-				cancel()
-			case <-stop:
-				cancel()
 			}
 		}()
 
@@ -213,7 +228,69 @@ func (t *Telegraf) reloadLoop() error {
 	return nil
 }
 
-func (t *Telegraf) watchLocalConfig(ctx context.Context, signals chan os.Signal, fConfig string) {
+// reloadPlugins loads the configuration and applies the plugin changes to the
+// running agent. An error wrapping agent.ErrRestartRequired is returned if the
+// changes require a restart of the agent.
+func (t *Telegraf) reloadPlugins() error {
+	t.agentMu.Lock()
+	ag := t.agent
+	t.agentMu.Unlock()
+	if ag == nil {
+		return fmt.Errorf("%w: agent is not running", agent.ErrRestartRequired)
+	}
+
+	log.Println("I! Reloading changed plugins")
+
+	// Keep using the running secret-stores, so the secrets of the new
+	// plugins are resolved by the same instances as the running plugins.
+	// Use memory buffers to not open the buffer files of running outputs,
+	// the agent opens the buffers of the outputs it adds.
+	c := t.newConfiguration()
+	c.ReuseSecretStores(ag.Config)
+	c.MemoryBuffers = true
+	if err := t.loadConfigurationInto(c); err != nil {
+		return err
+	}
+	if len(c.Outputs) == 0 {
+		return errors.New("no outputs found, probably invalid config file provided")
+	}
+	if t.plugindDir == "" && len(c.Inputs) == 0 {
+		return errors.New("no inputs found, probably invalid config file provided")
+	}
+
+	return ag.Reload(c)
+}
+
+// watchLocalConfigs starts a watcher for each local config file and directory
+// not being watched yet.
+func (t *Telegraf) watchLocalConfigs(ctx context.Context, changes chan<- string, watched map[string]bool) {
+	for _, fConfig := range t.configFiles {
+		if isURL(fConfig) || watched[fConfig] {
+			continue
+		}
+
+		if _, err := os.Stat(fConfig); err != nil {
+			log.Printf("W! Cannot watch config %s: %s", fConfig, err)
+		} else {
+			watched[fConfig] = true
+			go t.watchLocalConfig(ctx, changes, fConfig)
+		}
+	}
+	for _, fConfigDirectory := range t.configDir {
+		if watched[fConfigDirectory] {
+			continue
+		}
+
+		if _, err := os.Stat(fConfigDirectory); err != nil {
+			log.Printf("W! Cannot watch config directory %s: %s", fConfigDirectory, err)
+		} else {
+			watched[fConfigDirectory] = true
+			go t.watchLocalConfig(ctx, changes, fConfigDirectory)
+		}
+	}
+}
+
+func (t *Telegraf) watchLocalConfig(ctx context.Context, changes chan<- string, fConfig string) {
 	var mytomb tomb.Tomb
 	var watcher watch.FileWatcher
 	if t.watchConfig == "poll" {
@@ -225,7 +302,7 @@ func (t *Telegraf) watchLocalConfig(ctx context.Context, signals chan os.Signal,
 	} else {
 		watcher = watch.NewInotifyFileWatcher(fConfig)
 	}
-	changes, err := watcher.ChangeEvents(&mytomb, 0)
+	changeEvents, err := watcher.ChangeEvents(&mytomb, 0)
 	if err != nil {
 		log.Printf("E! Error watching config file/directory %q: %s\n", fConfig, err)
 		return
@@ -235,9 +312,9 @@ func (t *Telegraf) watchLocalConfig(ctx context.Context, signals chan os.Signal,
 	case <-ctx.Done():
 		mytomb.Done()
 		return
-	case <-changes.Modified:
+	case <-changeEvents.Modified:
 		log.Printf("I! Config file/directory %q modified\n", fConfig)
-	case <-changes.Deleted:
+	case <-changeEvents.Deleted:
 		// deleted can mean moved. wait a bit a check existence
 		<-time.After(time.Second)
 		if _, err := os.Stat(fConfig); err == nil {
@@ -245,16 +322,20 @@ func (t *Telegraf) watchLocalConfig(ctx context.Context, signals chan os.Signal,
 		} else {
 			log.Printf("W! Config file/directory %q deleted\n", fConfig)
 		}
-	case <-changes.Truncated:
+	case <-changeEvents.Truncated:
 		log.Printf("I! Config file/directory %q truncated\n", fConfig)
-	case <-changes.Created:
+	case <-changeEvents.Created:
 		log.Printf("I! Config directory %q has new file(s)\n", fConfig)
 	case <-mytomb.Dying():
 		log.Printf("I! Config watcher %q ended\n", fConfig)
 		return
 	}
 	mytomb.Done()
-	signals <- syscall.SIGHUP
+
+	select {
+	case changes <- fConfig:
+	case <-ctx.Done():
+	}
 }
 
 func (*Telegraf) watchRemoteConfigs(ctx context.Context, signals chan os.Signal, interval time.Duration, remoteConfigs []string) {
@@ -311,20 +392,29 @@ func (*Telegraf) watchRemoteConfigs(ctx context.Context, signals chan os.Signal,
 
 func (t *Telegraf) loadConfiguration() (*config.Config, error) {
 	// If no other options are specified, load the config file and run.
+	c := t.newConfiguration()
+	return c, t.loadConfigurationInto(c)
+}
+
+// newConfiguration creates an empty configuration using the command-line
+// settings.
+func (t *Telegraf) newConfiguration() *config.Config {
 	c := config.NewConfig()
 	c.Agent.Quiet = t.quiet
 	c.Agent.ConfigURLRetryAttempts = t.configURLRetryAttempts
 	c.OutputFilters = t.outputFilters
 	c.InputFilters = t.inputFilters
 	c.SecretStoreFilters = t.secretstoreFilters
+	return c
+}
 
+// loadConfigurationInto loads the configuration files into the given
+// configuration.
+func (t *Telegraf) loadConfigurationInto(c *config.Config) error {
 	if err := t.getConfigFiles(); err != nil {
-		return c, err
-	}
-	if err := c.LoadAll(t.configFiles...); err != nil {
-		return c, err
+		return err
 	}
-	return c, nil
+	return c.LoadAll(t.configFiles...)
 }
 
 func (t *Telegraf) getConfigFiles() error {
@@ -388,6 +478,8 @@ func (t *Telegraf) runAgent(ctx context.Context, reloadConfig bool) error {
 		RotationMaxSize:         int64(c.Agent.LogfileRotationMaxSize),
 		RotationMaxArchives:     c.Agent.LogfileRotationMaxArchives,
 		LogWithTimezone:         c.Agent.LogWithTimezone,
+		DedupInterval:           time.Duration(c.Agent.LogDedupInterval),
+		DedupLimit:              c.Agent.LogDedupLimit,
 	}
 
 	if err := logger.SetupLogging(logConfig); err != nil {
@@ -444,6 +536,14 @@ func (t *Telegraf) runAgent(ctx context.Context, reloadConfig bool) error {
 		}
 	}
 	ag := agent.NewAgent(c)
+	t.agentMu.Lock()
+	t.agent = ag
+	t.agentMu.Unlock()
+	defer func() {
+		t.agentMu.Lock()
+		t.agent = nil
+		t.agentMu.Unlock()
+	}()
 
 	// Notify systemd that telegraf is ready
 	// SdNotify() only tries to notify if the NOTIFY_SOCKET environment is set, so it's safe to call when systemd isn't present.
@@ -14,6 +14,7 @@ import (
 	"reflect"
 	"regexp"
 	"runtime"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -23,6 +24,7 @@ import (
 	"github.com/coreos/go-semver/semver"
 	"github.com/influxdata/toml"
 	"github.com/influxdata/toml/ast"
+	"github.com/santhosh-tekuri/jsonschema/v5"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
@@ -81,6 +83,17 @@ type Config struct {
 
 	SecretStores      map[string]telegraf.SecretStore
 	secretStoreSource map[string][]string
+	secretStoreIDs    map[string]string
+	secretHandlers    map[*Secret][]telegraf.SecretChangeHandler
+
+	// Plugins to notify about changes of the secret with the given reference
+	secretChangeHandlers   map[string][]telegraf.SecretChangeHandler
+	secretChangeHandlersMu sync.Mutex
+
+	// Running secret-stores to use instead of creating new instances with
+	// identical settings, see ReuseSecretStores
+	reusableSecretStores   map[string]telegraf.SecretStore
+	reusableSecretStoreIDs map[string]string
 
 	Agent       *AgentConfig
 	Inputs      []*models.RunningInput
@@ -92,6 +105,15 @@ type Config struct {
 	fileProcessors    OrderedPlugins
 	fileAggProcessors OrderedPlugins
 
+	Routes map[string]RouteConfig
+
+	// Templates referenced by plugins via `use_template`
+	templates map[string]*ast.Table
+
+	outputGroups     map[string]*outputGroup
+	groupMembers     []*groupMember
+	fileGroupMembers OrderedPlugins
+
 	// Parsers are created by their inputs during gather. Config doesn't keep track of them
 	// like the other plugins because they need to be garbage collected (See issue #11809)
 
@@ -103,6 +125,17 @@ type Config struct {
 
 	seenAgentTable     bool
 	seenAgentTableOnce sync.Once
+
+	// ValidateSchema enables checking the plugin options against the
+	// schema of the plugin while loading
+	ValidateSchema bool
+	schemas        map[string]*jsonschema.Schema
+
+	// MemoryBuffers forces all outputs to use memory buffers independent of
+	// the configured buffer strategy, e.g. to access the buffer files of
+	// outputs without opening or modifying them. The configured buffers are
+	// created when calling OpenBuffer on the outputs.
+	MemoryBuffers bool
 }
 
 // Ordered plugins used to keep the order in which they appear in a file
@@ -139,12 +172,15 @@ func NewConfig() *Config {
 		AggProcessors:      make([]*models.RunningProcessor, 0),
 		SecretStores:       make(map[string]telegraf.SecretStore),
 		secretStoreSource:  make(map[string][]string),
+		secretStoreIDs:     make(map[string]string),
+		secretHandlers:     make(map[*Secret][]telegraf.SecretChangeHandler),
 		fileProcessors:     make([]*OrderedPlugin, 0),
 		fileAggProcessors:  make([]*OrderedPlugin, 0),
 		InputFilters:       make([]string, 0),
 		OutputFilters:      make([]string, 0),
 		SecretStoreFilters: make([]string, 0),
 		Deprecations:       make(map[string][]int64),
+		templates:          make(map[string]*ast.Table),
 	}
 
 	// Handle unknown version
@@ -195,6 +231,20 @@ type AgentConfig struct {
 	// at the same time by manually scheduling them in time.
 	CollectionOffset Duration
 
+	// CollectionSpread shifts the collection of each input by an additional
+	// offset within its interval derived from the plugin. This distributes
+	// inputs sharing the same interval across the interval.
+	CollectionSpread bool `toml:"collection_spread"`
+
+	// MaxConcurrentGathers limits the number of Gather calls running at the
+	// same time across all inputs. Zero means unlimited.
+	MaxConcurrentGathers int `toml:"max_concurrent_gathers"`
+
+	// MaxConcurrentGathersPerPlugin limits the number of Gather calls running
+	// at the same time for inputs of the same plugin type. Zero means
+	// unlimited.
+	MaxConcurrentGathersPerPlugin int `toml:"max_concurrent_gathers_per_plugin"`
+
 	// FlushInterval is the Interval at which to flush data
 	FlushInterval Duration
 
@@ -237,10 +287,13 @@ type AgentConfig struct {
 	LogTarget string `toml:"logtarget" deprecated:"1.32.0;1.40.0;use 'logformat' and 'logfile' instead"`
 
 	// Log format controls the way messages are logged and can be one of "text",
-	// "structured" or, on Windows, "eventlog".
+	// "structured", "syslog", "journald" (Linux only) or, on Windows,
+	// "eventlog".
 	LogFormat string `toml:"logformat"`
 
-	// Name of the file to be logged to or stderr if empty. Ignored for "eventlog" format.
+	// Name of the file to be logged to or stderr if empty. For the "syslog"
+	// and "journald" format this is the socket to log to. Ignored for
+	// "eventlog" format.
 	Logfile string `toml:"logfile"`
 
 	// Message key for structured logs, to override the default of "msg".
@@ -262,6 +315,11 @@ type AgentConfig struct {
 	// Pick a timezone to use when logging or type 'local' for local time.
 	LogWithTimezone string `toml:"log_with_timezone"`
 
+	// Suppress identical log messages of a plugin exceeding the limit within
+	// the interval. When set to 0 no deduplication is performed.
+	LogDedupInterval Duration `toml:"log_dedup_interval"`
+	LogDedupLimit    int      `toml:"log_dedup_limit"`
+
 	Hostname     string
 	OmitHostname bool
 
@@ -275,6 +333,34 @@ type AgentConfig struct {
 	// the state in the file will be restored for the plugins.
 	Statefile string `toml:"statefile"`
 
+	// StatefileInterval is the interval for periodically storing the state of
+	// stateful plugins in addition to storing the state on termination. Zero
+	// disables periodic storing.
+	StatefileInterval Duration `toml:"statefile_interval"`
+
+	// StatefileBackend is the storage for the state of stateful plugins.
+	// Supported backends are "file", the default, storing all states in
+	// 'statefile', "directory" storing one file per plugin in the 'statefile'
+	// directory, "sqlite" using 'statefile' as database and "http" storing
+	// the states at the 'statefile' URL.
+	StatefileBackend string `toml:"statefile_backend"`
+
+	// StatefileHeaders are additional headers sent to the "http" backend.
+	StatefileHeaders map[string]string `toml:"statefile_headers"`
+
+	// StatefileS3Region enables signing the requests of the "http" backend
+	// to store the states in an S3 bucket of the given region.
+	StatefileS3Region string `toml:"statefile_s3_region"`
+
+	// Address to serve the local control API on, e.g. "localhost:8089". The
+	// API exposes the state of the running plugins and allows to trigger
+	// gathering and flushing. Disabled if empty.
+	ControlAddress string `toml:"control_address"`
+
+	// Address to serve the internal statistics on in the Prometheus and
+	// OpenMetrics formats, e.g. "localhost:9274". Disabled if empty.
+	SelfstatAddress string `toml:"selfstat_address"`
+
 	// Flag to always keep tags explicitly defined in the plugin itself and
 	// ensure those tags always pass filtering.
 	AlwaysIncludeLocalTags bool `toml:"always_include_local_tags"`
@@ -299,6 +385,19 @@ type AgentConfig struct {
 	// BufferDirectory is the directory to store buffer files for serialized
 	// to disk metrics when using the "disk" buffer strategy.
 	BufferDirectory string `toml:"buffer_directory"`
+
+	// BufferMaxDiskSize is the maximum size of the buffer files of each output
+	// plugin when using the "disk" buffer strategy. Zero means unlimited.
+	BufferMaxDiskSize Size `toml:"buffer_max_disk_size"`
+
+	// BufferEvictionPolicy determines what happens if the buffer files reach
+	// the maximum size. Supported policies are "drop_oldest" to remove the
+	// oldest metrics and "reject_new" to drop new metrics.
+	BufferEvictionPolicy string `toml:"buffer_eviction_policy"`
+
+	// BufferCompression is the algorithm used to compress the metrics in the
+	// buffer files. Supported algorithms are "none" and "zstd".
+	BufferCompression string `toml:"buffer_compression"`
 }
 
 // InputNames returns a list of strings of the configured inputs.
@@ -408,6 +507,18 @@ func (c *Config) SecretstoreNamesWithSources() string {
 	return getPluginSourcesTable(plugins)
 }
 
+// SecretStoreIDs returns the configuration IDs of all secret-stores keyed by
+// the store ID given by the user. Identically configured stores result in the
+// same configuration ID, so the IDs can be used to detect changes between two
+// configurations.
+func (c *Config) SecretStoreIDs() map[string]string {
+	ids := make(map[string]string, len(c.secretStoreIDs))
+	for k, v := range c.secretStoreIDs {
+		ids[k] = v
+	}
+	return ids
+}
+
 // PluginNameCounts returns a string of plugin names and their counts.
 // PluginNameCounts returns a list of sorted plugin names and their count
 func PluginNameCounts(plugins []string) []string {
@@ -559,6 +670,13 @@ func (c *Config) LoadAll(configFiles ...string) error {
 		}
 	}
 
+	if err := c.linkOutputGroups(); err != nil {
+		return err
+	}
+	if err := c.linkRoutes(); err != nil {
+		return err
+	}
+
 	// Sort the processors according to their `order` setting while
 	// using a stable sort to keep the file loading / file position order.
 	sort.Stable(c.Processors)
@@ -600,6 +718,14 @@ func (c *Config) LoadConfigData(data []byte, path string) error {
 		return fmt.Errorf("error parsing data: %w", err)
 	}
 
+	// Resolve the templates before parsing any table
+	if err := c.collectTemplates(tbl); err != nil {
+		return err
+	}
+	if err := c.applyTemplates(tbl); err != nil {
+		return err
+	}
+
 	// Parse tags tables first:
 	for _, tableName := range []string{"tags", "global_tags"} {
 		if val, ok := tbl.Fields[tableName]; ok {
@@ -657,6 +783,9 @@ func (c *Config) LoadConfigData(data []byte, path string) error {
 	if c.Agent.Statefile != "" {
 		c.Persister = &persister.Persister{
 			Filename: c.Agent.Statefile,
+			Backend:  c.Agent.StatefileBackend,
+			Headers:  c.Agent.StatefileHeaders,
+			S3Region: c.Agent.StatefileS3Region,
 		}
 	}
 
@@ -670,6 +799,7 @@ func (c *Config) LoadConfigData(data []byte, path string) error {
 	// Initialize the file-sorting slices
 	c.fileProcessors = make(OrderedPlugins, 0)
 	c.fileAggProcessors = make(OrderedPlugins, 0)
+	c.fileGroupMembers = make(OrderedPlugins, 0)
 
 	// Parse all the rest of the plugins:
 	for name, val := range tbl.Fields {
@@ -679,7 +809,7 @@ func (c *Config) LoadConfigData(data []byte, path string) error {
 		}
 
 		switch name {
-		case "agent", "global_tags", "tags":
+		case "agent", "global_tags", "tags", "templates":
 		case "outputs":
 			for pluginName, pluginVal := range subTable.Fields {
 				switch pluginSubTable := pluginVal.(type) {
@@ -774,6 +904,38 @@ func (c *Config) LoadConfigData(data []byte, path string) error {
 						name, pluginName, subTable.Line, keys(c.UnusedFields))
 				}
 			}
+		case "routes":
+			for routeName, routeVal := range subTable.Fields {
+				routeSubTable, ok := routeVal.(*ast.Table)
+				if !ok {
+					return fmt.Errorf("unsupported config format: route %s", routeName)
+				}
+				if err = c.addRoute(routeName, routeSubTable); err != nil {
+					return fmt.Errorf("error parsing route %s, %w", routeName, err)
+				}
+				if len(c.UnusedFields) > 0 {
+					return fmt.Errorf(
+						"route %s: line %d: configuration specified the fields %q, but they were not used. "+
+							"This is either a typo or this config option does not exist in this version.",
+						routeName, routeSubTable.Line, keys(c.UnusedFields))
+				}
+			}
+		case "output_groups":
+			for groupName, groupVal := range subTable.Fields {
+				groupSubTable, ok := groupVal.(*ast.Table)
+				if !ok {
+					return fmt.Errorf("unsupported config format: output group %s", groupName)
+				}
+				if err = c.addOutputGroup(groupName, path, groupSubTable); err != nil {
+					return fmt.Errorf("error parsing output group %s, %w", groupName, err)
+				}
+				if len(c.UnusedFields) > 0 {
+					return fmt.Errorf(
+						"output group %s: line %d: configuration specified the fields %q, but they were not used. "+
+							"This is either a typo or this config option does not exist in this version.",
+						groupName, groupSubTable.Line, keys(c.UnusedFields))
+				}
+			}
 		case "secretstores":
 			for pluginName, pluginVal := range subTable.Fields {
 				switch pluginSubTable := pluginVal.(type) {
@@ -814,6 +976,12 @@ func (c *Config) LoadConfigData(data []byte, path string) error {
 		c.AggProcessors = append(c.AggProcessors, op.plugin.(*models.RunningProcessor))
 	}
 
+	// Keep the order of output group members as this defines their priority
+	sort.Sort(c.fileGroupMembers)
+	for _, op := range c.fileGroupMembers {
+		c.groupMembers = append(c.groupMembers, op.plugin.(*groupMember))
+	}
+
 	return nil
 }
 
@@ -955,7 +1123,11 @@ func (c *Config) addAggregator(name, source string, table *ast.Table) error {
 		return err
 	}
 
-	if err := c.toml.UnmarshalTable(table, aggregator); err != nil {
+	if err := c.validateOptions("aggregators", name, aggregator, table); err != nil {
+		return err
+	}
+
+	if err := c.unmarshalPlugin(table, aggregator); err != nil {
 		return err
 	}
 
@@ -980,6 +1152,20 @@ func (c *Config) addSecretStore(name, source string, table *ast.Table) error {
 		return fmt.Errorf("invalid secret-store ID %q, must only contain letters, numbers or underscore", storeID)
 	}
 
+	if _, found := c.SecretStores[storeID]; found {
+		return fmt.Errorf("duplicate ID %q for secretstore %q", storeID, name)
+	}
+	configID, err := generatePluginID("secretstores."+name, table)
+	if err != nil {
+		return fmt.Errorf("generating ID for secret-store %q failed: %w", storeID, err)
+	}
+
+	// Use the running secret-store if the settings did not change
+	if store, found := c.reusableSecretStores[storeID]; found && c.reusableSecretStoreIDs[storeID] == configID {
+		c.registerSecretStore(name, source, storeID, configID, store)
+		return nil
+	}
+
 	creator, ok := secretstores.SecretStores[name]
 	if !ok {
 		// Handle removed, deprecated plugins
@@ -991,7 +1177,11 @@ func (c *Config) addSecretStore(name, source string, table *ast.Table) error {
 	}
 	store := creator(storeID)
 
-	if err := c.toml.UnmarshalTable(table, store); err != nil {
+	if err := c.validateOptions("secretstores", name, store, table); err != nil {
+		return err
+	}
+
+	if err := c.unmarshalPlugin(table, store); err != nil {
 		return err
 	}
 
@@ -1006,19 +1196,46 @@ func (c *Config) addSecretStore(name, source string, table *ast.Table) error {
 		return fmt.Errorf("error initializing secret-store %q: %w", storeID, err)
 	}
 
-	if _, found := c.SecretStores[storeID]; found {
-		return fmt.Errorf("duplicate ID %q for secretstore %q", storeID, name)
-	}
+	c.registerSecretStore(name, source, storeID, configID, store)
+	return nil
+}
+
+// registerSecretStore adds the secret-store with the given IDs to the
+// configuration.
+func (c *Config) registerSecretStore(name, source, storeID, configID string, store telegraf.SecretStore) {
 	c.SecretStores[storeID] = store
+	c.secretStoreIDs[storeID] = configID
 	if _, found := c.secretStoreSource[name]; !found {
 		c.secretStoreSource[name] = make([]string, 0)
 	}
 	c.secretStoreSource[name] = append(c.secretStoreSource[name], source)
-	return nil
+}
+
+// ReuseSecretStores makes the configuration use the secret-stores of the
+// given running configuration instead of creating new instances if the
+// settings of a secret-store did not change. This must be called before
+// loading the configuration, e.g. to keep the running secret-stores and
+// their background tasks when reloading the configuration.
+func (c *Config) ReuseSecretStores(running *Config) {
+	c.reusableSecretStores = running.SecretStores
+	c.reusableSecretStoreIDs = running.secretStoreIDs
 }
 
 func (c *Config) LinkSecrets() error {
-	for _, s := range unlinkedSecrets {
+	c.secretChangeHandlersMu.Lock()
+	defer c.secretChangeHandlersMu.Unlock()
+	if c.secretChangeHandlers == nil {
+		c.secretChangeHandlers = make(map[string][]telegraf.SecretChangeHandler)
+	}
+	handlers := c.secretChangeHandlers
+
+	// Only link the secrets of the plugins loaded since the last call as the
+	// dynamic secrets of previous configurations, e.g. of the running
+	// configuration when reloading, cannot be linked again
+	secrets := unlinkedSecrets
+	unlinkedSecrets = make([]*Secret, 0)
+
+	for _, s := range secrets {
 		resolvers := make(map[string]telegraf.ResolveFunc)
 		for _, ref := range s.GetUnlinked() {
 			// Split the reference and lookup the resolver
@@ -1037,6 +1254,85 @@ func (c *Config) LinkSecrets() error {
 		if err := s.Link(resolvers); err != nil {
 			return fmt.Errorf("retrieving resolver failed: %w", err)
 		}
+
+		// Only dynamic secrets are resolved again so plugins can only make
+		// use of changes to those
+		for ref := range s.resolvers {
+			for _, h := range c.secretHandlers[s] {
+				if !slices.Contains(handlers[ref], h) {
+					handlers[ref] = append(handlers[ref], h)
+				}
+			}
+		}
+	}
+
+	// Forward changes signaled by the secret-stores to the plugins using the
+	// secrets. Reused secret-stores keep notifying the plugins of the running
+	// configuration, see UpdateSecretChangeHandlers.
+	for storeID, store := range c.SecretStores {
+		notifier, ok := store.(telegraf.SecretChangeNotifier)
+		if !ok {
+			continue
+		}
+		if reused, found := c.reusableSecretStores[storeID]; found && reused == store {
+			continue
+		}
+		notifier.SetSecretChangeCallback(func(key string) {
+			c.secretChanged("@{" + storeID + ":" + key + "}")
+		})
+	}
+	return nil
+}
+
+// secretChanged notifies the plugins using the secret with the given
+// reference about a change.
+func (c *Config) secretChanged(ref string) {
+	c.secretChangeHandlersMu.Lock()
+	defer c.secretChangeHandlersMu.Unlock()
+
+	for _, h := range c.secretChangeHandlers[ref] {
+		go h.SecretChanged(ref)
+	}
+}
+
+// UpdateSecretChangeHandlers replaces the plugins notified about secret
+// changes by the plugins of this and the given configuration for which the
+// running function returns true. Use this after reloading plugins with a
+// configuration reusing the secret-stores of this configuration.
+func (c *Config) UpdateSecretChangeHandlers(cfg *Config, running func(plugin any) bool) {
+	cfg.secretChangeHandlersMu.Lock()
+	added := cfg.secretChangeHandlers
+	cfg.secretChangeHandlersMu.Unlock()
+
+	c.secretChangeHandlersMu.Lock()
+	defer c.secretChangeHandlersMu.Unlock()
+
+	handlers := make(map[string][]telegraf.SecretChangeHandler)
+	for _, current := range []map[string][]telegraf.SecretChangeHandler{c.secretChangeHandlers, added} {
+		for ref, hs := range current {
+			for _, h := range hs {
+				if running(h) && !slices.Contains(handlers[ref], h) {
+					handlers[ref] = append(handlers[ref], h)
+				}
+			}
+		}
+	}
+	c.secretChangeHandlers = handlers
+}
+
+// unmarshalPlugin unmarshals the table into the given plugin and keeps track
+// of the plugin's secrets referencing secret-stores to notify the plugin
+// about changes of those secrets.
+func (c *Config) unmarshalPlugin(table *ast.Table, plugin interface{}) error {
+	start := len(unlinkedSecrets)
+	if err := c.toml.UnmarshalTable(table, plugin); err != nil {
+		return err
+	}
+
+	if h, ok := plugin.(telegraf.SecretChangeHandler); ok {
+		for _, s := range unlinkedSecrets[start:] {
+			c.secretHandlers[s] = append(c.secretHandlers[s], h)
+		}
 	}
 	return nil
 }
@@ -1090,6 +1386,10 @@ func (c *Config) addParser(parentcategory, parentname string, table *ast.Table)
 		}
 	}
 
+	if err := c.validateOptions("parsers", conf.DataFormat, parser, table); err != nil {
+		return nil, err
+	}
+
 	if err := c.toml.UnmarshalTable(table, parser); err != nil {
 		return nil, err
 	}
@@ -1134,6 +1434,10 @@ func (c *Config) addSerializer(parentname string, table *ast.Table) (*models.Run
 	}
 	serializer := creator()
 
+	if err := c.validateOptions("serializers", conf.DataFormat, serializer, table); err != nil {
+		return nil, err
+	}
+
 	if err := c.toml.UnmarshalTable(table, serializer); err != nil {
 		return nil, err
 	}
@@ -1258,7 +1562,11 @@ func (c *Config) setupProcessor(name string, creator processors.StreamingCreator
 		optionTestCount++
 	}
 
-	if err := c.toml.UnmarshalTable(table, processor); err != nil {
+	if err := c.validateOptions("processors", name, processor, table); err != nil {
+		return nil, 0, err
+	}
+
+	if err := c.unmarshalPlugin(table, processor); err != nil {
 		return nil, 0, fmt.Errorf("unmarshalling failed: %w", err)
 	}
 
@@ -1319,7 +1627,11 @@ func (c *Config) addOutput(name, source string, table *ast.Table) error {
 		return err
 	}
 
-	if err := c.toml.UnmarshalTable(table, output); err != nil {
+	if err := c.validateOptions("outputs", name, output, table); err != nil {
+		return err
+	}
+
+	if err := c.unmarshalPlugin(table, output); err != nil {
 		return err
 	}
 
@@ -1343,6 +1655,10 @@ func (c *Config) addOutput(name, source string, table *ast.Table) error {
 		}
 	}
 
+	if group := c.getFieldString(table, "group"); group != "" {
+		return c.addGroupMember(group, output, outputConfig, table)
+	}
+
 	ro := models.NewRunningOutput(output, outputConfig, c.Agent.MetricBatchSize, c.Agent.MetricBufferLimit)
 	c.Outputs = append(c.Outputs, ro)
 
@@ -1403,7 +1719,11 @@ func (c *Config) addInput(name, source string, table *ast.Table) error {
 		return err
 	}
 
-	if err := c.toml.UnmarshalTable(table, input); err != nil {
+	if err := c.validateOptions("inputs", name, input, table); err != nil {
+		return err
+	}
+
+	if err := c.unmarshalPlugin(table, input); err != nil {
 		return err
 	}
 
@@ -1417,9 +1737,10 @@ func (c *Config) addInput(name, source string, table *ast.Table) error {
 		}
 	}
 
-	// Check the number of misses against the threshold
+	// Check the number of misses against the threshold, the processors
+	// attached to the input are handled below
 	for key, count := range missCount {
-		if count <= missCountThreshold {
+		if count <= missCountThreshold || key == "processors" {
 			continue
 		}
 		if err := c.missingTomlField(nil, key); err != nil {
@@ -1427,8 +1748,16 @@ func (c *Config) addInput(name, source string, table *ast.Table) error {
 		}
 	}
 
+	// Setup the processors attached to the input after checking the
+	// options of the input as the processors use their own tracker
+	inputProcessors, err := c.addInputProcessors(name, pluginConfig.ID, source, table)
+	if err != nil {
+		return err
+	}
+
 	rp := models.NewRunningInput(input, pluginConfig)
 	rp.SetDefaultTags(c.Tags)
+	rp.Processors = inputProcessors
 	c.Inputs = append(c.Inputs, rp)
 
 	return nil
@@ -1584,6 +1913,7 @@ func (c *Config) buildFilter(plugin string, tbl *ast.Table) (models.Filter, erro
 	f.TagInclude = c.getFieldStringSlice(tbl, "taginclude")
 
 	f.MetricPass = c.getFieldString(tbl, "metricpass")
+	f.MetricTransform = c.getFieldStringSlice(tbl, "metric_transform")
 
 	if c.hasErrs() {
 		return f, c.firstErr()
@@ -1612,6 +1942,9 @@ func (c *Config) buildInput(name, source string, tbl *ast.Table) (*models.InputC
 	cp.CollectionOffset, _ = c.getFieldDuration(tbl, "collection_offset")
 	cp.StartupErrorBehavior = c.getFieldString(tbl, "startup_error_behavior")
 	cp.TimeSource = c.getFieldString(tbl, "time_source")
+	cp.Route = c.getFieldString(tbl, "route")
+	schedule := c.getFieldString(tbl, "schedule")
+	scheduleTimezone := c.getFieldString(tbl, "schedule_timezone")
 
 	cp.MeasurementPrefix = c.getFieldString(tbl, "name_prefix")
 	cp.MeasurementSuffix = c.getFieldString(tbl, "name_suffix")
@@ -1619,6 +1952,27 @@ func (c *Config) buildInput(name, source string, tbl *ast.Table) (*models.InputC
 	cp.Alias = c.getFieldString(tbl, "alias")
 	cp.LogLevel = c.getFieldString(tbl, "log_level")
 
+	if node, ok := tbl.Fields["backoff"]; ok {
+		subtbl, ok := node.(*ast.Table)
+		if !ok {
+			return nil, fmt.Errorf("invalid backoff settings for input %s", name)
+		}
+		var backoff backoffConfig
+		if err := c.toml.UnmarshalTable(subtbl, &backoff); err != nil {
+			return nil, fmt.Errorf("could not parse backoff settings for input %s: %w", name, err)
+		}
+		cp.Backoff = &models.BackoffConfig{
+			MaxInterval: time.Duration(backoff.MaxInterval),
+			Multiplier:  backoff.Multiplier,
+		}
+	}
+
+	cardinality, err := c.buildCardinality("input "+name, tbl)
+	if err != nil {
+		return nil, err
+	}
+	cp.Cardinality = cardinality
+
 	cp.Tags = make(map[string]string)
 	if node, ok := tbl.Fields["tags"]; ok {
 		if subtbl, ok := node.(*ast.Table); ok {
@@ -1632,7 +1986,18 @@ func (c *Config) buildInput(name, source string, tbl *ast.Table) (*models.InputC
 		return nil, c.firstErr()
 	}
 
-	var err error
+	if schedule != "" {
+		cp.Schedule, err = internal.ParseSchedule(schedule, scheduleTimezone)
+		if err != nil {
+			return nil, err
+		}
+		if cp.Interval != 0 {
+			return nil, errors.New("'schedule' cannot be combined with 'interval'")
+		}
+	} else if scheduleTimezone != "" {
+		return nil, errors.New("'schedule_timezone' requires a 'schedule'")
+	}
+
 	cp.Filter, err = c.buildFilter("inputs."+name, tbl)
 	if err != nil {
 		return cp, err
@@ -1643,6 +2008,53 @@ func (c *Config) buildInput(name, source string, tbl *ast.Table) (*models.InputC
 	return cp, err
 }
 
+// backoffConfig contains the settings of the backoff table of inputs.
+type backoffConfig struct {
+	MaxInterval Duration `toml:"max_interval"`
+	Multiplier  float64  `toml:"multiplier"`
+}
+
+// cardinalityConfig contains the settings of the cardinality table of inputs
+// and outputs.
+type cardinalityConfig struct {
+	MaxSeries int      `toml:"max_series" minimum:"1"`
+	Window    Duration `toml:"window"`
+	Action    string   `toml:"action" enum:"drop,aggregate,strip"`
+	StripTags []string `toml:"strip_tags"`
+}
+
+// buildCardinality parses the cardinality table of the given plugin if any.
+func (c *Config) buildCardinality(plugin string, tbl *ast.Table) (*models.CardinalityConfig, error) {
+	node, ok := tbl.Fields["cardinality"]
+	if !ok {
+		return nil, nil
+	}
+	subtbl, ok := node.(*ast.Table)
+	if !ok {
+		return nil, fmt.Errorf("invalid cardinality settings for %s", plugin)
+	}
+	var cardinality cardinalityConfig
+	if err := c.toml.UnmarshalTable(subtbl, &cardinality); err != nil {
+		return nil, fmt.Errorf("could not parse cardinality settings for %s: %w", plugin, err)
+	}
+	return &models.CardinalityConfig{
+		MaxSeries: cardinality.MaxSeries,
+		Window:    time.Duration(cardinality.Window),
+		Action:    cardinality.Action,
+		StripTags: cardinality.StripTags,
+	}, nil
+}
+
+// retryConfig contains the settings of the retry table of outputs.
+type retryConfig struct {
+	InitialDelay Duration `toml:"initial_delay"`
+	MaxDelay     Duration `toml:"max_delay"`
+	Multiplier   float64  `toml:"multiplier" minimum:"1"`
+	Jitter       float64  `toml:"jitter" minimum:"0" maximum:"1"`
+	MaxAttempts  int      `toml:"max_attempts" minimum:"0"`
+	DeadLetter   string   `toml:"dead_letter"`
+}
+
 // buildOutput parses output specific items from the ast.Table,
 // builds the filter and returns a
 // models.OutputConfig to be inserted into models.RunningInput
@@ -1653,11 +2065,14 @@ func (c *Config) buildOutput(name, source string, tbl *ast.Table) (*models.Outpu
 		return nil, err
 	}
 	oc := &models.OutputConfig{
-		Name:            name,
-		Source:          source,
-		Filter:          filter,
-		BufferStrategy:  c.Agent.BufferStrategy,
-		BufferDirectory: c.Agent.BufferDirectory,
+		Name:                 name,
+		Source:               source,
+		Filter:               filter,
+		BufferStrategy:       c.Agent.BufferStrategy,
+		BufferDirectory:      c.Agent.BufferDirectory,
+		BufferMaxDiskSize:    int64(c.Agent.BufferMaxDiskSize),
+		BufferEvictionPolicy: c.Agent.BufferEvictionPolicy,
+		BufferCompression:    c.Agent.BufferCompression,
 	}
 
 	// TODO: support FieldPass/FieldDrop on outputs
@@ -1672,13 +2087,39 @@ func (c *Config) buildOutput(name, source string, tbl *ast.Table) (*models.Outpu
 	oc.NamePrefix = c.getFieldString(tbl, "name_prefix")
 	oc.StartupErrorBehavior = c.getFieldString(tbl, "startup_error_behavior")
 	oc.LogLevel = c.getFieldString(tbl, "log_level")
+	oc.OnReject = c.getFieldString(tbl, "on_reject")
+
+	if node, ok := tbl.Fields["retry"]; ok {
+		subtbl, ok := node.(*ast.Table)
+		if !ok {
+			return nil, fmt.Errorf("invalid retry settings for output %s", name)
+		}
+		var retry retryConfig
+		if err := c.toml.UnmarshalTable(subtbl, &retry); err != nil {
+			return nil, fmt.Errorf("could not parse retry settings for output %s: %w", name, err)
+		}
+		oc.Retry = &models.RetryConfig{
+			InitialDelay: time.Duration(retry.InitialDelay),
+			MaxDelay:     time.Duration(retry.MaxDelay),
+			Multiplier:   retry.Multiplier,
+			Jitter:       retry.Jitter,
+			MaxAttempts:  retry.MaxAttempts,
+			DeadLetter:   retry.DeadLetter,
+		}
+	}
+
+	oc.Cardinality, err = c.buildCardinality("output "+name, tbl)
+	if err != nil {
+		return nil, err
+	}
 
 	if c.hasErrs() {
 		return nil, c.firstErr()
 	}
 
-	if oc.BufferStrategy == "disk" {
-		log.Printf("W! Using disk buffer strategy for plugin outputs.%s, this is an experimental feature", name)
+	oc.MemoryBuffer = c.MemoryBuffers
+	if !oc.MemoryBuffer && (oc.BufferStrategy == "disk" || oc.BufferStrategy == "hybrid") {
+		log.Printf("W! Using %s buffer strategy for plugin outputs.%s, this is an experimental feature", oc.BufferStrategy, name)
 	}
 
 	// Generate an ID for the plugin
@@ -1690,17 +2131,19 @@ func (c *Config) missingTomlField(_ reflect.Type, key string) error {
 	switch key {
 	// General options to ignore
 	case "alias", "always_include_local_tags",
-		"buffer_strategy", "buffer_directory",
-		"collection_jitter", "collection_offset",
+		"backoff", "buffer_strategy", "buffer_directory", "buffer_max_disk_size", "buffer_eviction_policy", "buffer_compression",
+		"cardinality", "collection_jitter", "collection_offset",
 		"data_format", "delay", "drop", "drop_original",
 		"fielddrop", "fieldexclude", "fieldinclude", "fieldpass", "flush_interval", "flush_jitter",
-		"grace",
+		"grace", "group",
 		"interval",
 		"log_level", "lvm", // What is this used for?
-		"metric_batch_size", "metric_buffer_limit", "metricpass",
+		"metric_batch_size", "metric_buffer_limit", "metric_transform", "metricpass",
 		"name_override", "name_prefix", "name_suffix", "namedrop", "namedrop_separator", "namepass", "namepass_separator",
-		"order",
+		"on_reject", "order",
 		"pass", "period", "precision",
+		"retry", "route",
+		"schedule", "schedule_timezone",
 		"tagdrop", "tagexclude", "taginclude", "tagpass", "tags", "startup_error_behavior":
 
 	// Secret-store options to ignore
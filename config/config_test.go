@@ -504,6 +504,255 @@ func TestConfig_BadOrdering(t *testing.T) {
 	)
 }
 
+func TestConfig_OutputRetry(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/output_retry.toml"))
+	require.Len(t, c.Outputs, 2)
+
+	expected := &models.RetryConfig{
+		InitialDelay: 2 * time.Second,
+		MaxDelay:     time.Minute,
+		Multiplier:   1.5,
+		Jitter:       0.2,
+		MaxAttempts:  10,
+		DeadLetter:   "outputs.fallback",
+	}
+	require.Equal(t, expected, c.Outputs[0].Config.Retry)
+	require.Nil(t, c.Outputs[1].Config.Retry)
+}
+
+func TestConfig_OutputOnReject(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/output_on_reject.toml"))
+	require.Len(t, c.Outputs, 1)
+	require.Equal(t, "/var/lib/telegraf/rejected.influx", c.Outputs[0].Config.OnReject)
+}
+
+func TestConfig_MemoryBuffers(t *testing.T) {
+	directory := t.TempDir()
+	cfg := fmt.Sprintf(`
+[agent]
+  buffer_strategy = "disk"
+  buffer_directory = %q
+
+[[outputs.http]]
+`, directory)
+
+	c := config.NewConfig()
+	c.MemoryBuffers = true
+	require.NoError(t, c.LoadConfigData([]byte(cfg), config.EmptySourcePath))
+	require.Len(t, c.Outputs, 1)
+	require.Equal(t, "disk", c.Outputs[0].Config.BufferStrategy)
+	require.Equal(t, directory, c.Outputs[0].Config.BufferDirectory)
+	require.True(t, c.Outputs[0].Config.MemoryBuffer)
+	require.NotEmpty(t, c.Outputs[0].ID())
+
+	// No buffer files must be created
+	entries, err := os.ReadDir(directory)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestConfig_OutputGroups(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadAll("./testdata/output_groups.toml"))
+	require.Len(t, c.Outputs, 2)
+	require.Equal(t, "outputs.http::standalone", c.Outputs[0].LogName())
+
+	ro := c.Outputs[1]
+	require.Equal(t, "outputs.group::influx", ro.LogName())
+	require.Equal(t, 500, ro.MetricBatchSize)
+	require.Equal(t, []string{"cpu"}, ro.Config.Filter.NamePass)
+
+	group, ok := ro.Output.(*models.OutputGroup)
+	require.True(t, ok)
+	require.Equal(t, models.GroupPolicyHash, group.Policy)
+	require.Equal(t, "host", group.HashTag)
+	require.Equal(t, time.Minute, group.RecoveryInterval)
+	require.Len(t, group.Members, 2)
+	require.Equal(t, "outputs.http::first", group.Members[0].LogName())
+	require.Equal(t, "outputs.http::second", group.Members[1].LogName())
+}
+
+func TestConfig_OutputGroupsInvalid(t *testing.T) {
+	c := config.NewConfig()
+	require.ErrorContains(t, c.LoadAll("./testdata/output_groups_invalid_member.toml"), `option "metric_buffer_limit" is not supported`)
+
+	c = config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte("[[outputs.http]]\n  group = \"missing\"\n"), ""))
+	require.ErrorContains(t, c.LoadAll(), `undefined output group "missing"`)
+}
+
+func TestConfig_Routes(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadAll("./testdata/routes.toml"))
+	require.Equal(t, map[string]config.RouteConfig{
+		"billing": {Processors: []string{"processor"}, Outputs: []string{"billing"}},
+	}, c.Routes)
+
+	require.Len(t, c.Inputs, 2)
+	routes := make([]string, 0, len(c.Inputs))
+	for _, input := range c.Inputs {
+		routes = append(routes, input.Config.Route)
+	}
+	require.ElementsMatch(t, []string{"billing", ""}, routes)
+
+	require.Len(t, c.Processors, 1)
+	require.Equal(t, []string{"billing"}, c.Processors[0].Config.Filter.Routes)
+
+	require.Len(t, c.Outputs, 2)
+	for _, output := range c.Outputs {
+		if output.Config.Alias == "billing" {
+			require.Equal(t, []string{"billing"}, output.Config.Filter.Routes)
+		} else {
+			require.Empty(t, output.Config.Filter.Routes)
+		}
+	}
+}
+
+func TestConfig_RoutesUndefined(t *testing.T) {
+	c := config.NewConfig()
+	require.ErrorContains(t, c.LoadAll("./testdata/routes_undefined.toml"), `route "billing" references undefined output "missing"`)
+
+	c = config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte("[[inputs.memcached]]\n  route = \"missing\"\n"), ""))
+	require.ErrorContains(t, c.LoadAll(), `references undefined route "missing"`)
+}
+
+func TestConfig_InputProcessors(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadAll("./testdata/input_processors.toml"))
+	require.Len(t, c.Processors, 1)
+	require.Len(t, c.Inputs, 2)
+	require.Empty(t, c.Inputs[1].Processors)
+
+	// Processors without order come first, the others sorted by order
+	chain := c.Inputs[0].Processors
+	require.Len(t, chain, 3)
+	require.Equal(t, "processor_parser", chain[0].Config.Name)
+	require.Equal(t, "processor", chain[1].Config.Name)
+	require.Equal(t, "processor", chain[2].Config.Name)
+	require.Equal(t, "first", chain[1].Processor.(processors.HasUnwrap).Unwrap().(*MockupProcessorPlugin).Option)
+	require.Equal(t, "second", chain[2].Processor.(processors.HasUnwrap).Unwrap().(*MockupProcessorPlugin).Option)
+
+	// Processors attached to inputs are distinct from the global ones
+	require.NotEqual(t, chain[1].ID(), chain[2].ID())
+	require.NotEqual(t, c.Processors[0].ID(), chain[1].ID())
+}
+
+func TestConfig_InputProcessorsUnusedField(t *testing.T) {
+	c := config.NewConfig()
+	require.ErrorContains(t, c.LoadConfig("./testdata/input_processors_unused.toml"), `configuration specified the fields ["foo"]`)
+}
+
+func TestConfig_InputProcessorsID(t *testing.T) {
+	c := config.NewConfig()
+	cfg := []byte(`
+[[inputs.memcached]]
+  servers = ["localhost"]
+
+  [[inputs.memcached.processors.processor]]
+    option = "foo"
+
+[[inputs.memcached]]
+  servers = ["otherhost"]
+
+  [[inputs.memcached.processors.processor]]
+    option = "foo"
+`)
+	require.NoError(t, c.LoadConfigData(cfg, ""))
+	require.Len(t, c.Inputs, 2)
+	require.Len(t, c.Inputs[0].Processors, 1)
+	require.Len(t, c.Inputs[1].Processors, 1)
+
+	// Identical processors of different inputs must be distinguishable
+	require.NotEqual(t, c.Inputs[0].Processors[0].ID(), c.Inputs[1].Processors[0].ID())
+}
+
+func TestConfig_ProcessorsOnlyForInputs(t *testing.T) {
+	c := config.NewConfig()
+	cfg := []byte(`
+[[outputs.azure_monitor]]
+  [[outputs.azure_monitor.processors.processor]]
+    option = "foo"
+`)
+	require.ErrorContains(t, c.LoadConfigData(cfg, ""), `configuration specified the fields ["processors"]`)
+}
+
+func TestConfig_InputSchedule(t *testing.T) {
+	c := config.NewConfig()
+	cfg := []byte(`
+[[inputs.memcached]]
+  schedule = "0 2 * * *"
+  schedule_timezone = "Europe/Berlin"
+
+[[inputs.memcached]]
+  servers = ["otherhost"]
+`)
+	require.NoError(t, c.LoadConfigData(cfg, ""))
+	require.Len(t, c.Inputs, 2)
+	require.NotNil(t, c.Inputs[0].Config.Schedule)
+	require.Nil(t, c.Inputs[1].Config.Schedule)
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	next := c.Inputs[0].Config.Schedule.Next(time.Date(2024, 6, 1, 12, 0, 0, 0, berlin))
+	require.True(t, next.Equal(time.Date(2024, 6, 2, 2, 0, 0, 0, berlin)))
+
+	c = config.NewConfig()
+	require.ErrorContains(t, c.LoadConfigData([]byte("[[inputs.memcached]]\n  schedule = \"0 25 * * *\"\n"), ""), "parsing schedule")
+
+	c = config.NewConfig()
+	require.ErrorContains(t, c.LoadConfigData([]byte("[[inputs.memcached]]\n  schedule_timezone = \"UTC\"\n"), ""), "requires a 'schedule'")
+
+	c = config.NewConfig()
+	require.ErrorContains(t, c.LoadConfigData([]byte("[[inputs.memcached]]\n  interval = \"1m\"\n  schedule = \"@daily\"\n"), ""), "cannot be combined")
+}
+
+func TestConfig_InputBackoff(t *testing.T) {
+	c := config.NewConfig()
+	cfg := []byte(`
+[[inputs.memcached]]
+  [inputs.memcached.backoff]
+    max_interval = "10m"
+    multiplier = 1.5
+
+[[inputs.memcached]]
+  servers = ["otherhost"]
+`)
+	require.NoError(t, c.LoadConfigData(cfg, ""))
+	require.Len(t, c.Inputs, 2)
+	require.Equal(t, &models.BackoffConfig{MaxInterval: 10 * time.Minute, Multiplier: 1.5}, c.Inputs[0].Config.Backoff)
+	require.Nil(t, c.Inputs[1].Config.Backoff)
+}
+
+func TestConfig_Cardinality(t *testing.T) {
+	c := config.NewConfig()
+	cfg := []byte(`
+[[inputs.memcached]]
+  [inputs.memcached.cardinality]
+    max_series = 1000
+    window = "10m"
+    action = "strip"
+    strip_tags = ["request_id"]
+
+[[outputs.azure_monitor]]
+  [outputs.azure_monitor.cardinality]
+    max_series = 5000
+`)
+	require.NoError(t, c.LoadConfigData(cfg, ""))
+	require.Len(t, c.Inputs, 1)
+	require.Equal(t, &models.CardinalityConfig{
+		MaxSeries: 1000,
+		Window:    10 * time.Minute,
+		Action:    models.CardinalityActionStrip,
+		StripTags: []string{"request_id"},
+	}, c.Inputs[0].Config.Cardinality)
+	require.Len(t, c.Outputs, 1)
+	require.Equal(t, &models.CardinalityConfig{MaxSeries: 5000}, c.Outputs[0].Config.Cardinality)
+	require.Empty(t, c.UnusedFields)
+}
+
 func TestConfig_AzureMonitorNamespacePrefix(t *testing.T) {
 	// #8256 Cannot use empty string as the namespace prefix
 	c := config.NewConfig()
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/influxdata/toml/ast"
+
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+// addInputProcessors sets up the processors attached to the given input via
+// `[[inputs.<input>.processors.<name>]]` tables. The processors are sorted by
+// their appearance and the `order` setting.
+func (c *Config) addInputProcessors(input, inputID, source string, table *ast.Table) (models.RunningProcessors, error) {
+	node, found := table.Fields["processors"]
+	if !found {
+		return nil, nil
+	}
+	subTable, ok := node.(*ast.Table)
+	if !ok {
+		return nil, fmt.Errorf("invalid processors setting for input %q", input)
+	}
+
+	plugins := make(OrderedPlugins, 0, len(subTable.Fields))
+	for name, val := range subTable.Fields {
+		tables, ok := val.([]*ast.Table)
+		if !ok {
+			return nil, fmt.Errorf("unsupported config format: %s", name)
+		}
+		for _, t := range tables {
+			processor, err := c.addInputProcessor(input, inputID, name, source, t)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing processor %s: %w", name, err)
+			}
+			plugins = append(plugins, &OrderedPlugin{t.Line, processor})
+		}
+	}
+
+	sort.Sort(plugins)
+	runningProcessors := make(models.RunningProcessors, 0, len(plugins))
+	for _, op := range plugins {
+		runningProcessors = append(runningProcessors, op.plugin.(*models.RunningProcessor))
+	}
+	sort.Stable(runningProcessors)
+
+	return runningProcessors, nil
+}
+
+func (c *Config) addInputProcessor(input, inputID, name, source string, table *ast.Table) (*models.RunningProcessor, error) {
+	creator, ok := processors.Processors[name]
+	if !ok {
+		// Handle removed, deprecated plugins
+		if di, deprecated := processors.Deprecations[name]; deprecated {
+			printHistoricPluginDeprecationNotice("processors", name, di)
+			return nil, errors.New("plugin deprecated")
+		}
+		return nil, fmt.Errorf("undefined but requested processor: %s", name)
+	}
+
+	missCount := make(map[string]int)
+	c.setLocalMissingTomlFieldTracker(missCount)
+	defer c.resetMissingTomlFieldTracker()
+
+	processorConfig, err := c.buildProcessor("inputs."+input+".processors", name, source, table)
+	if err != nil {
+		return nil, err
+	}
+
+	// Identical processors attached to different inputs must not share their
+	// ID, e.g. for persisting their states, so derive the ID from the input
+	processorConfig.ID, err = generatePluginID("inputs."+input+"."+inputID+".processors."+name, table)
+	if err != nil {
+		return nil, err
+	}
+	processor, count, err := c.setupProcessor(processorConfig.Name, creator, table)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the number of misses against the threshold
+	for key, n := range missCount {
+		if n <= count {
+			continue
+		}
+		if err := c.missingTomlField(nil, key); err != nil {
+			return nil, err
+		}
+	}
+
+	return models.NewRunningProcessor(processor, processorConfig), nil
+}
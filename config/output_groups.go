@@ -0,0 +1,140 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/influxdata/toml/ast"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+)
+
+// Options of outputs only supported on the output group for group members
+var groupOnlyOptions = []string{
+	"buffer_compression", "buffer_directory", "buffer_eviction_policy", "buffer_max_disk_size", "buffer_strategy",
+	"cardinality",
+	"fielddrop", "fieldexclude", "fieldinclude", "fieldpass", "flush_interval", "flush_jitter",
+	"metric_batch_size", "metric_buffer_limit", "metricpass",
+	"name_override", "name_prefix", "name_suffix", "namedrop", "namepass",
+	"on_reject", "retry",
+	"tagdrop", "tagexclude", "taginclude", "tagpass",
+}
+
+// outputGroupConfig contains the group specific settings of output groups.
+type outputGroupConfig struct {
+	Policy           string   `toml:"policy"`
+	HashTag          string   `toml:"hash_tag"`
+	RecoveryInterval Duration `toml:"recovery_interval"`
+}
+
+// outputGroup is an output group waiting for its members to be linked.
+type outputGroup struct {
+	group  *models.OutputGroup
+	config *models.OutputConfig
+}
+
+// groupMember is an output to be linked to the output group of the given name.
+type groupMember struct {
+	group  string
+	member *models.GroupMember
+}
+
+// addOutputGroup parses the settings of the output group with the given name.
+// Groups share the options of outputs, e.g. for the buffer and filtering.
+func (c *Config) addOutputGroup(name, source string, table *ast.Table) error {
+	if _, found := c.outputGroups[name]; found {
+		return fmt.Errorf("duplicate output group %q", name)
+	}
+
+	var cfg outputGroupConfig
+	if err := c.toml.UnmarshalTable(table, &cfg); err != nil {
+		return err
+	}
+
+	outputConfig, err := c.buildOutput("group", source, table)
+	if err != nil {
+		return err
+	}
+	outputConfig.Alias = name
+
+	if c.outputGroups == nil {
+		c.outputGroups = make(map[string]*outputGroup)
+	}
+	c.outputGroups[name] = &outputGroup{
+		group: &models.OutputGroup{
+			Name:             name,
+			Policy:           cfg.Policy,
+			HashTag:          cfg.HashTag,
+			RecoveryInterval: time.Duration(cfg.RecoveryInterval),
+		},
+		config: outputConfig,
+	}
+	return nil
+}
+
+// addGroupMember adds the output to the output group of the given name
+// once all configuration files are loaded.
+func (c *Config) addGroupMember(group string, output telegraf.Output, outputConfig *models.OutputConfig, table *ast.Table) error {
+	for _, option := range groupOnlyOptions {
+		if _, found := table.Fields[option]; found {
+			return fmt.Errorf("option %q is not supported for members of output groups, set it on group %q instead", option, group)
+		}
+	}
+
+	c.fileGroupMembers = append(c.fileGroupMembers, &OrderedPlugin{
+		Line: table.Line,
+		plugin: &groupMember{
+			group:  group,
+			member: models.NewGroupMember(output, outputConfig, group),
+		},
+	})
+	return nil
+}
+
+// linkOutputGroups assigns the members to their output groups in the order
+// of their appearance and adds the groups to the outputs. The ID of a group
+// covers its members so changing the members modifies the group.
+func (c *Config) linkOutputGroups() error {
+	for _, m := range c.groupMembers {
+		g, found := c.outputGroups[m.group]
+		if !found {
+			return fmt.Errorf("output %s references undefined output group %q", m.member.LogName(), m.group)
+		}
+		g.group.Members = append(g.group.Members, m.member)
+	}
+
+	names := make([]string, 0, len(c.outputGroups))
+	for name := range c.outputGroups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		g := c.outputGroups[name]
+		ids := make([]string, 0, len(g.group.Members)+1)
+		ids = append(ids, g.config.ID)
+		for _, m := range g.group.Members {
+			ids = append(ids, m.ID())
+		}
+		g.config.ID = groupID(ids)
+
+		ro := models.NewRunningOutput(g.group, g.config, c.Agent.MetricBatchSize, c.Agent.MetricBufferLimit)
+		c.Outputs = append(c.Outputs, ro)
+	}
+	return nil
+}
+
+// groupID returns the ID of an output group from the IDs of the group
+// settings and its members.
+func groupID(ids []string) string {
+	hash := sha256.New()
+	for _, id := range ids {
+		hash.Write([]byte(id))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/influxdata/toml/ast"
+
+	"github.com/influxdata/telegraf/models"
+)
+
+// RouteConfig lists the plugins receiving the metrics of a route by their
+// alias or, for plugins without alias, by their name.
+type RouteConfig struct {
+	Processors  []string `toml:"processors"`
+	Aggregators []string `toml:"aggregators"`
+	Outputs     []string `toml:"outputs"`
+}
+
+// addRoute parses the route with the given name.
+func (c *Config) addRoute(name string, table *ast.Table) error {
+	if _, found := c.Routes[name]; found {
+		return fmt.Errorf("duplicate route %q", name)
+	}
+
+	var route RouteConfig
+	if err := c.toml.UnmarshalTable(table, &route); err != nil {
+		return err
+	}
+
+	if c.Routes == nil {
+		c.Routes = make(map[string]RouteConfig)
+	}
+	c.Routes[name] = route
+	return nil
+}
+
+// linkRoutes restricts the plugins referenced by routes to the metrics of
+// those routes and verifies the routes referenced by inputs.
+func (c *Config) linkRoutes() error {
+	for _, input := range c.Inputs {
+		if _, found := c.Routes[input.Config.Route]; input.Config.Route != "" && !found {
+			return fmt.Errorf("input %s references undefined route %q", input.LogName(), input.Config.Route)
+		}
+	}
+
+	names := make([]string, 0, len(c.Routes))
+	for name := range c.Routes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		route := c.Routes[name]
+		for _, key := range route.Processors {
+			var filters []*models.Filter
+			for _, p := range slices.Concat(c.Processors, c.AggProcessors) {
+				filters = appendRouteFilter(filters, key, p.Config.Name, p.Config.Alias, &p.Config.Filter)
+			}
+			if err := addRouteFilters(filters, name, "processor", key); err != nil {
+				return err
+			}
+		}
+		for _, key := range route.Aggregators {
+			var filters []*models.Filter
+			for _, a := range c.Aggregators {
+				filters = appendRouteFilter(filters, key, a.Config.Name, a.Config.Alias, &a.Config.Filter)
+			}
+			if err := addRouteFilters(filters, name, "aggregator", key); err != nil {
+				return err
+			}
+		}
+		for _, key := range route.Outputs {
+			var filters []*models.Filter
+			for _, o := range c.Outputs {
+				filters = appendRouteFilter(filters, key, o.Config.Name, o.Config.Alias, &o.Config.Filter)
+			}
+			if err := addRouteFilters(filters, name, "output", key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// appendRouteFilter appends the filter of the plugin if the given key matches
+// the alias of the plugin or, for plugins without alias, the plugin name.
+func appendRouteFilter(filters []*models.Filter, key, name, alias string, filter *models.Filter) []*models.Filter {
+	switch key {
+	case alias:
+		return append(filters, filter)
+	case name:
+		if alias == "" {
+			return append(filters, filter)
+		}
+	}
+	return filters
+}
+
+// addRouteFilters adds the route to the given filters of the plugins
+// referenced by the key.
+func addRouteFilters(filters []*models.Filter, route, kind, key string) error {
+	if len(filters) == 0 {
+		return fmt.Errorf("route %q references undefined %s %q", route, kind, key)
+	}
+	for _, f := range filters {
+		f.Routes = append(f.Routes, route)
+	}
+	return nil
+}
@@ -0,0 +1,674 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/toml/ast"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	durationType        = reflect.TypeOf(Duration(0))
+	sizeType            = reflect.TypeOf(Size(0))
+	secretType          = reflect.TypeOf(Secret{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// filterOptions lists the metric filtering options of all plugins for the
+// schema, the options are parsed by buildFilter.
+type filterOptions struct {
+	NamePass          []string            `toml:"namepass"`
+	NamePassSeparator string              `toml:"namepass_separator"`
+	NameDrop          []string            `toml:"namedrop"`
+	NameDropSeparator string              `toml:"namedrop_separator"`
+	FieldPass         []string            `toml:"fieldpass"`
+	FieldInclude      []string            `toml:"fieldinclude"`
+	FieldDrop         []string            `toml:"fielddrop"`
+	FieldExclude      []string            `toml:"fieldexclude"`
+	TagPass           map[string][]string `toml:"tagpass"`
+	TagDrop           map[string][]string `toml:"tagdrop"`
+	TagInclude        []string            `toml:"taginclude"`
+	TagExclude        []string            `toml:"tagexclude"`
+	MetricPass        string              `toml:"metricpass"`
+	MetricTransform   []string            `toml:"metric_transform"`
+}
+
+// inputOptions lists the options common to all inputs for the schema, the
+// options are parsed by buildInput.
+type inputOptions struct {
+	filterOptions
+	Alias                string             `toml:"alias"`
+	Interval             Duration           `toml:"interval"`
+	Precision            Duration           `toml:"precision"`
+	CollectionJitter     Duration           `toml:"collection_jitter"`
+	CollectionOffset     Duration           `toml:"collection_offset"`
+	Schedule             string             `toml:"schedule"`
+	ScheduleTimezone     string             `toml:"schedule_timezone"`
+	StartupErrorBehavior string             `toml:"startup_error_behavior" enum:"error,retry,ignore,probe"`
+	TimeSource           string             `toml:"time_source" enum:"metric,collection_start,collection_end"`
+	Route                string             `toml:"route"`
+	NameOverride         string             `toml:"name_override"`
+	NamePrefix           string             `toml:"name_prefix"`
+	NameSuffix           string             `toml:"name_suffix"`
+	LogLevel             string             `toml:"log_level" enum:"error,warn,info,debug,trace,ERROR,WARN,INFO,DEBUG,TRACE"`
+	Tags                 map[string]string  `toml:"tags"`
+	Backoff              *backoffConfig     `toml:"backoff"`
+	Cardinality          *cardinalityConfig `toml:"cardinality"`
+}
+
+// outputOptions lists the options common to all outputs for the schema, the
+// options are parsed by buildOutput.
+type outputOptions struct {
+	filterOptions
+	Alias                string             `toml:"alias"`
+	FlushInterval        Duration           `toml:"flush_interval"`
+	FlushJitter          Duration           `toml:"flush_jitter"`
+	MetricBatchSize      int                `toml:"metric_batch_size" minimum:"0"`
+	MetricBufferLimit    int                `toml:"metric_buffer_limit" minimum:"0"`
+	StartupErrorBehavior string             `toml:"startup_error_behavior" enum:"error,retry,ignore"`
+	NameOverride         string             `toml:"name_override"`
+	NamePrefix           string             `toml:"name_prefix"`
+	NameSuffix           string             `toml:"name_suffix"`
+	LogLevel             string             `toml:"log_level" enum:"error,warn,info,debug,trace,ERROR,WARN,INFO,DEBUG,TRACE"`
+	OnReject             string             `toml:"on_reject"`
+	Group                string             `toml:"group"`
+	Retry                *retryConfig       `toml:"retry"`
+	Cardinality          *cardinalityConfig `toml:"cardinality"`
+}
+
+// processorOptions lists the options common to all processors for the
+// schema, the options are parsed by buildProcessor.
+type processorOptions struct {
+	filterOptions
+	Alias    string `toml:"alias"`
+	Order    int64  `toml:"order"`
+	LogLevel string `toml:"log_level" enum:"error,warn,info,debug,trace,ERROR,WARN,INFO,DEBUG,TRACE"`
+}
+
+// aggregatorOptions lists the options common to all aggregators for the
+// schema, the options are parsed by buildAggregator.
+type aggregatorOptions struct {
+	filterOptions
+	Alias        string            `toml:"alias"`
+	Period       Duration          `toml:"period"`
+	Delay        Duration          `toml:"delay"`
+	Grace        Duration          `toml:"grace"`
+	DropOriginal bool              `toml:"drop_original"`
+	NameOverride string            `toml:"name_override"`
+	NamePrefix   string            `toml:"name_prefix"`
+	NameSuffix   string            `toml:"name_suffix"`
+	LogLevel     string            `toml:"log_level" enum:"error,warn,info,debug,trace,ERROR,WARN,INFO,DEBUG,TRACE"`
+	Tags         map[string]string `toml:"tags"`
+}
+
+// secretStoreOptions lists the options common to all secret-stores for the
+// schema, the options are parsed by addSecretStore.
+type secretStoreOptions struct {
+	ID string `toml:"id"`
+}
+
+var generalOptions = map[string]reflect.Type{
+	"inputs":       reflect.TypeOf(inputOptions{}),
+	"outputs":      reflect.TypeOf(outputOptions{}),
+	"processors":   reflect.TypeOf(processorOptions{}),
+	"aggregators":  reflect.TypeOf(aggregatorOptions{}),
+	"secretstores": reflect.TypeOf(secretStoreOptions{}),
+}
+
+// PluginSchemas returns a JSON schema document containing the schemas of the
+// options of all plugins as definitions named "<category>.<name>". The
+// plugins can be limited to the given categories like "inputs" or plugins
+// like "inputs.cpu".
+func PluginSchemas(filters ...string) (map[string]interface{}, error) {
+	matches := func(category, name string) bool {
+		if len(filters) == 0 {
+			return true
+		}
+		for _, f := range filters {
+			if f == category || f == category+"."+name {
+				return true
+			}
+		}
+		return false
+	}
+
+	definitions := make(map[string]interface{})
+	var errs []error
+	add := func(category, name string, create func() interface{}) {
+		if !matches(category, name) {
+			return
+		}
+		schema, err := pluginSchema(category, name, create())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("creating schema of %s.%s failed: %w", category, name, err))
+			return
+		}
+		definitions[category+"."+name] = schema
+	}
+	for name, creator := range inputs.Inputs {
+		add("inputs", name, func() interface{} { return creator() })
+	}
+	for name, creator := range outputs.Outputs {
+		add("outputs", name, func() interface{} { return creator() })
+	}
+	for name, creator := range processors.Processors {
+		add("processors", name, func() interface{} {
+			processor := creator()
+			if p, ok := processor.(processors.HasUnwrap); ok {
+				return p.Unwrap()
+			}
+			return processor
+		})
+	}
+	for name, creator := range aggregators.Aggregators {
+		add("aggregators", name, func() interface{} { return creator() })
+	}
+	for name, creator := range secretstores.SecretStores {
+		add("secretstores", name, func() interface{} { return creator("") })
+	}
+	for name, creator := range parsers.Parsers {
+		add("parsers", name, func() interface{} { return creator("") })
+	}
+	for name, creator := range serializers.Serializers {
+		add("serializers", name, func() interface{} { return creator() })
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if len(definitions) == 0 {
+		return nil, fmt.Errorf("no plugins matching %q", strings.Join(filters, ","))
+	}
+
+	return map[string]interface{}{
+		"$schema": schemaDraft,
+		"title":   "Telegraf plugin options",
+		"$defs":   definitions,
+	}, nil
+}
+
+// pluginSchema returns the JSON schema of the options of the given plugin
+// instance including the options common to all plugins of the category. The
+// defaults are taken from the options set by the plugin's creator.
+func pluginSchema(category, name string, plugin interface{}) (map[string]interface{}, error) {
+	schema, err := typeSchema(reflect.TypeOf(plugin), reflect.ValueOf(plugin), make(map[reflect.Type]bool))
+	if err != nil {
+		return nil, err
+	}
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		properties = make(map[string]interface{})
+	}
+
+	// Add the general options not overridden by the plugin
+	if t, found := generalOptions[category]; found {
+		general, err := structSchema(t, reflect.Value{}, make(map[reflect.Type]bool))
+		if err != nil {
+			return nil, err
+		}
+		for key, option := range general["properties"].(map[string]interface{}) {
+			if _, exists := properties[key]; !exists {
+				properties[key] = option
+			}
+		}
+	}
+
+	// Plugins accepting arbitrary data-formats select their parser or
+	// serializer via the data-format
+	switch plugin.(type) {
+	case telegraf.ParserPlugin, telegraf.ParserFuncPlugin:
+		properties["data_format"] = map[string]interface{}{
+			"type": "string",
+			"enum": sortedKeys(parsers.Parsers),
+		}
+	case telegraf.SerializerPlugin, telegraf.SerializerFuncPlugin:
+		properties["data_format"] = map[string]interface{}{
+			"type": "string",
+			"enum": sortedKeys(serializers.Serializers),
+		}
+	}
+
+	schema = map[string]interface{}{
+		"title":      category + "." + name,
+		"type":       "object",
+		"properties": properties,
+	}
+	if info, deprecated := pluginDeprecations(category)[name]; deprecated {
+		schema["deprecated"] = true
+		schema["x-deprecated"] = deprecationSchema(info.Since, info.RemovalIn, info.Notice)
+	}
+	return schema, nil
+}
+
+func pluginDeprecations(category string) map[string]telegraf.DeprecationInfo {
+	switch category {
+	case "inputs":
+		return inputs.Deprecations
+	case "outputs":
+		return outputs.Deprecations
+	case "processors":
+		return processors.Deprecations
+	case "aggregators":
+		return aggregators.Deprecations
+	case "secretstores":
+		return secretstores.Deprecations
+	case "parsers":
+		return parsers.Deprecations
+	case "serializers":
+		return serializers.Deprecations
+	}
+	return nil
+}
+
+func deprecationSchema(since, removal, notice string) map[string]interface{} {
+	info := map[string]interface{}{"since": since}
+	if removal != "" {
+		info["removal_in"] = removal
+	}
+	if notice != "" {
+		info["notice"] = notice
+	}
+	return info
+}
+
+// typeSchema returns the JSON schema for values of the given type. The value
+// is optional and used to determine the defaults of nested structures.
+func typeSchema(t reflect.Type, v reflect.Value, seen map[reflect.Type]bool) (map[string]interface{}, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		if v.IsValid() && !v.IsNil() {
+			v = v.Elem()
+		} else {
+			v = reflect.Value{}
+		}
+	}
+
+	switch t {
+	case durationType:
+		return map[string]interface{}{"type": []string{"string", "number"}}, nil
+	case sizeType:
+		return map[string]interface{}{"type": []string{"string", "integer"}}, nil
+	case secretType:
+		return map[string]interface{}{"type": "string"}, nil
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		// The plugin decides about the accepted values
+		return make(map[string]interface{}), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Array, reflect.Slice:
+		items, err := typeSchema(t.Elem(), reflect.Value{}, seen)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"type":  "array",
+			"items": items,
+		}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			break
+		}
+		values, err := typeSchema(t.Elem(), reflect.Value{}, seen)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": values,
+		}, nil
+	case reflect.Struct:
+		return structSchema(t, v, seen)
+	}
+
+	// Interfaces and other types are not restricted
+	return make(map[string]interface{}), nil
+}
+
+func structSchema(t reflect.Type, v reflect.Value, seen map[reflect.Type]bool) (map[string]interface{}, error) {
+	// Do not descend into recursive structures
+	if seen[t] {
+		return map[string]interface{}{"type": "object"}, nil
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	properties := make(map[string]interface{})
+	if err := addStructProperties(properties, t, v, seen); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}, nil
+}
+
+func addStructProperties(properties map[string]interface{}, t reflect.Type, v reflect.Value, seen map[reflect.Type]bool) error {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		var fieldValue reflect.Value
+		if v.IsValid() {
+			fieldValue = v.Field(i)
+		}
+
+		key, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+
+		// The options of embedded structures are options of the plugin
+		if field.Anonymous && key == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+				if fieldValue.IsValid() && !fieldValue.IsNil() {
+					fieldValue = fieldValue.Elem()
+				} else {
+					fieldValue = reflect.Value{}
+				}
+			}
+			if ft.Kind() == reflect.Struct && ft != secretType {
+				if err := addStructProperties(properties, ft, fieldValue, seen); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if !field.IsExported() || key == "" || key == "-" {
+			continue
+		}
+
+		schema, err := typeSchema(field.Type, fieldValue, seen)
+		if err != nil {
+			return err
+		}
+		if err := annotateSchema(schema, field, fieldValue); err != nil {
+			return err
+		}
+		properties[key] = schema
+	}
+	return nil
+}
+
+// annotateSchema adds the restrictions given by the `enum`, `minimum` and
+// `maximum` tags, the deprecation given by the `deprecated` tag and the
+// default value to the schema of the field.
+func annotateSchema(schema map[string]interface{}, field reflect.StructField, value reflect.Value) error {
+	// Restrictions apply to the elements of lists
+	target := schema
+	elem := field.Type
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		target = items
+		elem = elem.Elem()
+	}
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+
+	if tag := field.Tag.Get("enum"); tag != "" {
+		values := strings.Split(tag, ",")
+		enum := make([]interface{}, 0, len(values))
+		for _, raw := range values {
+			v, err := tagValue(field, elem, raw)
+			if err != nil {
+				return err
+			}
+			enum = append(enum, v)
+		}
+		target["enum"] = enum
+	}
+	if tag := field.Tag.Get("minimum"); tag != "" {
+		v, err := tagValue(field, elem, tag)
+		if err != nil {
+			return err
+		}
+		target["minimum"] = v
+	}
+	if tag := field.Tag.Get("maximum"); tag != "" {
+		v, err := tagValue(field, elem, tag)
+		if err != nil {
+			return err
+		}
+		target["maximum"] = v
+	}
+
+	if tag := field.Tag.Get("deprecated"); tag != "" {
+		parts := strings.SplitN(tag, ";", 3)
+		var removal, notice string
+		if len(parts) > 1 {
+			notice = parts[len(parts)-1]
+		}
+		if len(parts) > 2 {
+			removal = parts[1]
+		}
+		schema["deprecated"] = true
+		schema["x-deprecated"] = deprecationSchema(parts[0], removal, notice)
+	}
+
+	if d, found := defaultValue(value); found {
+		schema["default"] = d
+	}
+	return nil
+}
+
+// tagValue converts the value of a schema tag to the kind of the field.
+func tagValue(field reflect.StructField, t reflect.Type, raw string) (interface{}, error) {
+	var v interface{}
+	var err error
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err = strconv.ParseInt(raw, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		v, err = strconv.ParseFloat(raw, 64)
+	default:
+		v = raw
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema tag value %q for option %q: %w", raw, field.Name, err)
+	}
+	return v, nil
+}
+
+// defaultValue returns the value of the option if it is set and can be
+// represented in the schema.
+func defaultValue(v reflect.Value) (interface{}, bool) {
+	if !v.IsValid() || v.IsZero() {
+		return nil, false
+	}
+
+	switch v.Type() {
+	case durationType:
+		return time.Duration(v.Int()).String(), true
+	case secretType:
+		return nil, false
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String:
+		return v.String(), true
+	case reflect.Array, reflect.Slice, reflect.Map:
+		switch v.Type().Elem().Kind() {
+		case reflect.Bool, reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return v.Interface(), true
+		}
+	}
+	return nil, false
+}
+
+func sortedKeys[M ~map[string]V, V any](m M) []string {
+	names := make([]string, 0, len(m))
+	for k := range m {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateOptions checks the options of the given plugin instance against the
+// schema of the plugin if schema validation is enabled. The plugin must not
+// be configured yet to determine the correct defaults.
+func (c *Config) validateOptions(category, name string, plugin interface{}, table *ast.Table) error {
+	if !c.ValidateSchema {
+		return nil
+	}
+
+	id := category + "." + name
+	schema, found := c.schemas[id]
+	if !found {
+		definition, err := pluginSchema(category, name, plugin)
+		if err != nil {
+			return fmt.Errorf("creating schema failed: %w", err)
+		}
+		schema, err = compileSchema(id, definition)
+		if err != nil {
+			return fmt.Errorf("compiling schema failed: %w", err)
+		}
+		if c.schemas == nil {
+			c.schemas = make(map[string]*jsonschema.Schema)
+		}
+		c.schemas[id] = schema
+	}
+
+	value, err := astValue(table)
+	if err != nil {
+		return err
+	}
+	if err := schema.Validate(value); err != nil {
+		var verr *jsonschema.ValidationError
+		if !errors.As(err, &verr) {
+			return err
+		}
+		return fmt.Errorf("invalid options: %w", errors.Join(validationErrors(verr)...))
+	}
+	return nil
+}
+
+func compileSchema(id string, schema map[string]interface{}) (*jsonschema.Schema, error) {
+	buf, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	if err := compiler.AddResource(id+".json", bytes.NewReader(buf)); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(id + ".json")
+}
+
+// validationErrors returns the errors of the options causing the validation
+// error.
+func validationErrors(verr *jsonschema.ValidationError) []error {
+	if len(verr.Causes) == 0 {
+		option := strings.ReplaceAll(strings.TrimPrefix(verr.InstanceLocation, "/"), "/", ".")
+		return []error{fmt.Errorf("option %q: %s", option, verr.Message)}
+	}
+
+	errs := make([]error, 0, len(verr.Causes))
+	for _, cause := range verr.Causes {
+		errs = append(errs, validationErrors(cause)...)
+	}
+	return errs
+}
+
+// astValue converts the TOML node to the values produced by decoding JSON.
+func astValue(node interface{}) (interface{}, error) {
+	switch n := node.(type) {
+	case *ast.Table:
+		m := make(map[string]interface{}, len(n.Fields))
+		for key, field := range n.Fields {
+			v, err := astValue(field)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+		}
+		return m, nil
+	case []*ast.Table:
+		s := make([]interface{}, 0, len(n))
+		for _, t := range n {
+			v, err := astValue(t)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, v)
+		}
+		return s, nil
+	case *ast.KeyValue:
+		v, err := astValue(n.Value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n.Line, err)
+		}
+		return v, nil
+	case *ast.Array:
+		s := make([]interface{}, 0, len(n.Value))
+		for _, element := range n.Value {
+			v, err := astValue(element)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, v)
+		}
+		return s, nil
+	case *ast.String:
+		return n.Value, nil
+	case *ast.Integer:
+		i, err := n.Int()
+		if err != nil {
+			return nil, err
+		}
+		return json.Number(strconv.FormatInt(i, 10)), nil
+	case *ast.Float:
+		f, err := n.Float()
+		if err != nil {
+			return nil, err
+		}
+		return json.Number(strconv.FormatFloat(f, 'g', -1, 64)), nil
+	case *ast.Boolean:
+		return n.Boolean()
+	case *ast.Datetime:
+		return n.Source(), nil
+	}
+	return nil, fmt.Errorf("unsupported value type %T", node)
+}
@@ -0,0 +1,220 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+// Mockup INPUT plugin using the schema tags
+type MockupSchemaPlugin struct {
+	Mode    string          `toml:"mode" enum:"fast,slow"`
+	Port    int             `toml:"port" minimum:"1" maximum:"65535"`
+	Ratios  []float64       `toml:"ratios" minimum:"0" maximum:"1"`
+	Timeout config.Duration `toml:"timeout"`
+	Old     bool            `toml:"old" deprecated:"1.30.0;1.40.0;use 'mode' instead"`
+	Log     telegraf.Logger `toml:"-"`
+}
+
+func (*MockupSchemaPlugin) SampleConfig() string {
+	return "Mockup test input plugin"
+}
+func (*MockupSchemaPlugin) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func init() {
+	inputs.Add("schema_test", func() telegraf.Input {
+		return &MockupSchemaPlugin{Mode: "fast", Timeout: config.Duration(5 * time.Second)}
+	})
+}
+
+// Mockup PROCESSOR plugin with an invalid schema tag
+type MockupInvalidSchemaPlugin struct {
+	Port int `toml:"port" maximum:"many"`
+}
+
+func (*MockupInvalidSchemaPlugin) SampleConfig() string {
+	return "Mockup test processor plugin"
+}
+
+func (*MockupInvalidSchemaPlugin) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
+	return metrics
+}
+
+func init() {
+	processors.Add("schema_invalid_test", func() telegraf.Processor {
+		return &MockupInvalidSchemaPlugin{}
+	})
+}
+
+func TestPluginSchemas(t *testing.T) {
+	schema, err := config.PluginSchemas("inputs.schema_test")
+	require.NoError(t, err)
+
+	definitions := schema["$defs"].(map[string]interface{})
+	require.Len(t, definitions, 1)
+	plugin := definitions["inputs.schema_test"].(map[string]interface{})
+	require.Equal(t, "object", plugin["type"])
+	properties := plugin["properties"].(map[string]interface{})
+
+	require.Equal(t, map[string]interface{}{
+		"type":    "string",
+		"enum":    []interface{}{"fast", "slow"},
+		"default": "fast",
+	}, properties["mode"])
+	require.Equal(t, map[string]interface{}{
+		"type":    "integer",
+		"minimum": int64(1),
+		"maximum": int64(65535),
+	}, properties["port"])
+	require.Equal(t, map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type":    "number",
+			"minimum": float64(0),
+			"maximum": float64(1),
+		},
+	}, properties["ratios"])
+	require.Equal(t, map[string]interface{}{
+		"type":    []string{"string", "number"},
+		"default": "5s",
+	}, properties["timeout"])
+	require.Equal(t, map[string]interface{}{
+		"type":       "boolean",
+		"deprecated": true,
+		"x-deprecated": map[string]interface{}{
+			"since":      "1.30.0",
+			"removal_in": "1.40.0",
+			"notice":     "use 'mode' instead",
+		},
+	}, properties["old"])
+	require.NotContains(t, properties, "log")
+
+	// General options of inputs
+	require.Contains(t, properties, "interval")
+	require.Contains(t, properties, "namepass")
+	require.Contains(t, properties["cardinality"].(map[string]interface{})["properties"], "max_series")
+}
+
+func TestPluginSchemasCategory(t *testing.T) {
+	schema, err := config.PluginSchemas("inputs")
+	require.NoError(t, err)
+
+	definitions := schema["$defs"].(map[string]interface{})
+	require.Contains(t, definitions, "inputs.schema_test")
+	require.Contains(t, definitions, "inputs.memcached")
+	require.NotContains(t, definitions, "outputs.azure_monitor")
+
+	// Inputs with parsers accept the parser data-formats
+	plugin := definitions["inputs.parser"].(map[string]interface{})
+	require.Contains(t, plugin["properties"], "data_format")
+}
+
+func TestPluginSchemasInvalidTag(t *testing.T) {
+	_, err := config.PluginSchemas("processors.schema_invalid_test")
+	require.ErrorContains(t, err, `invalid schema tag value "many" for option "Port"`)
+}
+
+func TestPluginSchemasUnknown(t *testing.T) {
+	_, err := config.PluginSchemas("inputs.does_not_exist")
+	require.ErrorContains(t, err, "no plugins matching")
+}
+
+func TestConfig_ValidateSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      string
+		expected string
+	}{
+		{
+			name: "valid",
+			cfg: `
+[[inputs.schema_test]]
+  mode = "slow"
+  port = 8080
+  ratios = [0.5, 1.0]
+  timeout = "10s"
+  log_level = "debug"
+`,
+		},
+		{
+			name: "invalid enum",
+			cfg: `
+[[inputs.schema_test]]
+  mode = "medium"
+`,
+			expected: `option "mode"`,
+		},
+		{
+			name: "out of range",
+			cfg: `
+[[inputs.schema_test]]
+  port = 70000
+`,
+			expected: `option "port"`,
+		},
+		{
+			name: "out of range in list",
+			cfg: `
+[[inputs.schema_test]]
+  ratios = [0.5, 2.0]
+`,
+			expected: `option "ratios.1"`,
+		},
+		{
+			name: "invalid type",
+			cfg: `
+[[inputs.schema_test]]
+  port = "http"
+`,
+			expected: `option "port"`,
+		},
+		{
+			name: "invalid general option",
+			cfg: `
+[[inputs.schema_test]]
+  log_level = "verbose"
+`,
+			expected: `option "log_level"`,
+		},
+		{
+			name: "invalid subtable option",
+			cfg: `
+[[inputs.schema_test]]
+  [inputs.schema_test.cardinality]
+    max_series = 0
+`,
+			expected: `option "cardinality.max_series"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := config.NewConfig()
+			c.ValidateSchema = true
+			err := c.LoadConfigData([]byte(tt.cfg), config.EmptySourcePath)
+			if tt.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, "invalid options")
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestConfig_ValidateSchemaDisabled(t *testing.T) {
+	c := config.NewConfig()
+	cfg := []byte(`
+[[inputs.schema_test]]
+  mode = "medium"
+`)
+	require.NoError(t, c.LoadConfigData(cfg, config.EmptySourcePath))
+}
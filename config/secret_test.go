@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"testing"
+	"time"
 
 	"github.com/awnumar/memguard"
 	"github.com/stretchr/testify/require"
@@ -417,6 +418,31 @@ func TestSecretStoreStatic(t *testing.T) {
 	}
 }
 
+func TestSecretStoreReuse(t *testing.T) {
+	running := NewConfig()
+	require.NoError(t, running.LoadConfigData([]byte(`
+[[secretstores.mockup]]
+  id = "mock"
+[[secretstores.mockup]]
+  id = "other"
+`), EmptySourcePath))
+
+	// Only secret-stores with unchanged settings must be reused
+	c := NewConfig()
+	c.ReuseSecretStores(running)
+	require.NoError(t, c.LoadConfigData([]byte(`
+[[secretstores.mockup]]
+  id = "mock"
+[[secretstores.mockup]]
+  id = "other"
+  dynamic = true
+`), EmptySourcePath))
+	require.Same(t, running.SecretStores["mock"], c.SecretStores["mock"])
+	require.NotSame(t, running.SecretStores["other"], c.SecretStores["other"])
+	require.Equal(t, running.SecretStoreIDs()["mock"], c.SecretStoreIDs()["mock"])
+	require.NotEqual(t, running.SecretStoreIDs()["other"], c.SecretStoreIDs()["other"])
+}
+
 func TestSecretStoreInvalidKeys(t *testing.T) {
 	cfg := []byte(
 		`
@@ -653,6 +679,121 @@ func (tsuite *SecretImplTestSuite) TestSecretStoreDynamic() {
 	}
 }
 
+func (tsuite *SecretImplTestSuite) TestSecretStoreChangeNotification() {
+	t := tsuite.T()
+
+	cfg := []byte(
+		`
+[[inputs.mockup_handler]]
+	secret = "@{mock:secret}"
+[[inputs.mockup_handler]]
+	secret = "@{static:secret}"
+[[inputs.mockup]]
+	secret = "@{mock:secret}"
+`)
+
+	c := NewConfig()
+	require.NoError(t, c.LoadConfigData(cfg, EmptySourcePath))
+	require.Len(t, c.Inputs, 3)
+
+	// Create a dynamic and a static mockup secretstore
+	store := &MockupSecretStore{
+		Secrets: map[string][]byte{"secret": []byte("Ood Bnar"), "other": []byte("Thon")},
+		Dynamic: true,
+	}
+	require.NoError(t, store.Init())
+	c.SecretStores["mock"] = store
+	static := &MockupSecretStore{
+		Secrets: map[string][]byte{"secret": []byte("Obi-Wan Kenobi")},
+	}
+	require.NoError(t, static.Init())
+	c.SecretStores["static"] = static
+	require.NoError(t, c.LinkSecrets())
+	require.NotNil(t, store.callback)
+	require.NotNil(t, static.callback)
+
+	// Only the plugin referencing the changed secret should be notified
+	dynamicPlugin := c.Inputs[0].Input.(*MockupSecretHandlerPlugin)
+	staticPlugin := c.Inputs[1].Input.(*MockupSecretHandlerPlugin)
+	store.callback("other")
+	store.callback("secret")
+	select {
+	case ref := <-dynamicPlugin.changed:
+		require.Equal(t, "@{mock:secret}", ref)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "no notification received")
+	}
+	require.Empty(t, dynamicPlugin.changed)
+
+	// Static secrets are already resolved so there is no notification
+	static.callback("secret")
+	require.Never(t, func() bool {
+		return len(staticPlugin.changed) > 0
+	}, 100*time.Millisecond, 10*time.Millisecond)
+}
+
+func (tsuite *SecretImplTestSuite) TestSecretStoreChangeNotificationReload() {
+	t := tsuite.T()
+
+	store := &MockupSecretStore{
+		Secrets: map[string][]byte{"secret": []byte("Ood Bnar"), "other": []byte("Thon")},
+		Dynamic: true,
+	}
+	require.NoError(t, store.Init())
+
+	running := NewConfig()
+	require.NoError(t, running.LoadConfigData([]byte(`
+[[inputs.mockup_handler]]
+	secret = "@{mock:secret}"
+[[inputs.mockup_handler]]
+	secret = "@{mock:other}"
+`), EmptySourcePath))
+	running.SecretStores["mock"] = store
+	require.NoError(t, running.LinkSecrets())
+
+	// Linking a reload configuration reusing the store must not redirect
+	// the notifications to its plugins
+	c := NewConfig()
+	c.ReuseSecretStores(running)
+	require.NoError(t, c.LoadConfigData([]byte(`
+[[inputs.mockup_handler]]
+	secret = "@{mock:secret}"
+[[inputs.mockup_handler]]
+	secret = "@{mock:other}"
+`), EmptySourcePath))
+	c.SecretStores["mock"] = store
+	require.NoError(t, c.LinkSecrets())
+
+	kept := running.Inputs[0].Input.(*MockupSecretHandlerPlugin)
+	removed := running.Inputs[1].Input.(*MockupSecretHandlerPlugin)
+	duplicate := c.Inputs[0].Input.(*MockupSecretHandlerPlugin)
+	added := c.Inputs[1].Input.(*MockupSecretHandlerPlugin)
+
+	store.callback("secret")
+	select {
+	case ref := <-kept.changed:
+		require.Equal(t, "@{mock:secret}", ref)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "no notification received")
+	}
+	require.Empty(t, duplicate.changed)
+
+	// After applying the reload only the running plugins are notified
+	running.UpdateSecretChangeHandlers(c, func(plugin any) bool {
+		return plugin == kept || plugin == added
+	})
+	store.callback("other")
+	select {
+	case ref := <-added.changed:
+		require.Equal(t, "@{mock:other}", ref)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "no notification received")
+	}
+	require.Never(t, func() bool {
+		return len(removed.changed) > 0 || len(duplicate.changed) > 0
+	}, 100*time.Millisecond, 10*time.Millisecond)
+}
+
 func (tsuite *SecretImplTestSuite) TestSecretSet() {
 	t := tsuite.T()
 
@@ -796,9 +937,24 @@ type MockupSecretPlugin struct {
 func (*MockupSecretPlugin) SampleConfig() string                { return "Mockup test secret plugin" }
 func (*MockupSecretPlugin) Gather(_ telegraf.Accumulator) error { return nil }
 
+// Mockup (input) plugin for testing secret change notifications
+type MockupSecretHandlerPlugin struct {
+	Secret Secret `toml:"secret"`
+
+	changed chan string
+}
+
+func (*MockupSecretHandlerPlugin) SampleConfig() string                { return "Mockup test secret plugin" }
+func (*MockupSecretHandlerPlugin) Gather(_ telegraf.Accumulator) error { return nil }
+func (m *MockupSecretHandlerPlugin) SecretChanged(ref string) {
+	m.changed <- ref
+}
+
 type MockupSecretStore struct {
 	Secrets map[string][]byte
 	Dynamic bool
+
+	callback func(key string)
 }
 
 func (*MockupSecretStore) Init() error {
@@ -828,6 +984,9 @@ func (s *MockupSecretStore) List() ([]string, error) {
 	}
 	return keys, nil
 }
+func (s *MockupSecretStore) SetSecretChangeCallback(callback func(key string)) {
+	s.callback = callback
+}
 func (s *MockupSecretStore) GetResolver(key string) (telegraf.ResolveFunc, error) {
 	return func() ([]byte, bool, error) {
 		v, err := s.Get(key)
@@ -839,6 +998,9 @@ func (s *MockupSecretStore) GetResolver(key string) (telegraf.ResolveFunc, error
 func init() {
 	// Register the mockup input plugin for the required names
 	inputs.Add("mockup", func() telegraf.Input { return &MockupSecretPlugin{} })
+	inputs.Add("mockup_handler", func() telegraf.Input {
+		return &MockupSecretHandlerPlugin{changed: make(chan string, 10)}
+	})
 	secretstores.Add("mockup", func(string) telegraf.SecretStore {
 		return &MockupSecretStore{}
 	})
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/toml/ast"
+)
+
+// collectTemplates stores the `[templates.<name>]` tables of the given
+// configuration to be used by plugins via the `use_template` option.
+// Templates of previously loaded configurations remain available.
+func (c *Config) collectTemplates(tbl *ast.Table) error {
+	node, found := tbl.Fields["templates"]
+	if !found {
+		return nil
+	}
+	subTable, ok := node.(*ast.Table)
+	if !ok {
+		return errors.New("invalid configuration, error parsing templates table")
+	}
+
+	for name, val := range subTable.Fields {
+		var template *ast.Table
+		switch t := val.(type) {
+		case *ast.Table:
+			template = t
+		case []*ast.Table:
+			if len(t) != 1 {
+				return fmt.Errorf("template %q defined multiple times", name)
+			}
+			template = t[0]
+		default:
+			return fmt.Errorf("unsupported config format: template %s", name)
+		}
+
+		if _, found := c.templates[name]; found {
+			return fmt.Errorf("template %q defined multiple times", name)
+		}
+		if _, found := template.Fields["use_template"]; found {
+			return fmt.Errorf("line %d: template %q must not use other templates", template.Line, name)
+		}
+		c.templates[name] = template
+	}
+	return nil
+}
+
+// applyTemplates merges the templates referenced via `use_template` into all
+// tables of the configuration except for the templates themselves.
+func (c *Config) applyTemplates(tbl *ast.Table) error {
+	if err := c.checkTemplateCycles(); err != nil {
+		return err
+	}
+
+	for name, val := range tbl.Fields {
+		if name == "templates" {
+			continue
+		}
+		if err := c.applyTemplatesToNode(val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Config) applyTemplatesToNode(node interface{}) error {
+	switch n := node.(type) {
+	case *ast.Table:
+		// Resolve the templates of the table first to also handle templates
+		// used in the sub-tables of the templates
+		if err := c.resolveTemplates(n); err != nil {
+			return err
+		}
+		for _, val := range n.Fields {
+			if err := c.applyTemplatesToNode(val); err != nil {
+				return err
+			}
+		}
+	case []*ast.Table:
+		for _, t := range n {
+			if err := c.applyTemplatesToNode(t); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkTemplateCycles returns an error if the sub-tables of templates use
+// templates in a cycle, as resolving those templates would never end.
+func (c *Config) checkTemplateCycles() error {
+	names := make([]string, 0, len(c.templates))
+	for name := range c.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	done := make(map[string]bool, len(names))
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		if idx := slices.Index(path, name); idx >= 0 {
+			cycle := append(slices.Clone(path[idx:]), name)
+			return fmt.Errorf("templates used in a cycle: %s", strings.Join(cycle, " -> "))
+		}
+		template, found := c.templates[name]
+		if !found || done[name] {
+			return nil
+		}
+
+		path = append(path, name)
+		for _, used := range usedTemplates(template) {
+			if err := visit(used); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		done[name] = true
+
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// usedTemplates returns the names of the templates used by the sub-tables of
+// the given node. Invalid settings are skipped as they are reported when
+// resolving the templates.
+func usedTemplates(node interface{}) []string {
+	var names []string
+	switch n := node.(type) {
+	case *ast.Table:
+		if kv, ok := n.Fields["use_template"].(*ast.KeyValue); ok {
+			if used, err := templateNames(kv); err == nil {
+				names = append(names, used...)
+			}
+		}
+		for _, val := range n.Fields {
+			names = append(names, usedTemplates(val)...)
+		}
+	case []*ast.Table:
+		for _, t := range n {
+			names = append(names, usedTemplates(t)...)
+		}
+	}
+	return names
+}
+
+// resolveTemplates merges the templates referenced by the table into the
+// table. Options set in the table take precedence over the ones of the
+// templates and later templates take precedence over earlier ones.
+func (c *Config) resolveTemplates(tbl *ast.Table) error {
+	node, found := tbl.Fields["use_template"]
+	if !found {
+		return nil
+	}
+	kv, ok := node.(*ast.KeyValue)
+	if !ok {
+		return fmt.Errorf("line %d: invalid use_template setting", tbl.Line)
+	}
+
+	names, err := templateNames(kv)
+	if err != nil {
+		return err
+	}
+	delete(tbl.Fields, "use_template")
+
+	// Options are only added if not set already, so apply the templates in
+	// reverse order to let later templates take precedence
+	for i := len(names) - 1; i >= 0; i-- {
+		template, found := c.templates[names[i]]
+		if !found {
+			return fmt.Errorf("line %d: undefined template %q", kv.Line, names[i])
+		}
+		mergeTable(tbl, template)
+	}
+	return nil
+}
+
+// templateNames returns the names of the templates referenced by the given
+// use_template setting.
+func templateNames(kv *ast.KeyValue) ([]string, error) {
+	var names []string
+	switch v := kv.Value.(type) {
+	case *ast.String:
+		names = append(names, v.Value)
+	case *ast.Array:
+		for _, element := range v.Value {
+			s, ok := element.(*ast.String)
+			if !ok {
+				return nil, fmt.Errorf("line %d: invalid use_template setting, expected template names", kv.Line)
+			}
+			names = append(names, s.Value)
+		}
+	default:
+		return nil, fmt.Errorf("line %d: invalid use_template setting, expected template names", kv.Line)
+	}
+	return names, nil
+}
+
+// mergeTable adds the options of src missing in dst to dst, descending into
+// sub-tables existing in both.
+func mergeTable(dst, src *ast.Table) {
+	for key, val := range src.Fields {
+		existing, found := dst.Fields[key]
+		if !found {
+			dst.Fields[key] = cloneNode(val)
+			continue
+		}
+		dstTable, dstIsTable := existing.(*ast.Table)
+		srcTable, srcIsTable := val.(*ast.Table)
+		if dstIsTable && srcIsTable {
+			mergeTable(dstTable, srcTable)
+		}
+	}
+}
+
+// cloneNode copies the tables of the node to allow modifying them without
+// affecting the template. Values are never modified and thus shared.
+func cloneNode(node interface{}) interface{} {
+	switch n := node.(type) {
+	case *ast.Table:
+		t := *n
+		t.Fields = make(map[string]interface{}, len(n.Fields))
+		for key, val := range n.Fields {
+			t.Fields[key] = cloneNode(val)
+		}
+		return &t
+	case []*ast.Table:
+		tables := make([]*ast.Table, 0, len(n))
+		for _, t := range n {
+			tables = append(tables, cloneNode(t).(*ast.Table))
+		}
+		return tables
+	}
+	return node
+}
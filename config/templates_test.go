@@ -0,0 +1,160 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+func TestConfig_Templates(t *testing.T) {
+	c := config.NewConfig()
+	cfg := []byte(`
+[templates.common]
+  servers = ["tcp://internal:1234"]
+  timeout = "30s"
+  [templates.common.tags]
+    dc = "internal"
+
+[[templates.fast]]
+  timeout = "5s"
+  read_timeout = "1s"
+
+[[inputs.memcached]]
+  use_template = "common"
+
+[[inputs.memcached]]
+  use_template = ["common", "fast"]
+  port = 8080
+  read_timeout = "2s"
+  [inputs.memcached.tags]
+    role = "edge"
+
+[[inputs.memcached]]
+  use_template = "common"
+  servers = ["tcp://other:1234"]
+`)
+	require.NoError(t, c.LoadConfigData(cfg, config.EmptySourcePath))
+	require.Len(t, c.Inputs, 3)
+
+	plugin, ok := c.Inputs[0].Input.(*MockupInputPlugin)
+	require.True(t, ok)
+	require.Equal(t, []string{"tcp://internal:1234"}, plugin.Servers)
+	require.Equal(t, config.Duration(30*time.Second), plugin.Timeout)
+	require.Equal(t, map[string]string{"dc": "internal"}, c.Inputs[0].Config.Tags)
+
+	// Options of the plugin take precedence over later templates taking
+	// precedence over earlier ones
+	plugin, ok = c.Inputs[1].Input.(*MockupInputPlugin)
+	require.True(t, ok)
+	require.Equal(t, []string{"tcp://internal:1234"}, plugin.Servers)
+	require.Equal(t, config.Duration(5*time.Second), plugin.Timeout)
+	require.Equal(t, config.Duration(2*time.Second), plugin.ReadTimeout)
+	require.Equal(t, 8080, plugin.Port)
+	require.Equal(t, map[string]string{"dc": "internal", "role": "edge"}, c.Inputs[1].Config.Tags)
+
+	plugin, ok = c.Inputs[2].Input.(*MockupInputPlugin)
+	require.True(t, ok)
+	require.Equal(t, []string{"tcp://other:1234"}, plugin.Servers)
+	require.Equal(t, map[string]string{"dc": "internal"}, c.Inputs[2].Config.Tags)
+	require.Empty(t, c.UnusedFields)
+}
+
+func TestConfig_TemplatesAcrossFiles(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(`
+[templates.common]
+  timeout = "30s"
+`), config.EmptySourcePath))
+	require.NoError(t, c.LoadConfigData([]byte(`
+[[inputs.memcached]]
+  use_template = "common"
+`), config.EmptySourcePath))
+
+	require.Len(t, c.Inputs, 1)
+	plugin, ok := c.Inputs[0].Input.(*MockupInputPlugin)
+	require.True(t, ok)
+	require.Equal(t, config.Duration(30*time.Second), plugin.Timeout)
+}
+
+func TestConfig_TemplatesInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      string
+		expected string
+	}{
+		{
+			name: "undefined template",
+			cfg: `
+[[inputs.memcached]]
+  use_template = "missing"
+`,
+			expected: `line 3: undefined template "missing"`,
+		},
+		{
+			name: "invalid reference",
+			cfg: `
+[[inputs.memcached]]
+  use_template = 42
+`,
+			expected: "line 3: invalid use_template setting, expected template names",
+		},
+		{
+			name: "nested template",
+			cfg: `
+[templates.a]
+  timeout = "5s"
+
+[templates.b]
+  use_template = "a"
+`,
+			expected: `line 5: template "b" must not use other templates`,
+		},
+		{
+			name: "cyclic sub-tables",
+			cfg: `
+[templates.a]
+  timeout = "5s"
+  [templates.a.tls]
+    use_template = "b"
+
+[templates.b]
+  [templates.b.auth]
+    use_template = "a"
+
+[[inputs.memcached]]
+  use_template = "a"
+`,
+			expected: "templates used in a cycle: a -> b -> a",
+		},
+		{
+			name: "self-referencing sub-table",
+			cfg: `
+[templates.a]
+  [templates.a.tls]
+    use_template = "a"
+`,
+			expected: "templates used in a cycle: a -> a",
+		},
+		{
+			name: "duplicate template",
+			cfg: `
+[[templates.a]]
+  timeout = "5s"
+
+[[templates.a]]
+  timeout = "10s"
+`,
+			expected: `template "a" defined multiple times`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := config.NewConfig()
+			require.ErrorContains(t, c.LoadConfigData([]byte(tt.cfg), config.EmptySourcePath), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package prometheus
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/prometheus/common/model"
+)
+
+type Table struct {
+	First *unicode.RangeTable
+	Rest  *unicode.RangeTable
+}
+
+var MetricNameTable = Table{
+	First: &unicode.RangeTable{
+		R16: []unicode.Range16{
+			{0x003A, 0x003A, 1}, // :
+			{0x0041, 0x005A, 1}, // A-Z
+			{0x005F, 0x005F, 1}, // _
+			{0x0061, 0x007A, 1}, // a-z
+		},
+		LatinOffset: 4,
+	},
+	Rest: &unicode.RangeTable{
+		R16: []unicode.Range16{
+			{0x0030, 0x003A, 1}, // 0-:
+			{0x0041, 0x005A, 1}, // A-Z
+			{0x005F, 0x005F, 1}, // _
+			{0x0061, 0x007A, 1}, // a-z
+		},
+		LatinOffset: 4,
+	},
+}
+
+var LabelNameTable = Table{
+	First: &unicode.RangeTable{
+		R16: []unicode.Range16{
+			{0x0041, 0x005A, 1}, // A-Z
+			{0x005F, 0x005F, 1}, // _
+			{0x0061, 0x007A, 1}, // a-z
+		},
+		LatinOffset: 3,
+	},
+	Rest: &unicode.RangeTable{
+		R16: []unicode.Range16{
+			{0x0030, 0x0039, 1}, // 0-9
+			{0x0041, 0x005A, 1}, // A-Z
+			{0x005F, 0x005F, 1}, // _
+			{0x0061, 0x007A, 1}, // a-z
+		},
+		LatinOffset: 4,
+	},
+}
+
+// Sanitize checks if the name is valid according to the table.  If not, it
+// attempts to replaces invalid runes with an underscore to create a valid
+// name.
+func sanitize(name string, table Table) (string, bool) {
+	var b strings.Builder
+
+	for i, r := range name {
+		switch {
+		case i == 0:
+			if unicode.In(r, table.First) {
+				b.WriteRune(r)
+			}
+		default:
+			if unicode.In(r, table.Rest) {
+				b.WriteRune(r)
+			} else {
+				b.WriteString("_")
+			}
+		}
+	}
+
+	name = strings.Trim(b.String(), "_")
+	if name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+// SanitizeMetricName checks if the name is a valid Prometheus metric name.  If
+// not, it attempts to replaces invalid runes with an underscore to create a
+// valid name.
+func SanitizeMetricName(name string) (string, bool) {
+	if model.IsValidMetricName(model.LabelValue(name)) {
+		return name, true
+	}
+	return sanitize(name, MetricNameTable)
+}
+
+// SanitizeLabelName checks if the name is a valid Prometheus label name.  If
+// not, it attempts to replaces invalid runes with an underscore to create a
+// valid name.
+func SanitizeLabelName(name string) (string, bool) {
+	if model.LabelName(name).IsValidLegacy() {
+		return name, true
+	}
+	return sanitize(name, LabelNameTable)
+}
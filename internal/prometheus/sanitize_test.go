@@ -0,0 +1,50 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeMetricName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		ok       bool
+	}{
+		{name: "valid", input: "cpu_usage_idle", expected: "cpu_usage_idle", ok: true},
+		{name: "colon", input: "job:cpu:rate5m", expected: "job:cpu:rate5m", ok: true},
+		{name: "utf-8", input: "disk-io.reads", expected: "disk-io.reads", ok: true},
+		{name: "empty", input: "", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, ok := SanitizeMetricName(tt.input)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestSanitizeLabelName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		ok       bool
+	}{
+		{name: "valid", input: "host_name", expected: "host_name", ok: true},
+		{name: "colon", input: "job:name", expected: "job_name", ok: true},
+		{name: "invalid runes", input: "cpu-core.id", expected: "cpu_core_id", ok: true},
+		{name: "invalid first rune", input: "0core", expected: "core", ok: true},
+		{name: "no valid runes", input: "-", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, ok := SanitizeLabelName(tt.input)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+var scheduleParser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+// Schedule is a cron schedule evaluated on the wall-clock time of a timezone.
+// Wall-clock times occurring twice due to a daylight saving time change
+// trigger only once at their first occurrence, times skipped by the change
+// are shifted by the length of the gap.
+type Schedule struct {
+	spec     *cron.SpecSchedule
+	location *time.Location
+}
+
+// ParseSchedule parses the cron expression with optional seconds field or
+// descriptors like "@daily". The timezone defaults to the one given by a
+// "CRON_TZ=" prefix of the expression or the local timezone.
+func ParseSchedule(expression, timezone string) (*Schedule, error) {
+	parsed, err := scheduleParser.Parse(expression)
+	if err != nil {
+		return nil, fmt.Errorf("parsing schedule %q failed: %w", expression, err)
+	}
+	spec, ok := parsed.(*cron.SpecSchedule)
+	if !ok {
+		return nil, fmt.Errorf("schedule %q is not supported, use an interval instead", expression)
+	}
+
+	location := spec.Location
+	if timezone != "" {
+		location, err = time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("loading timezone %q failed: %w", timezone, err)
+		}
+	}
+
+	// Compute the schedule on the wall-clock time, represented in UTC, to
+	// resolve daylight saving time changes ourselves
+	spec.Location = time.UTC
+	s := &Schedule{spec: spec, location: location}
+
+	if s.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("schedule %q never triggers", expression)
+	}
+	return s, nil
+}
+
+// Next returns the first time of the schedule after the given time or the
+// zero time if there is none within the next five years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	wall := t.In(s.location)
+	next := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), time.UTC)
+	for {
+		next = s.spec.Next(next)
+		if next.IsZero() {
+			return next
+		}
+		if resolved := s.resolve(next); resolved.After(t) {
+			return resolved
+		}
+	}
+}
+
+// Interval returns the shortest time between two consecutive times of the
+// schedule within the next 1000 times after the given time, or zero if the
+// schedule does not trigger at least twice.
+func (s *Schedule) Interval(t time.Time) time.Duration {
+	var interval time.Duration
+	prev := s.Next(t)
+	for range 1000 {
+		if prev.IsZero() {
+			break
+		}
+		next := s.Next(prev)
+		if next.IsZero() {
+			break
+		}
+		if d := next.Sub(prev); interval == 0 || d < interval {
+			interval = d
+		}
+		prev = next
+	}
+	return interval
+}
+
+// resolve returns the earliest time with the given wall-clock time,
+// represented in UTC, in the location of the schedule. Wall-clock times
+// skipped by a change of the zone offset are shifted by the length of the gap.
+func (s *Schedule) resolve(wall time.Time) time.Time {
+	// Zone offsets do not change more than once within two days
+	_, before := wall.Add(-24 * time.Hour).In(s.location).Zone()
+	_, after := wall.Add(24 * time.Hour).In(s.location).Zone()
+
+	for _, offset := range []int{before, after} {
+		t := wall.Add(-time.Duration(offset) * time.Second).In(s.location)
+		year, month, day := t.Date()
+		hour, minute, second := t.Clock()
+		if year == wall.Year() && month == wall.Month() && day == wall.Day() &&
+			hour == wall.Hour() && minute == wall.Minute() && second == wall.Second() {
+			return t
+		}
+	}
+	return wall.Add(-time.Duration(before) * time.Second).In(s.location)
+}
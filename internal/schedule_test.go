@@ -0,0 +1,135 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		expression string
+		timezone   string
+		start      time.Time
+		expected   []time.Time
+	}{
+		{
+			name:       "daily",
+			expression: "0 2 * * *",
+			timezone:   "America/New_York",
+			start:      time.Date(2024, 6, 1, 12, 0, 0, 0, newYork),
+			expected: []time.Time{
+				time.Date(2024, 6, 2, 2, 0, 0, 0, newYork),
+				time.Date(2024, 6, 3, 2, 0, 0, 0, newYork),
+			},
+		},
+		{
+			name:       "weekdays at the top of the hour",
+			expression: "0 * * * MON-FRI",
+			timezone:   "America/New_York",
+			start:      time.Date(2024, 6, 7, 22, 30, 0, 0, newYork),
+			expected: []time.Time{
+				time.Date(2024, 6, 7, 23, 0, 0, 0, newYork),
+				time.Date(2024, 6, 10, 0, 0, 0, 0, newYork),
+				time.Date(2024, 6, 10, 1, 0, 0, 0, newYork),
+			},
+		},
+		{
+			name:       "skipped time is shifted",
+			expression: "30 2 * * *",
+			timezone:   "America/New_York",
+			start:      time.Date(2024, 3, 9, 12, 0, 0, 0, newYork),
+			expected: []time.Time{
+				time.Date(2024, 3, 10, 3, 30, 0, 0, newYork),
+				time.Date(2024, 3, 11, 2, 30, 0, 0, newYork),
+			},
+		},
+		{
+			name:       "repeated time triggers once",
+			expression: "30 1 * * *",
+			timezone:   "America/New_York",
+			start:      time.Date(2024, 11, 2, 12, 0, 0, 0, newYork),
+			expected: []time.Time{
+				time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC),
+				time.Date(2024, 11, 4, 1, 30, 0, 0, newYork),
+			},
+		},
+		{
+			name:       "timezone prefix",
+			expression: "CRON_TZ=America/New_York @daily",
+			start:      time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+			expected: []time.Time{
+				time.Date(2024, 6, 2, 0, 0, 0, 0, newYork),
+			},
+		},
+		{
+			name:       "seconds",
+			expression: "*/20 * * * * *",
+			timezone:   "UTC",
+			start:      time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+			expected: []time.Time{
+				time.Date(2024, 6, 1, 12, 0, 20, 0, time.UTC),
+				time.Date(2024, 6, 1, 12, 0, 40, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseSchedule(tt.expression, tt.timezone)
+			require.NoError(t, err)
+
+			actual := make([]time.Time, 0, len(tt.expected))
+			next := tt.start
+			for range tt.expected {
+				next = s.Next(next)
+				actual = append(actual, next)
+			}
+			require.Len(t, actual, len(tt.expected))
+			for i := range tt.expected {
+				require.Truef(t, tt.expected[i].Equal(actual[i]), "expected %v but got %v", tt.expected[i], actual[i])
+			}
+		})
+	}
+}
+
+func TestScheduleInterval(t *testing.T) {
+	start := time.Date(2024, 6, 7, 22, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expression string
+		expected   time.Duration
+	}{
+		{expression: "*/10 * * * * *", expected: 10 * time.Second},
+		{expression: "0 2 * * *", expected: 24 * time.Hour},
+		{expression: "0 9,17 * * MON-FRI", expected: 8 * time.Hour},
+		{expression: "@yearly", expected: 365 * 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			s, err := ParseSchedule(tt.expression, "UTC")
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, s.Interval(start))
+		})
+	}
+}
+
+func TestScheduleInvalid(t *testing.T) {
+	_, err := ParseSchedule("* * *", "")
+	require.ErrorContains(t, err, "parsing schedule")
+
+	_, err = ParseSchedule("@every 1m", "")
+	require.ErrorContains(t, err, "use an interval instead")
+
+	_, err = ParseSchedule("0 0 30 2 *", "")
+	require.ErrorContains(t, err, "never triggers")
+
+	_, err = ParseSchedule("@daily", "Mars/Olympus_Mons")
+	require.ErrorContains(t, err, "loading timezone")
+}
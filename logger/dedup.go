@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// maxDedupEntries is the number of tracked messages above which expired
+// entries are removed
+const maxDedupEntries = 1000
+
+// dedup keeps track of the identical messages of a logger to suppress
+// repetitions exceeding a limit within an interval
+type dedup struct {
+	entries map[string]*dedupEntry
+	// next is the earliest expiry of an interval with suppressed messages
+	next time.Time
+	sync.Mutex
+}
+
+type dedupEntry struct {
+	level      telegraf.LogLevel
+	args       []interface{}
+	start      time.Time
+	count      int
+	suppressed int
+}
+
+// suppressedNote returns the note added to messages to report suppressions
+func suppressedNote(n int) string {
+	return fmt.Sprintf(" (%d identical messages suppressed)", n)
+}
+
+// check returns if the message should be logged and, for the first message
+// logged in a new interval, the number of messages suppressed in the
+// previous interval. Additionally, the entries of other messages with
+// suppressions in an expired interval are returned to report those.
+func (d *dedup) check(level telegraf.LogLevel, ts time.Time, interval time.Duration, limit int, args ...interface{}) (bool, int, []*dedupEntry) {
+	key := level.Indicator() + fmt.Sprint(args...)
+
+	d.Lock()
+	defer d.Unlock()
+
+	if d.entries == nil {
+		d.entries = make(map[string]*dedupEntry)
+	}
+
+	e, found := d.entries[key]
+	if !found || ts.Sub(e.start) >= interval {
+		var suppressed int
+		if found {
+			suppressed = e.suppressed
+		}
+		d.entries[key] = &dedupEntry{level: level, args: args, start: ts, count: 1}
+		return true, suppressed, d.cleanup(ts, interval)
+	}
+
+	if e.count < limit {
+		e.count++
+		return true, 0, d.expire(ts, interval)
+	}
+	if e.suppressed == 0 {
+		if end := e.start.Add(interval); d.next.IsZero() || end.Before(d.next) {
+			d.next = end
+		}
+	}
+	e.suppressed++
+	return false, 0, d.expire(ts, interval)
+}
+
+// expire removes and returns the entries with suppressed messages whose
+// interval expired
+func (d *dedup) expire(ts time.Time, interval time.Duration) []*dedupEntry {
+	if d.next.IsZero() || ts.Before(d.next) {
+		return nil
+	}
+
+	var expired []*dedupEntry
+	d.next = time.Time{}
+	for k, e := range d.entries {
+		if e.suppressed == 0 {
+			continue
+		}
+		if ts.Sub(e.start) >= interval {
+			expired = append(expired, e)
+			delete(d.entries, k)
+			continue
+		}
+		if end := e.start.Add(interval); d.next.IsZero() || end.Before(d.next) {
+			d.next = end
+		}
+	}
+	return expired
+}
+
+// cleanup removes expired entries to limit the memory used for messages
+// containing varying content and returns the removed entries with suppressed
+// messages
+func (d *dedup) cleanup(ts time.Time, interval time.Duration) []*dedupEntry {
+	expired := d.expire(ts, interval)
+	if len(d.entries) <= maxDedupEntries {
+		return expired
+	}
+	for k, e := range d.entries {
+		if ts.Sub(e.start) >= interval {
+			delete(d.entries, k)
+		}
+	}
+	return expired
+}
@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+)
+
+func TestDedup(t *testing.T) {
+	instance = defaultHandler()
+
+	tmpfile, err := os.CreateTemp(t.TempDir(), "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+
+	filename := tmpfile.Name()
+	require.NoError(t, tmpfile.Close())
+
+	cfg := &Config{
+		Logfile:             filename,
+		RotationMaxArchives: -1,
+		DedupInterval:       time.Minute,
+		DedupLimit:          2,
+	}
+	require.NoError(t, SetupLogging(cfg))
+	defer func() { require.NoError(t, CloseLogging()) }()
+
+	ts := time.Unix(1722443551, 0)
+	l := New("inputs", "test", "")
+	other := New("inputs", "test", "other")
+	for i := 0; i < 5; i++ {
+		l.Print(telegraf.Warn, ts.Add(time.Duration(i)*time.Second), "repeated message")
+		other.Print(telegraf.Warn, ts.Add(time.Duration(i)*time.Second), "repeated message")
+	}
+	l.Print(telegraf.Error, ts.Add(5*time.Second), "repeated message")
+	l.Print(telegraf.Warn, ts.Add(6*time.Second), "another message")
+	l.Print(telegraf.Warn, ts.Add(time.Minute), "repeated message")
+
+	buf, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(buf)), "\n")
+
+	expected := []string{
+		"2024-07-31T16:32:31Z W! [inputs.test] repeated message",
+		"2024-07-31T16:32:31Z W! [inputs.test::other] repeated message",
+		"2024-07-31T16:32:32Z W! [inputs.test] repeated message",
+		"2024-07-31T16:32:32Z W! [inputs.test::other] repeated message",
+		"2024-07-31T16:32:36Z E! [inputs.test] repeated message",
+		"2024-07-31T16:32:37Z W! [inputs.test] another message",
+		"2024-07-31T16:33:31Z W! [inputs.test] repeated message (3 identical messages suppressed)",
+	}
+	require.Equal(t, expected, lines)
+}
+
+func TestDedupExpired(t *testing.T) {
+	instance = defaultHandler()
+
+	tmpfile, err := os.CreateTemp(t.TempDir(), "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+
+	filename := tmpfile.Name()
+	require.NoError(t, tmpfile.Close())
+
+	cfg := &Config{
+		Logfile:             filename,
+		RotationMaxArchives: -1,
+		DedupInterval:       time.Minute,
+		DedupLimit:          1,
+	}
+	require.NoError(t, SetupLogging(cfg))
+	defer func() { require.NoError(t, CloseLogging()) }()
+
+	ts := time.Unix(1722443551, 0)
+	l := New("inputs", "test", "")
+	for i := 0; i < 3; i++ {
+		l.Print(telegraf.Warn, ts.Add(time.Duration(i)*time.Second), "repeated message")
+	}
+	l.Print(telegraf.Warn, ts.Add(30*time.Second), "another message")
+	l.Print(telegraf.Warn, ts.Add(2*time.Minute), "another message")
+	l.Print(telegraf.Warn, ts.Add(3*time.Minute), "another message")
+
+	buf, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(buf)), "\n")
+
+	expected := []string{
+		"2024-07-31T16:32:31Z W! [inputs.test] repeated message",
+		"2024-07-31T16:33:01Z W! [inputs.test] another message",
+		"2024-07-31T16:34:31Z W! [inputs.test] repeated message (2 identical messages suppressed)",
+		"2024-07-31T16:34:31Z W! [inputs.test] another message",
+		"2024-07-31T16:35:31Z W! [inputs.test] another message",
+	}
+	require.Equal(t, expected, lines)
+}
+
+func TestDedupInvalid(t *testing.T) {
+	instance = defaultHandler()
+	require.ErrorContains(t, SetupLogging(&Config{DedupInterval: -time.Second}), "invalid log deduplication interval")
+	require.ErrorContains(t, SetupLogging(&Config{DedupLimit: -1}), "invalid log deduplication limit")
+}
+
+func TestDedupCleanup(t *testing.T) {
+	var d dedup
+	ts := time.Unix(1722443551, 0)
+	for i := 0; i <= maxDedupEntries; i++ {
+		pass, _, _ := d.check(telegraf.Info, ts, time.Second, 1, "message ", i)
+		require.True(t, pass)
+	}
+	require.Len(t, d.entries, maxDedupEntries+1)
+
+	// Expired entries are removed when adding a new one
+	pass, _, _ := d.check(telegraf.Info, ts.Add(time.Second), time.Second, 1, "new message")
+	require.True(t, pass)
+	require.Len(t, d.entries, 1)
+}
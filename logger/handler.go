@@ -25,6 +25,9 @@ type handler struct {
 	level    telegraf.LogLevel
 	timezone *time.Location
 
+	dedupInterval time.Duration
+	dedupLimit    int
+
 	impl      sink
 	earlysink *log.Logger
 	earlylogs *list.List
@@ -71,6 +74,19 @@ func (h *handler) switchSink(impl sink, level telegraf.LogLevel, tz *time.Locati
 	h.Unlock()
 }
 
+func (h *handler) setDedup(interval time.Duration, limit int) {
+	h.Lock()
+	h.dedupInterval = interval
+	h.dedupLimit = limit
+	h.Unlock()
+}
+
+func (h *handler) dedupSettings() (time.Duration, int) {
+	h.Lock()
+	defer h.Unlock()
+	return h.dedupInterval, h.dedupLimit
+}
+
 func (h *handler) add(level telegraf.LogLevel, ts time.Time, prefix string, attr map[string]interface{}, args ...interface{}) *entry {
 	e := &entry{
 		timestamp:  ts,
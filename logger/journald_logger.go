@@ -0,0 +1,108 @@
+//go:build linux
+
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+const defaultJournaldSocket = "/run/systemd/journal/socket"
+
+type journaldLogger struct {
+	conn       *net.UnixConn
+	identifier string
+	errlog     *log.Logger
+}
+
+func (l *journaldLogger) Close() error {
+	return l.conn.Close()
+}
+
+func (l *journaldLogger) Print(level telegraf.LogLevel, _ time.Time, prefix string, attr map[string]interface{}, args ...interface{}) {
+	// Translate the Telegraf log-levels to syslog priorities
+	var priority int
+	switch level {
+	case telegraf.Error:
+		priority = 3
+	case telegraf.Warn:
+		priority = 4
+	case telegraf.Info:
+		priority = 6
+	default:
+		priority = 7
+	}
+
+	var buf bytes.Buffer
+	writeJournaldField(&buf, "MESSAGE", prefix+fmt.Sprint(args...))
+	writeJournaldField(&buf, "PRIORITY", strconv.Itoa(priority))
+	writeJournaldField(&buf, "SYSLOG_IDENTIFIER", l.identifier)
+	for k, v := range attr {
+		writeJournaldField(&buf, journaldFieldName(k), fmt.Sprint(v))
+	}
+
+	if _, err := l.conn.Write(buf.Bytes()); err != nil {
+		l.errlog.Printf("E! Writing log message failed: %v", err)
+	}
+}
+
+// writeJournaldField serializes the field using the native journal protocol,
+// values containing newlines are written in the binary form.
+func writeJournaldField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteByte('\n')
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// journaldFieldName converts the attribute key to a valid journal field
+// name only consisting of uppercase letters, digits and underscores
+func journaldFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, key)
+	return "TELEGRAF_" + name
+}
+
+func createJournaldLogger(cfg *Config) (sink, error) {
+	path := cfg.Logfile
+	if path == "" {
+		path = defaultJournaldSocket
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("connecting to journald failed: %w", err)
+	}
+
+	return &journaldLogger{
+		conn:       conn,
+		identifier: cfg.InstanceName,
+		errlog:     log.New(os.Stderr, "", 0),
+	}, nil
+}
+
+func init() {
+	add("journald", createJournaldLogger)
+}
@@ -0,0 +1,76 @@
+//go:build linux
+
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournald(t *testing.T) {
+	instance = defaultHandler()
+
+	// Use a short path as the length of socket paths is limited
+	tmpdir, err := os.MkdirTemp("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+	addr := filepath.Join(tmpdir, "journal.sock")
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: addr, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	cfg := &Config{
+		LogFormat:    "journald",
+		Logfile:      addr,
+		InstanceName: "mytelegraf",
+	}
+	require.NoError(t, SetupLogging(cfg))
+	defer func() { require.NoError(t, CloseLogging()) }()
+
+	l := New("inputs", "test", "myalias")
+	l.SetID("f4ee9f6a0d5e5c1e")
+	l.AddAttribute("custom-key", 42)
+	l.Warn("TEST")
+
+	buf := make([]byte, 1024)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		"MESSAGE":             "[inputs.test::myalias] TEST",
+		"PRIORITY":            "4",
+		"SYSLOG_IDENTIFIER":   "mytelegraf",
+		"TELEGRAF_CATEGORY":   "inputs",
+		"TELEGRAF_PLUGIN":     "test",
+		"TELEGRAF_ALIAS":      "myalias",
+		"TELEGRAF_ID":         "f4ee9f6a0d5e5c1e",
+		"TELEGRAF_CUSTOM_KEY": "42",
+	}
+	actual := make(map[string]string)
+	for _, line := range bytes.Split(bytes.TrimSuffix(buf[:n], []byte("\n")), []byte("\n")) {
+		k, v, found := bytes.Cut(line, []byte("="))
+		require.True(t, found)
+		actual[string(k)] = string(v)
+	}
+	require.Equal(t, expected, actual)
+}
+
+func TestJournaldMultilineField(t *testing.T) {
+	var buf bytes.Buffer
+	writeJournaldField(&buf, "MESSAGE", "first\nsecond")
+
+	var expected bytes.Buffer
+	expected.WriteString("MESSAGE\n")
+	require.NoError(t, binary.Write(&expected, binary.LittleEndian, uint64(12)))
+	expected.WriteString("first\nsecond\n")
+	require.Equal(t, expected.Bytes(), buf.Bytes())
+}
@@ -36,6 +36,7 @@ type logger struct {
 	prefix     string
 	onError    []func()
 	attributes map[string]interface{}
+	dedup      dedup
 }
 
 // New creates a new logging instance to be used in models
@@ -78,11 +79,18 @@ func (l *logger) Level() telegraf.LogLevel {
 	return instance.level
 }
 
+// SetID sets the ID of the plugin instance added to the logging output
+func (l *logger) SetID(id string) {
+	if id != "" {
+		l.attributes["id"] = id
+	}
+}
+
 // AddAttribute allows to add a key-value attribute to the logging output
 func (l *logger) AddAttribute(key string, value interface{}) {
 	// Do not allow to overwrite general keys
 	switch key {
-	case "category", "plugin", "alias":
+	case "category", "plugin", "alias", "id":
 	default:
 		l.attributes[key] = value
 	}
@@ -146,6 +154,24 @@ func (l *logger) Print(level telegraf.LogLevel, ts time.Time, args ...interface{
 	if l.level != nil && !l.level.Includes(level) || l.level == nil && !instance.level.Includes(level) {
 		return
 	}
+
+	// Suppress repeated identical messages if configured
+	if interval, limit := instance.dedupSettings(); instance.impl != nil && interval > 0 {
+		pass, suppressed, expired := l.dedup.check(level, ts, interval, limit, args...)
+		for _, e := range expired {
+			// Report messages suppressed in an expired interval that were
+			// not repeated since
+			msg := append(e.args[:len(e.args):len(e.args)], suppressedNote(e.suppressed))
+			instance.impl.Print(e.level, ts.In(instance.timezone), l.prefix, l.attributes, msg...)
+		}
+		if !pass {
+			return
+		}
+		if suppressed > 0 {
+			args = append(args, suppressedNote(suppressed))
+		}
+	}
+
 	if instance.impl != nil {
 		instance.impl.Print(level, ts.In(instance.timezone), l.prefix, l.attributes, args...)
 	} else {
@@ -198,6 +224,10 @@ type Config struct {
 	InstanceName string
 	// Structured logging message key
 	StructuredLogMessageKey string
+	// suppress identical messages of a plugin exceeding the limit within
+	// the interval, a zero interval disables the deduplication
+	DedupInterval time.Duration
+	DedupLimit    int
 
 	// internal  log-level
 	logLevel telegraf.LogLevel
@@ -248,6 +278,16 @@ func SetupLogging(cfg *Config) error {
 		cfg.InstanceName = "telegraf"
 	}
 
+	if cfg.DedupInterval < 0 {
+		return fmt.Errorf("invalid log deduplication interval %v", cfg.DedupInterval)
+	}
+	if cfg.DedupLimit < 0 {
+		return fmt.Errorf("invalid log deduplication limit %d", cfg.DedupLimit)
+	}
+	if cfg.DedupLimit == 0 {
+		cfg.DedupLimit = 1
+	}
+
 	if cfg.LogFormat == "" {
 		cfg.LogFormat = "text"
 	}
@@ -281,6 +321,7 @@ func SetupLogging(cfg *Config) error {
 	// Update the logging instance
 	skipEarlyLogs := cfg.LogFormat == "text" && cfg.Logfile == ""
 	instance.switchSink(l, cfg.logLevel, tz, skipEarlyLogs)
+	instance.setDedup(cfg.DedupInterval, cfg.DedupLimit)
 
 	return nil
 }
@@ -77,7 +77,8 @@ func init() {
 			writer = w
 		}
 
-		structuredHandlerOptions := defaultStructuredHandlerOptions
+		// Copy the options to not modify the defaults
+		structuredHandlerOptions := *defaultStructuredHandlerOptions
 
 		if cfg.StructuredLogMessageKey != "" {
 			structuredHandlerOptions.ReplaceAttr = func(groups []string, attr slog.Attr) slog.Attr {
@@ -90,7 +91,7 @@ func init() {
 		}
 
 		return &structuredLogger{
-			handler: slog.NewJSONHandler(writer, structuredHandlerOptions),
+			handler: slog.NewJSONHandler(writer, &structuredHandlerOptions),
 			output:  writer,
 			errlog:  log.New(os.Stderr, "", 0),
 		}, nil
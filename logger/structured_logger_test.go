@@ -391,3 +391,47 @@ func BenchmarkTelegrafStructuredLogWrite(b *testing.B) {
 		l.Print(telegraf.Debug, ts, "", nil, "test")
 	}
 }
+
+func TestStructuredPluginID(t *testing.T) {
+	instance = defaultHandler()
+
+	tmpfile, err := os.CreateTemp(t.TempDir(), "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+
+	filename := tmpfile.Name()
+	require.NoError(t, tmpfile.Close())
+
+	cfg := &Config{
+		Logfile:             filename,
+		LogFormat:           "structured",
+		RotationMaxArchives: -1,
+	}
+	require.NoError(t, SetupLogging(cfg))
+	defer func() { require.NoError(t, CloseLogging()) }()
+
+	l := New("inputs", "test", "myalias")
+	l.SetID("f4ee9f6a0d5e5c1e")
+	l.AddAttribute("id", "overwritten")
+	l.Info("TEST")
+
+	buf, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	expected := map[string]interface{}{
+		"level":    "INFO",
+		"msg":      "TEST",
+		"category": "inputs",
+		"plugin":   "test",
+		"alias":    "myalias",
+		"id":       "f4ee9f6a0d5e5c1e",
+	}
+
+	var actual map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf, &actual))
+
+	require.Contains(t, actual, "time")
+	require.NotEmpty(t, actual["time"])
+	delete(actual, "time")
+	require.Equal(t, expected, actual)
+}
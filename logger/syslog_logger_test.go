@@ -0,0 +1,60 @@
+//go:build !windows
+
+package logger
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslog(t *testing.T) {
+	instance = defaultHandler()
+
+	// Use a short path as the length of socket paths is limited
+	tmpdir, err := os.MkdirTemp("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+	addr := filepath.Join(tmpdir, "syslog.sock")
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: addr, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	cfg := &Config{
+		LogFormat:    "syslog",
+		Logfile:      addr,
+		InstanceName: "mytelegraf",
+		Debug:        true,
+	}
+	require.NoError(t, SetupLogging(cfg))
+	defer func() { require.NoError(t, CloseLogging()) }()
+
+	tests := []struct {
+		log      func(l *logger)
+		priority string
+	}{
+		{log: func(l *logger) { l.Error("TEST") }, priority: "<27>"},
+		{log: func(l *logger) { l.Warn("TEST") }, priority: "<28>"},
+		{log: func(l *logger) { l.Info("TEST") }, priority: "<30>"},
+		{log: func(l *logger) { l.Debug("TEST") }, priority: "<31>"},
+	}
+
+	l := New("inputs", "test", "")
+	buf := make([]byte, 1024)
+	for _, tt := range tests {
+		tt.log(l)
+
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		n, err := conn.Read(buf)
+		require.NoError(t, err)
+		msg := string(buf[:n])
+		require.Contains(t, msg, tt.priority)
+		require.Contains(t, msg, "mytelegraf[")
+		require.Contains(t, msg, "[inputs.test] TEST")
+	}
+}
@@ -54,12 +54,9 @@ func ToBytes(m telegraf.Metric) ([]byte, error) {
 }
 
 func FromBytes(b []byte) (telegraf.Metric, error) {
-	buf := bytes.NewBuffer(b)
-	decoder := gob.NewDecoder(buf)
-
-	var sm *serializedMetric
-	if err := decoder.Decode(&sm); err != nil {
-		return nil, fmt.Errorf("failed to decode metric from bytes: %w", err)
+	sm, err := decode(b)
+	if err != nil {
+		return nil, err
 	}
 
 	m := sm.M
@@ -83,3 +80,25 @@ func FromBytes(b []byte) (telegraf.Metric, error) {
 	}
 	return m, nil
 }
+
+// FromBytesUntracked deserializes a metric without restoring its tracking
+// information. This allows to read metrics serialized by another process
+// where the tracking information is not available.
+func FromBytesUntracked(b []byte) (telegraf.Metric, error) {
+	sm, err := decode(b)
+	if err != nil {
+		return nil, err
+	}
+	return sm.M, nil
+}
+
+func decode(b []byte) (*serializedMetric, error) {
+	buf := bytes.NewBuffer(b)
+	decoder := gob.NewDecoder(buf)
+
+	var sm *serializedMetric
+	if err := decoder.Decode(&sm); err != nil {
+		return nil, fmt.Errorf("failed to decode metric from bytes: %w", err)
+	}
+	return sm, nil
+}
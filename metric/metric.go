@@ -16,6 +16,8 @@ type metric struct {
 	MetricTime   time.Time
 
 	MetricType telegraf.ValueType
+
+	route string
 }
 
 func New(
@@ -73,6 +75,7 @@ func FromMetric(other telegraf.Metric) telegraf.Metric {
 		MetricFields: make([]*telegraf.Field, len(other.FieldList())),
 		MetricTime:   other.Time(),
 		MetricType:   other.Type(),
+		route:        Route(other),
 	}
 
 	for i, tag := range other.TagList() {
@@ -253,6 +256,7 @@ func (m *metric) Copy() telegraf.Metric {
 		MetricFields: make([]*telegraf.Field, len(m.MetricFields)),
 		MetricTime:   m.MetricTime,
 		MetricType:   m.MetricType,
+		route:        m.route,
 	}
 
 	for i, tag := range m.MetricTags {
@@ -326,3 +326,18 @@ func TestValueType(t *testing.T) {
 
 	require.Equal(t, telegraf.Gauge, m.Type())
 }
+
+func TestRoute(t *testing.T) {
+	m := baseMetric()
+	require.Empty(t, Route(m))
+
+	SetRoute(m, "foo")
+	require.Equal(t, "foo", Route(m))
+	require.Equal(t, "foo", Route(m.Copy()))
+	require.Equal(t, "foo", Route(FromMetric(m)))
+
+	tm, _ := WithTracking(m, func(telegraf.DeliveryInfo) {})
+	require.Equal(t, "foo", Route(tm))
+	SetRoute(tm, "bar")
+	require.Equal(t, "bar", Route(m))
+}
@@ -0,0 +1,26 @@
+package metric
+
+import "github.com/influxdata/telegraf"
+
+// Route returns the name of the route the metric belongs to or an empty
+// string for metrics not belonging to any route.
+func Route(m telegraf.Metric) string {
+	if wm, ok := m.(telegraf.UnwrappableMetric); ok {
+		m = wm.Unwrap()
+	}
+	if rm, ok := m.(*metric); ok {
+		return rm.route
+	}
+	return ""
+}
+
+// SetRoute assigns the metric to the route with the given name. Metrics not
+// created by this package cannot be routed and are left untouched.
+func SetRoute(m telegraf.Metric, route string) {
+	if wm, ok := m.(telegraf.UnwrappableMetric); ok {
+		m = wm.Unwrap()
+	}
+	if rm, ok := m.(*metric); ok {
+		rm.route = route
+	}
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// BackoffConfig controls lengthening the effective gather interval of an
+// input on consecutive gather failures.
+type BackoffConfig struct {
+	// Upper limit of the effective gather interval
+	MaxInterval time.Duration
+	// Factor to increase the effective interval by for each failure
+	Multiplier float64
+}
+
+func (c *BackoffConfig) init() error {
+	if c.MaxInterval == 0 {
+		c.MaxInterval = 5 * time.Minute
+	}
+	if c.Multiplier == 0 {
+		c.Multiplier = 2
+	}
+
+	if c.MaxInterval < 0 {
+		return errors.New("backoff maximum interval must not be negative")
+	}
+	if c.Multiplier <= 1 {
+		return fmt.Errorf("invalid backoff multiplier %v, must be greater than 1", c.Multiplier)
+	}
+	return nil
+}
+
+// interval returns the effective gather interval after the given number of
+// consecutive failures. The interval is never shortened by the maximum.
+func (c *BackoffConfig) interval(interval time.Duration, failures int) time.Duration {
+	d := float64(interval) * math.Pow(c.Multiplier, float64(failures))
+	d = math.Min(d, float64(max(c.MaxInterval, interval)))
+	return time.Duration(d)
+}
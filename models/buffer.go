@@ -95,7 +95,7 @@ type BufferStats struct {
 }
 
 // NewBuffer returns a new empty Buffer with the given capacity.
-func NewBuffer(name, id, alias string, capacity int, strategy, path string) (Buffer, error) {
+func NewBuffer(name, id, alias string, capacity int, strategy, path string, diskCfg DiskBufferConfig) (Buffer, error) {
 	registerGob()
 
 	bs := NewBufferStats(name, alias, capacity)
@@ -104,17 +104,15 @@ func NewBuffer(name, id, alias string, capacity int, strategy, path string) (Buf
 	case "", "memory":
 		return NewMemoryBuffer(capacity, bs)
 	case "disk":
-		return NewDiskBuffer(name, id, path, bs)
+		return NewDiskBuffer(name, id, alias, path, diskCfg, bs)
+	case "hybrid":
+		return NewHybridBuffer(name, id, alias, capacity, path, diskCfg, bs)
 	}
 	return nil, fmt.Errorf("invalid buffer strategy %q", strategy)
 }
 
 func NewBufferStats(name, alias string, capacity int) BufferStats {
-	tags := map[string]string{"output": name}
-	if alias != "" {
-		tags["alias"] = alias
-	}
-
+	tags := bufferTags(name, alias)
 	bs := BufferStats{
 		MetricsAdded: selfstat.Register(
 			"write",
@@ -152,6 +150,15 @@ func NewBufferStats(name, alias string, capacity int) BufferStats {
 	return bs
 }
 
+// bufferTags returns the tags of the buffer statistics for the given output.
+func bufferTags(name, alias string) map[string]string {
+	tags := map[string]string{"output": name}
+	if alias != "" {
+		tags["alias"] = alias
+	}
+	return tags
+}
+
 func (b *BufferStats) metricAdded() {
 	b.MetricsAdded.Incr(1)
 }
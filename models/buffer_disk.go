@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"log"
@@ -13,14 +14,41 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
+// DiskBufferConfig contains the settings specific to the disk buffer.
+type DiskBufferConfig struct {
+	// MaxSize is the maximum size of the buffer on disk in bytes, zero means
+	// unlimited.
+	MaxSize int64
+
+	// EvictionPolicy determines the behavior when reaching the maximum size.
+	// With "drop_oldest", the default, the oldest metrics are removed to make
+	// room for new metrics, "reject_new" drops the new metrics instead.
+	EvictionPolicy string
+
+	// Compression is the algorithm to compress entries with, either "none"
+	// or "zstd".
+	Compression string
+}
+
 type DiskBuffer struct {
 	BufferStats
 	sync.Mutex
 
-	file *wal.Log
-	path string
+	// Number of bytes removed from disk due to the size limit
+	BytesEvicted selfstat.Stat
+	// Number of entries skipped or removed due to corruption
+	CorruptEntries selfstat.Stat
+
+	file  *wal.Log
+	path  string
+	codec *entryCodec
+
+	maxSize        int64
+	evictionPolicy string
+	size           int64 // Current size of the WAL files on disk, only tracked when limited
 
 	batchFirst uint64 // Index of the first metric in the batch
 	batchSize  uint64 // Number of metrics currently in the batch
@@ -38,11 +66,53 @@ type DiskBuffer struct {
 	// transaction. Metrics at those offsets should not be contained in new
 	// batches.
 	mask []int
+
+	// Offsets of the metrics in the current transaction and the number of
+	// metrics evicted from the front since the transaction started
+	batchOffsets []int
+	batchEvicted int
 }
 
-func NewDiskBuffer(name, id, path string, stats BufferStats) (*DiskBuffer, error) {
+func NewDiskBuffer(name, id, alias, path string, cfg DiskBufferConfig, stats BufferStats) (*DiskBuffer, error) {
+	switch cfg.EvictionPolicy {
+	case "":
+		cfg.EvictionPolicy = "drop_oldest"
+	case "drop_oldest", "reject_new":
+	default:
+		return nil, fmt.Errorf("invalid buffer eviction policy %q", cfg.EvictionPolicy)
+	}
+	if cfg.MaxSize < 0 {
+		return nil, fmt.Errorf("invalid maximum buffer size %d", cfg.MaxSize)
+	}
+	codec, err := newEntryCodec(cfg.Compression)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := bufferTags(name, alias)
+	bytesEvicted := selfstat.Register("write", "buffer_evicted_bytes", tags)
+	corruptEntries := selfstat.Register("write", "buffer_corrupt_entries", tags)
+
+	// Use smaller segments for limited buffers as only full segments can
+	// be evicted from disk
+	opts := *wal.DefaultOptions
+	if cfg.MaxSize > 0 && cfg.MaxSize/10 < int64(opts.SegmentSize) {
+		opts.SegmentSize = int(max(cfg.MaxSize/10, 1))
+	}
+
 	filePath := filepath.Join(path, id)
-	walFile, err := wal.Open(filePath, nil)
+	walFile, err := wal.Open(filePath, &opts)
+	if errors.Is(err, wal.ErrCorrupt) {
+		// Try to recover from a torn write, e.g. after a power loss
+		log.Printf("W! WAL file for plugin outputs.%s (%s) is corrupt, trying to repair", name, id)
+		removed, rerr := repairWAL(filePath)
+		if rerr != nil {
+			return nil, fmt.Errorf("failed to repair wal file: %w", rerr)
+		}
+		log.Printf("W! Removed %d bytes of incomplete data from WAL file for plugin outputs.%s (%s)", removed, name, id)
+		corruptEntries.Incr(1)
+		walFile, err = wal.Open(filePath, &opts)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to open wal file: %w", err)
 	}
@@ -54,13 +124,34 @@ func NewDiskBuffer(name, id, path string, stats BufferStats) (*DiskBuffer, error
 	}
 
 	buf := &DiskBuffer{
-		BufferStats: stats,
-		file:        walFile,
-		path:        filePath,
+		BufferStats:    stats,
+		BytesEvicted:   bytesEvicted,
+		CorruptEntries: corruptEntries,
+		file:           walFile,
+		path:           filePath,
+		codec:          codec,
+		maxSize:        cfg.MaxSize,
+		evictionPolicy: cfg.EvictionPolicy,
+	}
+	// Skip the placeholder entry of a buffer emptied before, see
+	// EndTransaction
+	if buf.entries() > 0 {
+		if entry, err := walFile.Read(buf.readIndex()); err == nil && len(entry) == 0 {
+			if buf.entries() == 1 {
+				buf.isEmpty = true
+			} else {
+				buf.mask = []int{1}
+			}
+		}
 	}
 	if buf.length() > 0 {
 		buf.originalEnd = buf.writeIndex()
 	}
+	if buf.maxSize > 0 {
+		if buf.size, err = buf.diskUsage(); err != nil {
+			return nil, err
+		}
+	}
 	return buf, nil
 }
 
@@ -120,18 +211,139 @@ func (b *DiskBuffer) Add(metrics ...telegraf.Metric) int {
 }
 
 func (b *DiskBuffer) addSingleMetric(m telegraf.Metric) bool {
-	data, err := metric.ToBytes(m)
+	serialized, err := metric.ToBytes(m)
+	if err != nil {
+		panic(err)
+	}
+	data, err := b.codec.encode(serialized)
 	if err != nil {
 		panic(err)
 	}
+
+	// Make sure the metric fits into the size limit of the buffer
+	size := int64(len(data) + binary.PutUvarint(make([]byte, binary.MaxVarintLen64), uint64(len(data))))
+	if b.maxSize > 0 && b.size+size > b.maxSize {
+		if b.evictionPolicy == "reject_new" || !b.evictOldest(size) {
+			b.metricDropped(m)
+			return false
+		}
+	}
+
 	err = b.file.Write(b.writeIndex(), data)
 	if err == nil {
+		b.size += size
 		b.metricAdded()
 		return true
 	}
 	return false
 }
 
+// evictOldest removes the oldest segments of the WAL file until the given
+// number of bytes fits into the size limit. False is returned if not enough
+// space can be freed.
+func (b *DiskBuffer) evictOldest(needed int64) bool {
+	if b.isEmpty {
+		return false
+	}
+
+	for b.size+needed > b.maxSize {
+		// The WAL segment files are named by the index of their first entry,
+		// so the oldest segment can be removed by truncating the front to
+		// the first index of the second segment. The last segment is never
+		// evicted as it contains the newest metrics.
+		segments, err := b.segments()
+		if err != nil {
+			log.Printf("E! Listing WAL segments of %q failed: %v", b.path, err)
+			return false
+		}
+		if len(segments) < 2 {
+			return false
+		}
+		b.evict(segments[1])
+
+		size, err := b.diskUsage()
+		if err != nil {
+			log.Printf("E! Determining size of %q failed: %v", b.path, err)
+			return false
+		}
+		b.BytesEvicted.Incr(b.size - size)
+		b.size = size
+	}
+	return true
+}
+
+// evict removes all entries in front of the given index from the WAL file and
+// accounts the contained metrics as dropped.
+func (b *DiskBuffer) evict(index uint64) {
+	first := b.readIndex()
+	n := int(index - first)
+
+	for i := 0; i < n; i++ {
+		// Skip metrics already removed and metrics of the current transaction
+		// as those are accounted when ending the transaction
+		offset := i + 1
+		if slices.Contains(b.mask, offset) || slices.Contains(b.batchOffsets, offset+b.batchEvicted) {
+			continue
+		}
+
+		m, err := b.read(first + uint64(i))
+		if err != nil {
+			AgentMetricsDropped.Incr(1)
+			b.MetricsDropped.Incr(1)
+			continue
+		}
+		b.metricDropped(m)
+	}
+
+	if err := b.file.TruncateFront(index); err != nil {
+		log.Printf("E! evicting up to index %d, first: %d", index, first)
+		panic(err)
+	}
+	log.Printf("W! Buffer size limit of %d bytes reached for %q, evicted %d metrics", b.maxSize, b.path, n)
+
+	// Update the offsets relative to the new front of the WAL file
+	mask := b.mask[:0]
+	for _, offset := range b.mask {
+		if offset > n {
+			mask = append(mask, offset-n)
+		}
+	}
+	b.mask = mask
+	if b.batchOffsets != nil {
+		b.batchFirst = index
+		b.batchEvicted += n
+	}
+
+	// check if the original end index is still valid, clear if not
+	if b.originalEnd < b.readIndex() {
+		b.originalEnd = 0
+	}
+}
+
+// segments returns the first indices of the WAL segment files in ascending
+// order.
+func (b *DiskBuffer) segments() ([]uint64, error) {
+	return walSegments(b.path)
+}
+
+// diskUsage returns the size of the WAL files on disk in bytes.
+func (b *DiskBuffer) diskUsage() (int64, error) {
+	return DiskBufferSize(b.path)
+}
+
+// updateSize refreshes the tracked disk usage after truncating the WAL file.
+func (b *DiskBuffer) updateSize() {
+	if b.maxSize <= 0 {
+		return
+	}
+	size, err := b.diskUsage()
+	if err != nil {
+		log.Printf("E! Determining size of %q failed: %v", b.path, err)
+		return
+	}
+	b.size = size
+}
+
 func (b *DiskBuffer) BeginTransaction(batchSize int) *Transaction {
 	b.Lock()
 	defer b.Unlock()
@@ -141,6 +353,7 @@ func (b *DiskBuffer) BeginTransaction(batchSize int) *Transaction {
 	}
 	b.batchFirst = b.readIndex()
 	b.batchSize = 0
+	b.batchEvicted = 0
 
 	metrics := make([]telegraf.Metric, 0, batchSize)
 	offsets := make([]int, 0, batchSize)
@@ -148,10 +361,7 @@ func (b *DiskBuffer) BeginTransaction(batchSize int) *Transaction {
 	endIndex := b.writeIndex()
 	offset := 0
 	for batchSize > 0 && readIndex < endIndex {
-		data, err := b.file.Read(readIndex)
-		if err != nil {
-			panic(err)
-		}
+		index := readIndex
 		readIndex++
 		offset++
 
@@ -168,15 +378,18 @@ func (b *DiskBuffer) BeginTransaction(batchSize int) *Transaction {
 		// - ErrSkipTracking:  means that the tracking information was unable to be found for a tracking ID.
 		// - Outside of range: means that the metric was guaranteed to be left over from the previous instance
 		//                     as it was here when we opened the wal file in this instance.
-		m, err := metric.FromBytes(data)
+		m, err := b.read(index)
 		if err != nil {
 			if errors.Is(err, metric.ErrSkipTracking) {
 				// could not look up tracking information for metric, skip
 				continue
 			}
-			// non-recoverable error in deserialization, abort
-			log.Printf("E! raw metric data: %v", data)
-			panic(err)
+			// Skip corrupt entries and schedule them for removal
+			log.Printf("W! Skipping corrupt entry %d of WAL file %q: %v", index, b.path, err)
+			b.CorruptEntries.Incr(1)
+			b.mask = append(b.mask, offset)
+			sort.Ints(b.mask)
+			continue
 		}
 		if _, ok := m.(telegraf.TrackingMetric); ok && readIndex < b.originalEnd {
 			// tracking metric left over from previous instance, skip
@@ -188,9 +401,30 @@ func (b *DiskBuffer) BeginTransaction(batchSize int) *Transaction {
 		b.batchSize++
 		batchSize--
 	}
+	b.batchOffsets = offsets
 	return &Transaction{Batch: metrics, valid: true, state: offsets}
 }
 
+// read reads the entry at the given index and deserializes the metric.
+func (b *DiskBuffer) read(index uint64) (telegraf.Metric, error) {
+	entry, err := b.file.Read(index)
+	if err != nil {
+		if errors.Is(err, wal.ErrCorrupt) {
+			return nil, fmt.Errorf("%w: %w", errCorruptEntry, err)
+		}
+		panic(err)
+	}
+	data, err := b.codec.decode(entry)
+	if err != nil {
+		return nil, err
+	}
+	m, err := metric.FromBytes(data)
+	if err != nil && !errors.Is(err, metric.ErrSkipTracking) {
+		return nil, fmt.Errorf("%w: %w", errCorruptEntry, err)
+	}
+	return m, err
+}
+
 func (b *DiskBuffer) EndTransaction(tx *Transaction) {
 	if len(tx.Batch) == 0 {
 		return
@@ -208,56 +442,80 @@ func (b *DiskBuffer) EndTransaction(tx *Transaction) {
 	b.Lock()
 	defer b.Unlock()
 
+	// Metrics might have been evicted from the front of the WAL file during
+	// the transaction, so the offsets need to be corrected
+	evicted := b.batchEvicted
+	b.batchOffsets = nil
+	b.batchEvicted = 0
+
 	// Mark metrics which should be removed in the internal mask
 	remove := make([]int, 0, len(tx.Accept)+len(tx.Reject))
 	for _, idx := range tx.Accept {
 		b.metricWritten(tx.Batch[idx])
-		remove = append(remove, offsets[idx])
+		if offset := offsets[idx] - evicted; offset > 0 {
+			remove = append(remove, offset)
+		}
 	}
 	for _, idx := range tx.Reject {
 		b.metricRejected(tx.Batch[idx])
-		remove = append(remove, offsets[idx])
+		if offset := offsets[idx] - evicted; offset > 0 {
+			remove = append(remove, offset)
+		}
+	}
+	// Metrics to keep are lost if they were evicted in the meantime
+	if evicted > 0 {
+		for _, idx := range tx.InferKeep() {
+			if offsets[idx] <= evicted {
+				b.metricDropped(tx.Batch[idx])
+			}
+		}
 	}
 	b.mask = append(b.mask, remove...)
 	sort.Ints(b.mask)
 
 	// Remove the metrics that are marked for removal from the front of the
 	// WAL file. All other metrics must be kept.
-	if len(b.mask) == 0 || b.mask[0] != 0 {
-		// Mask is empty or the first index is not the front of the file, so
+	if len(b.mask) == 0 || b.mask[0] != 1 {
+		// Mask is empty or the first offset is not the front of the file, so
 		// exit early as there is nothing to remove
 		return
 	}
 
-	// Determine up to which index we can remove the entries from the WAL file
-	var removeIdx int
+	// Determine the number of entries we can remove from the front of the
+	// WAL file
+	var removeCount int
 	for i, offset := range b.mask {
-		if offset != i {
+		if offset != i+1 {
 			break
 		}
-		removeIdx = offset
+		removeCount = offset
 	}
 
 	// Remove the metrics in front from the WAL file
-	b.isEmpty = b.entries()-removeIdx-1 <= 0
+	b.isEmpty = b.entries()-removeCount <= 0
 	if b.isEmpty {
 		// WAL files cannot be fully empty but need to contain at least one
-		// item to not throw an error
-		if err := b.file.TruncateFront(b.writeIndex()); err != nil {
+		// item to not throw an error. Use an empty placeholder entry to
+		// recognize the buffer as empty when reading the files later.
+		if err := b.file.Write(b.writeIndex(), nil); err != nil {
+			panic(err)
+		}
+		if err := b.file.TruncateFront(b.writeIndex() - 1); err != nil {
 			log.Printf("E! batch length: %d, first: %d, size: %d", len(tx.Batch), b.batchFirst, b.batchSize)
 			panic(err)
 		}
+		b.mask = nil
 	} else {
-		if err := b.file.TruncateFront(b.batchFirst + uint64(removeIdx+1)); err != nil {
+		if err := b.file.TruncateFront(b.readIndex() + uint64(removeCount)); err != nil {
 			log.Printf("E! batch length: %d, first: %d, size: %d", len(tx.Batch), b.batchFirst, b.batchSize)
 			panic(err)
 		}
-	}
 
-	// Truncate the mask and update the relative offsets
-	b.mask = b.mask[:removeIdx]
-	for i := range b.mask {
-		b.mask[i] -= removeIdx
+		// Truncate the mask and update the relative offsets
+		b.mask = b.mask[removeCount:]
+		for i := range b.mask {
+			b.mask[i] -= removeCount
+		}
 	}
 
 	// check if the original end index is still valid, clear if not
@@ -266,6 +524,7 @@ func (b *DiskBuffer) EndTransaction(tx *Transaction) {
 	}
 
 	b.resetBatch()
+	b.updateSize()
 	b.BufferSize.Set(int64(b.length()))
 }
 
@@ -295,4 +554,5 @@ func (b *DiskBuffer) handleEmptyFile() {
 		panic(err)
 	}
 	b.isEmpty = false
+	b.updateSize()
 }
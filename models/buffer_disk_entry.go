@@ -0,0 +1,181 @@
+package models
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+// Entries of the disk buffer start with a header consisting of a marker byte,
+// a flags byte and the CRC32 checksum of the payload to detect corruption.
+// Entries written by older versions contain the plain serialized metric. As a
+// serialized metric never starts with a zero byte, the marker distinguishes
+// both formats.
+const (
+	entryMarker     byte = 0x00
+	entryHeaderSize      = 6
+
+	entryFlagZstd byte = 1 << 0
+)
+
+var (
+	errCorruptEntry = errors.New("corrupt buffer entry")
+
+	entryCRCTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// entryCodec encodes and decodes the entries of the disk buffer.
+type entryCodec struct {
+	encoder internal.ContentEncoder
+	decoder internal.ContentDecoder
+}
+
+func newEntryCodec(compression string) (*entryCodec, error) {
+	var c entryCodec
+	switch compression {
+	case "", "none":
+	case "zstd":
+		encoder, err := internal.NewContentEncoder("zstd")
+		if err != nil {
+			return nil, fmt.Errorf("creating encoder failed: %w", err)
+		}
+		c.encoder = encoder
+	default:
+		return nil, fmt.Errorf("invalid buffer compression %q", compression)
+	}
+	return &c, nil
+}
+
+// encode compresses the given serialized metric, if enabled, and prepends the
+// entry header.
+func (c *entryCodec) encode(data []byte) ([]byte, error) {
+	var flags byte
+	if c.encoder != nil {
+		compressed, err := c.encoder.Encode(data)
+		if err != nil {
+			return nil, fmt.Errorf("compressing entry failed: %w", err)
+		}
+		data = compressed
+		flags |= entryFlagZstd
+	}
+
+	entry := make([]byte, entryHeaderSize, entryHeaderSize+len(data))
+	entry[0] = entryMarker
+	entry[1] = flags
+	binary.BigEndian.PutUint32(entry[2:], crc32.Checksum(data, entryCRCTable))
+	return append(entry, data...), nil
+}
+
+// decode verifies the given entry and returns the serialized metric. Errors
+// wrap errCorruptEntry if the entry is corrupt.
+func (c *entryCodec) decode(entry []byte) ([]byte, error) {
+	if len(entry) == 0 {
+		return nil, fmt.Errorf("%w: empty entry", errCorruptEntry)
+	}
+
+	// Entries of older versions do not have a header
+	if entry[0] != entryMarker {
+		return entry, nil
+	}
+	if len(entry) < entryHeaderSize {
+		return nil, fmt.Errorf("%w: truncated header", errCorruptEntry)
+	}
+
+	flags := entry[1]
+	payload := entry[entryHeaderSize:]
+	if crc32.Checksum(payload, entryCRCTable) != binary.BigEndian.Uint32(entry[2:entryHeaderSize]) {
+		return nil, fmt.Errorf("%w: checksum mismatch", errCorruptEntry)
+	}
+	if flags&^entryFlagZstd != 0 {
+		return nil, fmt.Errorf("%w: unknown flags %#x", errCorruptEntry, flags)
+	}
+
+	if flags&entryFlagZstd == 0 {
+		return payload, nil
+	}
+
+	// Compressed entries can exist even if compression is disabled now
+	if c.decoder == nil {
+		decoder, err := internal.NewContentDecoder("zstd")
+		if err != nil {
+			return nil, fmt.Errorf("creating decoder failed: %w", err)
+		}
+		c.decoder = decoder
+	}
+	data, err := c.decoder.Decode(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: decompressing failed: %w", errCorruptEntry, err)
+	}
+	return data, nil
+}
+
+// repairWAL truncates the last segment of the WAL file at the given path after
+// the last complete entry to recover from a torn write. The number of removed
+// bytes is returned.
+func repairWAL(path string) (int64, error) {
+	segments, err := walSegments(path)
+	if err != nil {
+		return 0, err
+	}
+	if len(segments) == 0 {
+		return 0, errors.New("no segment found")
+	}
+
+	last := segmentName(segments[len(segments)-1])
+	filename := filepath.Join(path, last)
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return 0, err
+	}
+
+	// Skip all complete entries consisting of the data size and the data
+	var pos int
+	for pos < len(data) {
+		size, n := binary.Uvarint(data[pos:])
+		if n <= 0 || uint64(len(data)-pos-n) < size {
+			break
+		}
+		pos += n + int(size)
+	}
+	if pos == len(data) {
+		return 0, fmt.Errorf("no corruption found in last segment %q", last)
+	}
+
+	if err := os.Truncate(filename, int64(pos)); err != nil {
+		return 0, err
+	}
+	return int64(len(data) - pos), nil
+}
+
+// walSegments returns the first indices of the segment files of the WAL file
+// at the given path in ascending order.
+func walSegments(path string) ([]uint64, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := make([]uint64, 0, len(entries))
+	for _, entry := range entries {
+		index, err := strconv.ParseUint(entry.Name(), 10, 64)
+		if err != nil || entry.IsDir() {
+			continue
+		}
+		segments = append(segments, index)
+	}
+	slices.Sort(segments)
+	return segments, nil
+}
+
+// segmentName returns the file name of the WAL segment starting at the given
+// index.
+func segmentName(index uint64) string {
+	return fmt.Sprintf("%020d", index)
+}
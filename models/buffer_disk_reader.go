@@ -0,0 +1,94 @@
+package models
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// ReadDiskBuffer reads the metrics stored in the disk buffer at the given path
+// in order and calls fn for each metric. The buffer files are not modified,
+// so the buffer of a stopped agent can be inspected without losing data.
+// Tracking information is not restored. Corrupt entries are skipped and their
+// number is returned. Reading stops at the first error returned by fn.
+//
+// Metrics are only removed from the files once all older metrics are written,
+// so metrics written while older ones were kept in the buffer are reported,
+// just as they are written again by the agent after a restart.
+func ReadDiskBuffer(path string, fn func(telegraf.Metric) error) (int, error) {
+	registerGob()
+
+	codec, err := newEntryCodec("")
+	if err != nil {
+		return 0, err
+	}
+
+	segments, err := walSegments(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var corrupt int
+	for _, segment := range segments {
+		data, err := os.ReadFile(filepath.Join(path, segmentName(segment)))
+		if err != nil {
+			return corrupt, err
+		}
+
+		// Entries consist of the data size and the data
+		for pos := 0; pos < len(data); {
+			size, n := binary.Uvarint(data[pos:])
+			if n <= 0 || uint64(len(data)-pos-n) < size {
+				// Incomplete entry at the end of the segment
+				corrupt++
+				break
+			}
+			entry := data[pos+n : pos+n+int(size)]
+			pos += n + int(size)
+
+			// Skip the placeholder entry of an empty buffer
+			if size == 0 {
+				continue
+			}
+
+			serialized, err := codec.decode(entry)
+			if err != nil {
+				corrupt++
+				continue
+			}
+			m, err := metric.FromBytesUntracked(serialized)
+			if err != nil {
+				corrupt++
+				continue
+			}
+			if err := fn(m); err != nil {
+				return corrupt, err
+			}
+		}
+	}
+	return corrupt, nil
+}
+
+// DiskBufferSize returns the size of the files of the disk buffer at the given
+// path in bytes.
+func DiskBufferSize(path string) (int64, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return 0, err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+	}
+	return size, nil
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestReadDiskBuffer(t *testing.T) {
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(1, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(2, 0)),
+	}
+	tm, _ := metric.WithTracking(
+		metric.New("mem", map[string]string{}, map[string]interface{}{"value": 3}, time.Unix(3, 0)),
+		func(telegraf.DeliveryInfo) {},
+	)
+
+	path := t.TempDir()
+	buf, err := NewBuffer("test", "123", "", 0, "disk", path, DiskBufferConfig{Compression: "zstd"})
+	require.NoError(t, err)
+	buf.Add(metrics...)
+	buf.Add(tm)
+	require.NoError(t, buf.Close())
+
+	// Simulate an incomplete write at the end of the segment
+	filename := filepath.Join(path, "123", "00000000000000000001")
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0640)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{100, 0x00, 0x01, 0x02})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	before, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	// Tracking metrics must be read without their tracking information
+	var actual []telegraf.Metric
+	corrupt, err := ReadDiskBuffer(filepath.Join(path, "123"), func(m telegraf.Metric) error {
+		actual = append(actual, m)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, corrupt)
+	expected := append(metrics, metric.New("mem", map[string]string{}, map[string]interface{}{"value": 3}, time.Unix(3, 0)))
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// The buffer files must not be modified
+	after, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, before, after)
+
+	// Errors of the callback must stop reading
+	var count int
+	_, err = ReadDiskBuffer(filepath.Join(path, "123"), func(telegraf.Metric) error {
+		count++
+		return errors.New("stop")
+	})
+	require.ErrorContains(t, err, "stop")
+	require.Equal(t, 1, count)
+}
+
+func TestReadDiskBufferEmpty(t *testing.T) {
+	path := t.TempDir()
+	buf, err := NewBuffer("test", "123", "", 0, "disk", path, DiskBufferConfig{})
+	require.NoError(t, err)
+	buf.Add(metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(1, 0)))
+
+	tx := buf.BeginTransaction(10)
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+	require.NoError(t, buf.Close())
+
+	// Metrics already written must not be reported
+	var count int
+	corrupt, err := ReadDiskBuffer(filepath.Join(path, "123"), func(telegraf.Metric) error {
+		count++
+		return nil
+	})
+	require.NoError(t, err)
+	require.Zero(t, corrupt)
+	require.Zero(t, count)
+}
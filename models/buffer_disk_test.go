@@ -1,6 +1,7 @@
 package models
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 	"time"
@@ -19,7 +20,7 @@ func TestDiskBufferRetainsTrackingInformation(t *testing.T) {
 	var delivered int
 	mm, _ := metric.WithTracking(m, func(telegraf.DeliveryInfo) { delivered++ })
 
-	buf, err := NewBuffer("test", "123", "", 0, "disk", t.TempDir())
+	buf, err := NewBuffer("test", "123", "", 0, "disk", t.TempDir(), DiskBufferConfig{})
 	require.NoError(t, err)
 	buf.Stats().MetricsAdded.Set(0)
 	buf.Stats().MetricsWritten.Set(0)
@@ -78,7 +79,7 @@ func TestDiskBufferTrackingDroppedFromOldWal(t *testing.T) {
 	walfile.Close()
 
 	// Create a buffer
-	buf, err := NewBuffer("123", "123", "", 0, "disk", path)
+	buf, err := NewBuffer("123", "123", "", 0, "disk", path, DiskBufferConfig{})
 	require.NoError(t, err)
 	buf.Stats().MetricsAdded.Set(0)
 	buf.Stats().MetricsWritten.Set(0)
@@ -93,3 +94,229 @@ func TestDiskBufferTrackingDroppedFromOldWal(t *testing.T) {
 	}
 	testutil.RequireMetricsEqual(t, expected, tx.Batch)
 }
+
+func TestDiskBufferMaxSizeDropOldest(t *testing.T) {
+	buf, err := NewBuffer("test", "123", "", 0, "disk", t.TempDir(), DiskBufferConfig{MaxSize: 16 * 1024})
+	require.NoError(t, err)
+	defer buf.Close()
+	db := buf.(*DiskBuffer)
+	db.MetricsDropped.Set(0)
+	db.BytesEvicted.Set(0)
+
+	for i := 0; i < 500; i++ {
+		m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": i}, time.Unix(int64(i), 0))
+		require.Zero(t, buf.Add(m))
+	}
+
+	// The oldest metrics must be evicted to keep the size limit
+	require.Less(t, buf.Len(), 500)
+	require.EqualValues(t, 500-buf.Len(), db.MetricsDropped.Get())
+	require.Positive(t, db.BytesEvicted.Get())
+	size, err := db.diskUsage()
+	require.NoError(t, err)
+	require.LessOrEqual(t, size, int64(16*1024))
+
+	tx := buf.BeginTransaction(buf.Len())
+	require.Len(t, tx.Batch, buf.Len())
+	first, found := tx.Batch[0].GetField("value")
+	require.True(t, found)
+	require.Positive(t, first)
+	last, found := tx.Batch[len(tx.Batch)-1].GetField("value")
+	require.True(t, found)
+	require.Equal(t, int64(499), last)
+}
+
+func TestDiskBufferMaxSizeRejectNew(t *testing.T) {
+	cfg := DiskBufferConfig{MaxSize: 16 * 1024, EvictionPolicy: "reject_new"}
+	buf, err := NewBuffer("test", "123", "", 0, "disk", t.TempDir(), cfg)
+	require.NoError(t, err)
+	defer buf.Close()
+
+	var dropped int
+	for i := 0; i < 500; i++ {
+		m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": i}, time.Unix(int64(i), 0))
+		dropped += buf.Add(m)
+	}
+
+	// The newest metrics must be rejected to keep the size limit
+	require.Positive(t, dropped)
+	require.Equal(t, 500-dropped, buf.Len())
+
+	tx := buf.BeginTransaction(buf.Len())
+	first, found := tx.Batch[0].GetField("value")
+	require.True(t, found)
+	require.Equal(t, int64(0), first)
+}
+
+func TestDiskBufferMaxSizeEvictDuringTransaction(t *testing.T) {
+	buf, err := NewBuffer("test", "123", "", 0, "disk", t.TempDir(), DiskBufferConfig{MaxSize: 16 * 1024})
+	require.NoError(t, err)
+	defer buf.Close()
+
+	for i := 0; i < 10; i++ {
+		m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": i}, time.Unix(int64(i), 0))
+		buf.Add(m)
+	}
+	tx := buf.BeginTransaction(10)
+	require.Len(t, tx.Batch, 10)
+
+	// Evict the metrics of the running transaction
+	for i := 10; i < 500; i++ {
+		m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": i}, time.Unix(int64(i), 0))
+		buf.Add(m)
+	}
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+
+	// The remaining metrics must not be affected by the transaction
+	n := buf.Len()
+	tx = buf.BeginTransaction(n)
+	require.Len(t, tx.Batch, n)
+	for _, m := range tx.Batch {
+		v, found := m.GetField("value")
+		require.True(t, found)
+		require.GreaterOrEqual(t, v, int64(10))
+	}
+}
+
+func TestDiskBufferInvalidEvictionPolicy(t *testing.T) {
+	_, err := NewBuffer("test", "123", "", 0, "disk", t.TempDir(), DiskBufferConfig{EvictionPolicy: "foo"})
+	require.ErrorContains(t, err, "invalid buffer eviction policy")
+}
+
+func TestDiskBufferCompression(t *testing.T) {
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": "foo"}, time.Unix(1, 0)),
+	}
+
+	path := t.TempDir()
+	buf, err := NewBuffer("test", "123", "", 0, "disk", path, DiskBufferConfig{Compression: "zstd"})
+	require.NoError(t, err)
+	buf.Add(metrics...)
+	require.NoError(t, buf.Close())
+
+	// Entries must be readable after reopening the buffer, even with
+	// compression disabled
+	buf, err = NewBuffer("test", "123", "", 0, "disk", path, DiskBufferConfig{})
+	require.NoError(t, err)
+	defer buf.Close()
+
+	tx := buf.BeginTransaction(2)
+	testutil.RequireMetricsEqual(t, metrics, tx.Batch)
+}
+
+func TestDiskBufferInvalidCompression(t *testing.T) {
+	_, err := NewBuffer("test", "123", "", 0, "disk", t.TempDir(), DiskBufferConfig{Compression: "foo"})
+	require.ErrorContains(t, err, "invalid buffer compression")
+}
+
+func TestDiskBufferSkipsCorruptEntries(t *testing.T) {
+	registerGob()
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(1, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(2, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 3}, time.Unix(3, 0)),
+	}
+
+	// Prefill the WAL file and flip a bit in the payload of the second entry
+	codec, err := newEntryCodec("none")
+	require.NoError(t, err)
+	path := t.TempDir()
+	walfile, err := wal.Open(filepath.Join(path, "123"), nil)
+	require.NoError(t, err)
+	for i, m := range metrics {
+		data, err := metric.ToBytes(m)
+		require.NoError(t, err)
+		entry, err := codec.encode(data)
+		require.NoError(t, err)
+		if i == 1 {
+			entry[len(entry)-1] ^= 0x01
+		}
+		require.NoError(t, walfile.Write(uint64(i+1), entry))
+	}
+	require.NoError(t, walfile.Close())
+
+	buf, err := NewBuffer("test", "123", "", 0, "disk", path, DiskBufferConfig{})
+	require.NoError(t, err)
+	defer buf.Close()
+	db := buf.(*DiskBuffer)
+	db.CorruptEntries.Set(0)
+
+	tx := buf.BeginTransaction(3)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{metrics[0], metrics[2]}, tx.Batch)
+	require.Equal(t, int64(1), db.CorruptEntries.Get())
+	require.Equal(t, 2, buf.Len())
+}
+
+func TestDiskBufferRepairsTornWrite(t *testing.T) {
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(1, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(2, 0)),
+	}
+
+	path := t.TempDir()
+	buf, err := NewBuffer("test", "123", "", 0, "disk", path, DiskBufferConfig{})
+	require.NoError(t, err)
+	buf.Add(metrics...)
+	require.NoError(t, buf.Close())
+
+	// Simulate an incomplete write at the end of the segment
+	f, err := os.OpenFile(filepath.Join(path, "123", "00000000000000000001"), os.O_APPEND|os.O_WRONLY, 0640)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{100, 0x00, 0x01, 0x02})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	buf, err = NewBuffer("test", "123", "", 0, "disk", path, DiskBufferConfig{})
+	require.NoError(t, err)
+	defer buf.Close()
+
+	require.Equal(t, 2, buf.Len())
+	tx := buf.BeginTransaction(2)
+	testutil.RequireMetricsEqual(t, metrics, tx.Batch)
+}
+
+func TestDiskBufferRemovesWrittenMetricsFromDisk(t *testing.T) {
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(1, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(2, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 3}, time.Unix(3, 0)),
+	}
+
+	path := t.TempDir()
+	buf, err := NewBuffer("test", "123", "", 0, "disk", path, DiskBufferConfig{})
+	require.NoError(t, err)
+	buf.Add(metrics...)
+
+	// Write the first two metrics, the remaining one must survive a restart
+	tx := buf.BeginTransaction(2)
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+	require.NoError(t, buf.Close())
+
+	buf, err = NewBuffer("test", "123", "", 0, "disk", path, DiskBufferConfig{})
+	require.NoError(t, err)
+	require.Equal(t, 1, buf.Len())
+	tx = buf.BeginTransaction(10)
+	testutil.RequireMetricsEqual(t, metrics[2:], tx.Batch)
+
+	// Drain the buffer, it must be empty after a restart
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+	require.Zero(t, buf.Len())
+	require.NoError(t, buf.Close())
+
+	buf, err = NewBuffer("test", "123", "", 0, "disk", path, DiskBufferConfig{})
+	require.NoError(t, err)
+	defer buf.Close()
+	require.Zero(t, buf.Len())
+	require.Empty(t, buf.BeginTransaction(10).Batch)
+
+	// Adding metrics must replace the placeholder of the empty buffer
+	buf.Add(metrics[0])
+	require.Equal(t, 1, buf.Len())
+	tx = buf.BeginTransaction(10)
+	testutil.RequireMetricsEqual(t, metrics[:1], tx.Batch)
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"sync"
+
+	"github.com/influxdata/telegraf"
+)
+
+// HybridBuffer stores metrics in memory up to its capacity and spills the
+// overflow to disk. Metrics are kept in order, i.e. the oldest metrics are
+// held in memory and new metrics are written to disk as long as the disk
+// contains metrics.
+type HybridBuffer struct {
+	sync.Mutex
+	BufferStats
+
+	mem  *MemoryBuffer
+	disk *DiskBuffer
+	cap  int
+}
+
+// hybridTransaction holds the transactions of the memory and disk parts
+// of a batch.
+type hybridTransaction struct {
+	mem  *Transaction
+	disk *Transaction
+}
+
+func NewHybridBuffer(name, id, alias string, capacity int, path string, cfg DiskBufferConfig, stats BufferStats) (*HybridBuffer, error) {
+	mem, err := NewMemoryBuffer(capacity, stats)
+	if err != nil {
+		return nil, err
+	}
+	disk, err := NewDiskBuffer(name, id, alias, path, cfg, stats)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &HybridBuffer{
+		BufferStats: stats,
+		mem:         mem,
+		disk:        disk,
+		cap:         capacity,
+	}
+	buf.BufferSize.Set(int64(buf.length()))
+	return buf, nil
+}
+
+func (b *HybridBuffer) Len() int {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.length()
+}
+
+func (b *HybridBuffer) length() int {
+	return b.mem.Len() + b.disk.Len()
+}
+
+func (b *HybridBuffer) Add(metrics ...telegraf.Metric) int {
+	b.Lock()
+	defer b.Unlock()
+
+	dropped := 0
+	for _, m := range metrics {
+		// Only use the memory if it has room, including the metrics of a
+		// running transaction, and no newer metrics were spilled to disk.
+		if b.disk.Len() == 0 && b.mem.Len() < b.cap {
+			dropped += b.mem.Add(m)
+		} else {
+			dropped += b.disk.Add(m)
+		}
+	}
+
+	b.BufferSize.Set(int64(b.length()))
+	return dropped
+}
+
+func (b *HybridBuffer) BeginTransaction(batchSize int) *Transaction {
+	b.Lock()
+	defer b.Unlock()
+
+	// Take the oldest metrics from memory and fill up the batch from disk
+	memTx := b.mem.BeginTransaction(batchSize)
+	diskTx := &Transaction{}
+	if n := batchSize - len(memTx.Batch); n > 0 {
+		diskTx = b.disk.BeginTransaction(n)
+	}
+	if len(memTx.Batch)+len(diskTx.Batch) == 0 {
+		return &Transaction{}
+	}
+
+	batch := make([]telegraf.Metric, 0, len(memTx.Batch)+len(diskTx.Batch))
+	batch = append(batch, memTx.Batch...)
+	batch = append(batch, diskTx.Batch...)
+	return &Transaction{
+		Batch: batch,
+		valid: true,
+		state: &hybridTransaction{mem: memTx, disk: diskTx},
+	}
+}
+
+func (b *HybridBuffer) EndTransaction(tx *Transaction) {
+	// Ignore invalid transactions and make sure they can only be finished once
+	if !tx.valid {
+		return
+	}
+	tx.valid = false
+
+	// Split the accepted and rejected metrics between memory and disk
+	state := tx.state.(*hybridTransaction)
+	n := len(state.mem.Batch)
+	for _, idx := range tx.Accept {
+		if idx < n {
+			state.mem.Accept = append(state.mem.Accept, idx)
+		} else {
+			state.disk.Accept = append(state.disk.Accept, idx-n)
+		}
+	}
+	for _, idx := range tx.Reject {
+		if idx < n {
+			state.mem.Reject = append(state.mem.Reject, idx)
+		} else {
+			state.disk.Reject = append(state.disk.Reject, idx-n)
+		}
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	b.mem.EndTransaction(state.mem)
+	b.disk.EndTransaction(state.disk)
+	b.BufferSize.Set(int64(b.length()))
+}
+
+func (b *HybridBuffer) Stats() BufferStats {
+	return b.BufferStats
+}
+
+func (b *HybridBuffer) Close() error {
+	return b.disk.Close()
+}
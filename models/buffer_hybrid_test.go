@@ -0,0 +1,81 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestHybridBufferSpillsToDisk(t *testing.T) {
+	buf, err := NewBuffer("test", "123", "", 2, "hybrid", t.TempDir(), DiskBufferConfig{})
+	require.NoError(t, err)
+	defer buf.Close()
+	hb := buf.(*HybridBuffer)
+
+	metrics := make([]telegraf.Metric, 0, 5)
+	for i := 0; i < 5; i++ {
+		metrics = append(metrics, metric.New("cpu", map[string]string{}, map[string]interface{}{"value": i}, time.Unix(int64(i), 0)))
+	}
+	require.Zero(t, buf.Add(metrics...))
+	require.Equal(t, 5, buf.Len())
+	require.Equal(t, 2, hb.mem.Len())
+	require.Equal(t, 3, hb.disk.Len())
+
+	// Batches must span memory and disk in order
+	tx := buf.BeginTransaction(3)
+	testutil.RequireMetricsEqual(t, metrics[:3], tx.Batch)
+
+	// Keep the metric from disk and accept the ones from memory
+	tx.Accept = []int{0, 1}
+	buf.EndTransaction(tx)
+	require.Equal(t, 3, buf.Len())
+	require.Zero(t, hb.mem.Len())
+
+	// New metrics must be added to disk while it contains older metrics
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 5}, time.Unix(5, 0))
+	buf.Add(m)
+	require.Zero(t, hb.mem.Len())
+	require.Equal(t, 4, hb.disk.Len())
+
+	tx = buf.BeginTransaction(10)
+	testutil.RequireMetricsEqual(t, append(metrics[2:], m), tx.Batch)
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+	require.Zero(t, buf.Len())
+
+	// Memory must be used again after draining the disk
+	buf.Add(m)
+	require.Equal(t, 1, hb.mem.Len())
+	require.Zero(t, hb.disk.Len())
+}
+
+func TestHybridBufferKeepRestoresMemory(t *testing.T) {
+	buf, err := NewBuffer("test", "123", "", 2, "hybrid", t.TempDir(), DiskBufferConfig{})
+	require.NoError(t, err)
+	defer buf.Close()
+	buf.Stats().MetricsDropped.Set(0)
+	hb := buf.(*HybridBuffer)
+
+	m1 := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(1, 0))
+	m2 := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(2, 0))
+	m3 := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 3}, time.Unix(3, 0))
+	buf.Add(m1, m2)
+	tx := buf.BeginTransaction(2)
+
+	// Metrics added during a transaction must not take the place of the
+	// metrics in the batch
+	buf.Add(m3)
+	require.Equal(t, 1, hb.disk.Len())
+
+	buf.EndTransaction(tx)
+	require.Equal(t, 3, buf.Len())
+	require.Zero(t, buf.Stats().MetricsDropped.Get())
+
+	tx = buf.BeginTransaction(3)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{m1, m2, m3}, tx.Batch)
+}
@@ -0,0 +1,251 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// Actions for metrics of new series exceeding the cardinality limit
+const (
+	CardinalityActionDrop      = "drop"
+	CardinalityActionAggregate = "aggregate"
+	CardinalityActionStrip     = "strip"
+)
+
+// CardinalityOverflowTag marks the series aggregating the metrics exceeding
+// the cardinality limit per measurement.
+const CardinalityOverflowTag = "cardinality_overflow"
+
+// CardinalityConfig limits the number of distinct series passing a plugin.
+type CardinalityConfig struct {
+	// Maximum number of distinct series seen within the window
+	MaxSeries int
+	// Duration after which a series not seen anymore is released
+	Window time.Duration
+	// Action for metrics of new series exceeding the limit
+	Action string
+	// Tags removed from metrics exceeding the limit for the strip action
+	StripTags []string
+}
+
+func (c *CardinalityConfig) init() error {
+	if c.Window == 0 {
+		c.Window = time.Hour
+	}
+	if c.Action == "" {
+		c.Action = CardinalityActionDrop
+	}
+
+	if c.MaxSeries <= 0 {
+		return errors.New("cardinality maximum series must be positive")
+	}
+	if c.Window < 0 {
+		return errors.New("cardinality window must not be negative")
+	}
+	switch c.Action {
+	case CardinalityActionDrop, CardinalityActionAggregate:
+	case CardinalityActionStrip:
+		if len(c.StripTags) == 0 {
+			return errors.New("cardinality strip action requires tags to strip")
+		}
+	default:
+		return fmt.Errorf("invalid cardinality action %q", c.Action)
+	}
+	return nil
+}
+
+// cardinalityLimiter tracks the distinct series of a plugin by the hash of
+// the metrics and applies the configured action to metrics of new series
+// once the limit is reached.
+type cardinalityLimiter struct {
+	cfg *CardinalityConfig
+	log telegraf.Logger
+
+	series      map[uint64]time.Time
+	lastCleanup time.Time
+	limited     bool
+
+	// Aggregates of the metrics exceeding the limit per measurement since
+	// the start of the current window
+	overflow      map[string]*overflowSeries
+	overflowStart time.Time
+
+	sync.Mutex
+
+	Series     selfstat.Stat
+	Violations selfstat.Stat
+}
+
+func newCardinalityLimiter(cfg *CardinalityConfig, tags map[string]string, log telegraf.Logger) *cardinalityLimiter {
+	return &cardinalityLimiter{
+		cfg:        cfg,
+		log:        log,
+		series:     make(map[uint64]time.Time),
+		overflow:   make(map[string]*overflowSeries),
+		Series:     selfstat.Register("cardinality", "series", tags),
+		Violations: selfstat.Register("cardinality", "violations", tags),
+	}
+}
+
+// apply returns true if the metric, possibly modified according to the
+// action, passes the limiter and false if the metric must be dropped. For the
+// aggregate action the dropped metrics are accounted in the overflow series.
+func (l *cardinalityLimiter) apply(m telegraf.Metric, now time.Time) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	l.cleanup(now)
+	if l.admit(m.HashID(), now) {
+		return true
+	}
+
+	l.Violations.Incr(1)
+	if !l.limited {
+		l.log.Warnf("Limit of %d series reached, applying action %q to metrics of new series", l.cfg.MaxSeries, l.cfg.Action)
+		l.limited = true
+	}
+
+	switch l.cfg.Action {
+	case CardinalityActionAggregate:
+		l.aggregate(m, now)
+	case CardinalityActionStrip:
+		for _, key := range l.cfg.StripTags {
+			m.RemoveTag(key)
+		}
+		return l.admit(m.HashID(), now)
+	}
+	return false
+}
+
+// aggregate adds the fields of the given metric to the overflow series of
+// its measurement.
+func (l *cardinalityLimiter) aggregate(m telegraf.Metric, now time.Time) {
+	if len(l.overflow) == 0 {
+		l.overflowStart = now
+	}
+
+	series, found := l.overflow[m.Name()]
+	if !found {
+		series = &overflowSeries{fields: make(map[string]*overflowField)}
+		l.overflow[m.Name()] = series
+	}
+	for _, field := range m.FieldList() {
+		f, found := series.fields[field.Key]
+		if !found {
+			f = &overflowField{}
+			series.fields[field.Key] = f
+		}
+		f.add(field.Value)
+	}
+}
+
+// overflowMetrics returns the overflow series once per window, or
+// immediately if forced, and resets the aggregates.
+func (l *cardinalityLimiter) overflowMetrics(now time.Time, force bool) []telegraf.Metric {
+	l.Lock()
+	defer l.Unlock()
+
+	if len(l.overflow) == 0 || !force && now.Sub(l.overflowStart) < l.cfg.Window {
+		return nil
+	}
+
+	names := make([]string, 0, len(l.overflow))
+	for name := range l.overflow {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	metrics := make([]telegraf.Metric, 0, len(names))
+	for _, name := range names {
+		series := l.overflow[name]
+		fields := make(map[string]interface{}, 3*len(series.fields))
+		for key, f := range series.fields {
+			fields[key+"_count"] = f.count
+			if f.numeric {
+				fields[key+"_sum"] = f.sum
+			}
+			fields[key+"_last"] = f.last
+		}
+		metrics = append(metrics, metric.New(name, map[string]string{CardinalityOverflowTag: "true"}, fields, now))
+	}
+	l.overflow = make(map[string]*overflowSeries)
+
+	return metrics
+}
+
+// admit tracks the series and returns true if it is already known or the
+// limit is not reached.
+func (l *cardinalityLimiter) admit(id uint64, now time.Time) bool {
+	if _, found := l.series[id]; !found && len(l.series) >= l.cfg.MaxSeries {
+		return false
+	}
+	l.series[id] = now
+	l.Series.Set(int64(len(l.series)))
+	return true
+}
+
+// cleanup releases the series not seen within the window. To limit the
+// overhead the series are checked at most every sixtieth of the window.
+func (l *cardinalityLimiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < l.cfg.Window/60 {
+		return
+	}
+	l.lastCleanup = now
+
+	for id, seen := range l.series {
+		if now.Sub(seen) >= l.cfg.Window {
+			delete(l.series, id)
+		}
+	}
+	l.Series.Set(int64(len(l.series)))
+
+	if l.limited && len(l.series) < l.cfg.MaxSeries {
+		l.log.Info("Number of series dropped below the limit")
+		l.limited = false
+	}
+}
+
+// overflowSeries aggregates the fields of the metrics of one measurement
+// exceeding the cardinality limit.
+type overflowSeries struct {
+	fields map[string]*overflowField
+}
+
+// overflowField holds the number of values, the sum of the numeric values and
+// the last value of a field.
+type overflowField struct {
+	count   int64
+	sum     float64
+	numeric bool
+	last    interface{}
+}
+
+func (f *overflowField) add(value interface{}) {
+	f.count++
+	f.last = value
+
+	switch v := value.(type) {
+	case int64:
+		f.sum += float64(v)
+	case uint64:
+		f.sum += float64(v)
+	case float64:
+		f.sum += v
+	default:
+		return
+	}
+	f.numeric = true
+}
+
+// cardinalityOverflow marks the overflow series passed to the accumulator of
+// an input to skip the processing already applied to the aggregated metrics.
+type cardinalityOverflow struct {
+	telegraf.Metric
+}
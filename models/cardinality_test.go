@@ -0,0 +1,252 @@
+package models
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newCardinalityMetric(tags map[string]string) telegraf.Metric {
+	return metric.New("cpu", tags, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+}
+
+func TestCardinalityLimiterDrop(t *testing.T) {
+	cfg := &CardinalityConfig{MaxSeries: 2}
+	require.NoError(t, cfg.init())
+	limiter := newCardinalityLimiter(cfg, map[string]string{"test": t.Name()}, testutil.Logger{})
+
+	now := time.Now()
+	for i := range 3 {
+		m := newCardinalityMetric(map[string]string{"id": strconv.Itoa(i)})
+		require.Equal(t, i < 2, limiter.apply(m, now))
+	}
+
+	// Known series still pass
+	require.True(t, limiter.apply(newCardinalityMetric(map[string]string{"id": "0"}), now))
+	require.Equal(t, int64(2), limiter.Series.Get())
+	require.Equal(t, int64(1), limiter.Violations.Get())
+}
+
+func TestCardinalityLimiterAggregate(t *testing.T) {
+	cfg := &CardinalityConfig{MaxSeries: 1, Window: time.Minute, Action: CardinalityActionAggregate}
+	require.NoError(t, cfg.init())
+	limiter := newCardinalityLimiter(cfg, map[string]string{"test": t.Name()}, testutil.Logger{})
+
+	now := time.Now()
+	require.True(t, limiter.apply(newCardinalityMetric(map[string]string{"id": "0"}), now))
+
+	// Metrics of new series are aggregated per measurement
+	for i := range 3 {
+		m := metric.New("cpu", map[string]string{"id": strconv.Itoa(i + 1)}, map[string]interface{}{"value": i, "state": "ok"}, now)
+		require.False(t, limiter.apply(m, now))
+	}
+	m := metric.New("mem", map[string]string{"id": "1"}, map[string]interface{}{"value": 1.5}, now)
+	require.False(t, limiter.apply(m, now))
+	require.Equal(t, int64(1), limiter.Series.Get())
+	require.Equal(t, int64(4), limiter.Violations.Get())
+
+	// The aggregates are emitted once per window
+	require.Empty(t, limiter.overflowMetrics(now.Add(30*time.Second), false))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{CardinalityOverflowTag: "true"},
+			map[string]interface{}{
+				"value_count": int64(3),
+				"value_sum":   float64(3),
+				"value_last":  int64(2),
+				"state_count": int64(3),
+				"state_last":  "ok",
+			},
+			now.Add(time.Minute),
+		),
+		metric.New(
+			"mem",
+			map[string]string{CardinalityOverflowTag: "true"},
+			map[string]interface{}{
+				"value_count": int64(1),
+				"value_sum":   float64(1.5),
+				"value_last":  float64(1.5),
+			},
+			now.Add(time.Minute),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, limiter.overflowMetrics(now.Add(time.Minute), false))
+	require.Empty(t, limiter.overflowMetrics(now.Add(time.Minute), true))
+
+	// Forcing emits the aggregates immediately
+	require.False(t, limiter.apply(newCardinalityMetric(map[string]string{"id": "1"}), now))
+	require.Len(t, limiter.overflowMetrics(now, true), 1)
+}
+
+func TestCardinalityLimiterStrip(t *testing.T) {
+	cfg := &CardinalityConfig{MaxSeries: 2, Action: CardinalityActionStrip, StripTags: []string{"id"}}
+	require.NoError(t, cfg.init())
+	limiter := newCardinalityLimiter(cfg, map[string]string{"test": t.Name()}, testutil.Logger{})
+
+	now := time.Now()
+	require.True(t, limiter.apply(newCardinalityMetric(map[string]string{"id": "0", "host": "a"}), now))
+	require.True(t, limiter.apply(newCardinalityMetric(map[string]string{"id": "1", "host": "a"}), now))
+
+	// Metrics of new series end up in the series without the stripped tags
+	m := newCardinalityMetric(map[string]string{"id": "2", "host": "a"})
+	require.False(t, limiter.apply(m, now))
+
+	m = newCardinalityMetric(map[string]string{"id": "2", "host": "b"})
+	require.False(t, limiter.apply(m, now))
+	require.Equal(t, int64(2), limiter.Violations.Get())
+}
+
+func TestCardinalityLimiterStripAdmitted(t *testing.T) {
+	cfg := &CardinalityConfig{MaxSeries: 1, Action: CardinalityActionStrip, StripTags: []string{"id"}}
+	require.NoError(t, cfg.init())
+	limiter := newCardinalityLimiter(cfg, map[string]string{"test": t.Name()}, testutil.Logger{})
+
+	now := time.Now()
+	require.True(t, limiter.apply(newCardinalityMetric(map[string]string{"host": "a"}), now))
+
+	m := newCardinalityMetric(map[string]string{"id": "1", "host": "a"})
+	require.True(t, limiter.apply(m, now))
+	require.Equal(t, map[string]string{"host": "a"}, m.Tags())
+}
+
+func TestCardinalityLimiterWindow(t *testing.T) {
+	cfg := &CardinalityConfig{MaxSeries: 1, Window: time.Minute}
+	require.NoError(t, cfg.init())
+	limiter := newCardinalityLimiter(cfg, map[string]string{"test": t.Name()}, testutil.Logger{})
+
+	now := time.Now()
+	require.True(t, limiter.apply(newCardinalityMetric(map[string]string{"id": "0"}), now))
+	require.False(t, limiter.apply(newCardinalityMetric(map[string]string{"id": "1"}), now.Add(30*time.Second)))
+
+	// The first series is released after not being seen for the window
+	require.True(t, limiter.apply(newCardinalityMetric(map[string]string{"id": "1"}), now.Add(time.Minute)))
+	require.Equal(t, int64(1), limiter.Series.Get())
+}
+
+func TestCardinalityConfigInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      CardinalityConfig
+		expected string
+	}{
+		{
+			name:     "no limit",
+			expected: "cardinality maximum series must be positive",
+		},
+		{
+			name:     "negative window",
+			cfg:      CardinalityConfig{MaxSeries: 1, Window: -time.Second},
+			expected: "cardinality window must not be negative",
+		},
+		{
+			name:     "invalid action",
+			cfg:      CardinalityConfig{MaxSeries: 1, Action: "foo"},
+			expected: `invalid cardinality action "foo"`,
+		},
+		{
+			name:     "strip without tags",
+			cfg:      CardinalityConfig{MaxSeries: 1, Action: CardinalityActionStrip},
+			expected: "cardinality strip action requires tags to strip",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, tt.cfg.init(), tt.expected)
+		})
+	}
+}
+
+func TestRunningInputCardinality(t *testing.T) {
+	ri := NewRunningInput(&mockInput{}, &InputConfig{
+		Name:        "TestRunningInputCardinality",
+		Cardinality: &CardinalityConfig{MaxSeries: 1},
+	})
+	ri.log = testutil.Logger{}
+	require.NoError(t, ri.Init())
+
+	m := newCardinalityMetric(map[string]string{"id": "0"})
+	require.NotNil(t, ri.MakeMetric(m))
+
+	m = newCardinalityMetric(map[string]string{"id": "1"})
+	require.Nil(t, ri.MakeMetric(m))
+	require.Equal(t, int64(1), ri.cardinality.Violations.Get())
+}
+
+func TestRunningInputCardinalityAggregate(t *testing.T) {
+	ri := NewRunningInput(&mockInput{}, &InputConfig{
+		Name:        "TestRunningInputCardinalityAggregate",
+		Tags:        map[string]string{"plugin": "tag"},
+		Cardinality: &CardinalityConfig{MaxSeries: 1, Action: CardinalityActionAggregate},
+	})
+	ri.log = testutil.Logger{}
+	ri.SetDefaultTags(map[string]string{"host": "localhost"})
+	require.NoError(t, ri.Init())
+
+	require.NotNil(t, ri.MakeMetric(newCardinalityMetric(map[string]string{"id": "0"})))
+	require.Nil(t, ri.MakeMetric(newCardinalityMetric(map[string]string{"id": "1"})))
+
+	// The overflow series is only added when forced before the window ends
+	acc := &makeMetricAccumulator{maker: ri}
+	require.NoError(t, ri.Gather(acc))
+	require.Empty(t, acc.metrics)
+
+	ri.AddCardinalityOverflow(acc, true)
+	require.Len(t, acc.metrics, 1)
+	require.Equal(t, map[string]string{
+		CardinalityOverflowTag: "true",
+		"plugin":               "tag",
+		"host":                 "localhost",
+	}, acc.metrics[0].Tags())
+	require.Equal(t, int64(2), ri.MetricsGathered.Get())
+}
+
+func TestRunningOutputCardinalityAggregate(t *testing.T) {
+	conf := &OutputConfig{
+		Cardinality: &CardinalityConfig{MaxSeries: 1, Action: CardinalityActionAggregate},
+	}
+
+	m := &mockOutput{}
+	ro := NewRunningOutput(m, conf, 100, 1000)
+	require.NoError(t, ro.Init())
+
+	ro.AddMetric(newCardinalityMetric(map[string]string{"id": "0"}))
+	ro.AddMetric(newCardinalityMetric(map[string]string{"id": "1"}))
+	ro.AddMetric(newCardinalityMetric(map[string]string{"id": "2"}))
+
+	// Regular writes keep the aggregates until the window ends
+	require.NoError(t, ro.Write())
+	require.Len(t, m.Metrics(), 1)
+
+	// Flushing writes the aggregates immediately
+	require.NoError(t, ro.Flush())
+	metrics := m.Metrics()
+	require.Len(t, metrics, 2)
+	require.Equal(t, map[string]string{CardinalityOverflowTag: "true"}, metrics[1].Tags())
+	require.Equal(t, map[string]interface{}{
+		"value_count": int64(2),
+		"value_sum":   float64(84),
+		"value_last":  int64(42),
+	}, metrics[1].Fields())
+}
+
+// makeMetricAccumulator passes the added metrics through the plugin like the
+// accumulator of the agent.
+type makeMetricAccumulator struct {
+	testutil.Accumulator
+	maker   *RunningInput
+	metrics []telegraf.Metric
+}
+
+func (a *makeMetricAccumulator) AddMetric(m telegraf.Metric) {
+	if m := a.maker.MakeMetric(m); m != nil {
+		a.metrics = append(a.metrics, m)
+	}
+}
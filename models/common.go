@@ -2,10 +2,32 @@ package models
 
 import (
 	"reflect"
+	"sync"
+	"time"
 
 	"github.com/influxdata/telegraf"
 )
 
+// lastError keeps track of the most recent error of a plugin.
+type lastError struct {
+	err error
+	ts  time.Time
+	sync.Mutex
+}
+
+func (e *lastError) set(err error) {
+	e.Lock()
+	defer e.Unlock()
+	e.err = err
+	e.ts = time.Now()
+}
+
+func (e *lastError) get() (time.Time, error) {
+	e.Lock()
+	defer e.Unlock()
+	return e.ts, e.err
+}
+
 // logName returns the log-friendly name/type.
 func logName(pluginType, name, alias string) string {
 	if alias == "" {
@@ -3,6 +3,8 @@ package models
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/google/cel-go/cel"
@@ -57,6 +59,15 @@ type Filter struct {
 	MetricPass   string
 	metricFilter cel.Program
 
+	// Assignments of CEL expressions to fields or tags of the metric
+	MetricTransform  []string
+	metricTransforms []metricTransform
+
+	// Routes to select metrics of, metrics of other routes are never
+	// selected. Without routes only metrics not belonging to any route are
+	// selected.
+	Routes []string
+
 	selectActive bool
 	modifyActive bool
 
@@ -72,7 +83,7 @@ func (f *Filter) Compile() error {
 	f.modifyActive = len(f.FieldInclude) > 0 || len(f.FieldExclude) > 0
 	f.modifyActive = f.modifyActive || len(f.TagInclude) > 0 || len(f.TagExclude) > 0
 
-	f.isActive = f.selectActive || f.modifyActive
+	f.isActive = f.selectActive || f.modifyActive || len(f.MetricTransform) > 0
 
 	if !f.isActive {
 		return nil
@@ -122,6 +133,10 @@ func (f *Filter) Compile() error {
 		}
 	}
 
+	if err := f.compileMetricTransforms(); err != nil {
+		return fmt.Errorf("error compiling 'metric_transform', %w", err)
+	}
+
 	return f.compileMetricFilter()
 }
 
@@ -129,6 +144,10 @@ func (f *Filter) Compile() error {
 // namepass/namedrop, tagpass/tagdrop and metric filters.
 // The metric is not modified.
 func (f *Filter) Select(metric telegraf.Metric) (bool, error) {
+	if !selectRoute(f.Routes, metric) {
+		return false, nil
+	}
+
 	if !f.selectActive {
 		return true, nil
 	}
@@ -142,12 +161,7 @@ func (f *Filter) Select(metric telegraf.Metric) (bool, error) {
 	}
 
 	if f.metricFilter != nil {
-		result, _, err := f.metricFilter.Eval(map[string]interface{}{
-			"name":   metric.Name(),
-			"tags":   metric.Tags(),
-			"fields": metric.Fields(),
-			"time":   metric.Time(),
-		})
+		result, _, err := f.metricFilter.Eval(metricActivation(metric))
 		if err != nil {
 			return true, err
 		}
@@ -171,6 +185,40 @@ func (f *Filter) Modify(metric telegraf.Metric) {
 	f.filterTags(metric)
 }
 
+// Transform sets the fields and tags of the metric according to the
+// metric_transform assignments. The assignments are applied in order so
+// later expressions see the results of previous ones. Failing assignments
+// are skipped and reported in the returned error.
+func (f *Filter) Transform(metric telegraf.Metric) error {
+	var errs []error
+	for _, t := range f.metricTransforms {
+		result, _, err := t.program.Eval(metricActivation(metric))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("evaluating %s.%s failed: %w", t.target, t.key, err))
+			continue
+		}
+
+		value := result.Value()
+		if t.target == "tags" {
+			v, ok := value.(string)
+			if !ok {
+				errs = append(errs, fmt.Errorf("invalid result type %T for tag %q", value, t.key))
+				continue
+			}
+			metric.AddTag(t.key, v)
+			continue
+		}
+
+		switch value.(type) {
+		case bool, int64, uint64, float64, string:
+			metric.AddField(t.key, value)
+		default:
+			errs = append(errs, fmt.Errorf("invalid result type %T for field %q", value, t.key))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // IsActive checking if filter is active
 func (f *Filter) IsActive() bool {
 	return f.isActive
@@ -232,6 +280,61 @@ func (f *Filter) filterTags(metric telegraf.Metric) {
 	}
 }
 
+// metricTransform is a compiled assignment of a CEL expression to a field
+// or tag of a metric.
+type metricTransform struct {
+	target  string
+	key     string
+	program cel.Program
+}
+
+// Assignments in the form of `fields.<key> = <expression>` or
+// `tags["<key>"] = <expression>`
+var metricTransformRe = regexp.MustCompile(`^\s*(fields|tags)(?:\.([A-Za-z_][A-Za-z0-9_]*)|\[\s*"([^"]+)"\s*\])\s*=([^=].*)$`)
+
+// Compile the metric transformations
+func (f *Filter) compileMetricTransforms() error {
+	// Reset internal state
+	f.metricTransforms = nil
+
+	if len(f.MetricTransform) == 0 {
+		return nil
+	}
+
+	env, err := newMetricEnv()
+	if err != nil {
+		return err
+	}
+
+	f.metricTransforms = make([]metricTransform, 0, len(f.MetricTransform))
+	for _, assignment := range f.MetricTransform {
+		match := metricTransformRe.FindStringSubmatch(assignment)
+		if match == nil {
+			return fmt.Errorf("invalid assignment %q, expected 'fields.<key> = <expression>' or 'tags.<key> = <expression>'", assignment)
+		}
+		key := match[2]
+		if key == "" {
+			key = match[3]
+		}
+
+		ast, issues := env.Compile(strings.TrimSpace(match[4]))
+		if issues.Err() != nil {
+			return fmt.Errorf("compiling %q failed: %w", assignment, issues.Err())
+		}
+		program, err := env.Program(ast, cel.EvalOptions(cel.OptOptimize))
+		if err != nil {
+			return fmt.Errorf("creating program for %q failed: %w", assignment, err)
+		}
+
+		f.metricTransforms = append(f.metricTransforms, metricTransform{
+			target:  match[1],
+			key:     key,
+			program: program,
+		})
+	}
+	return nil
+}
+
 // Compile the metric filter
 func (f *Filter) compileMetricFilter() error {
 	// Reset internal state
@@ -245,7 +348,32 @@ func (f *Filter) compileMetricFilter() error {
 		return nil
 	}
 
-	// Declare the computation environment for the filter including custom functions
+	env, err := newMetricEnv()
+	if err != nil {
+		return err
+	}
+
+	// Compile the program
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return issues.Err()
+	}
+	// Check if we got a boolean expression needed for filtering
+	if ast.OutputType() != cel.BoolType {
+		return errors.New("expression needs to return a boolean")
+	}
+
+	// Get the final program
+	options := cel.EvalOptions(
+		cel.OptOptimize,
+	)
+	f.metricFilter, err = env.Program(ast, options)
+	return err
+}
+
+// newMetricEnv declares the computation environment for CEL expressions on
+// metrics including custom functions.
+func newMetricEnv() (*cel.Env, error) {
 	env, err := cel.NewEnv(
 		cel.Declarations(
 			decls.NewVar("name", decls.String),
@@ -263,25 +391,19 @@ func (f *Filter) compileMetricFilter() error {
 		ext.Strings(),
 	)
 	if err != nil {
-		return fmt.Errorf("creating environment failed: %w", err)
+		return nil, fmt.Errorf("creating environment failed: %w", err)
 	}
+	return env, nil
+}
 
-	// Compile the program
-	ast, issues := env.Compile(expression)
-	if issues.Err() != nil {
-		return issues.Err()
-	}
-	// Check if we got a boolean expression needed for filtering
-	if ast.OutputType() != cel.BoolType {
-		return errors.New("expression needs to return a boolean")
+// metricActivation returns the variables of the metric environment.
+func metricActivation(metric telegraf.Metric) map[string]interface{} {
+	return map[string]interface{}{
+		"name":   metric.Name(),
+		"tags":   metric.Tags(),
+		"fields": metric.Fields(),
+		"time":   metric.Time(),
 	}
-
-	// Get the final program
-	options := cel.EvalOptions(
-		cel.OptOptimize,
-	)
-	f.metricFilter, err = env.Program(ast, options)
-	return err
 }
 
 func ShouldPassFilters(include, exclude filter.Filter, key string) bool {
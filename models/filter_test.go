@@ -25,6 +25,36 @@ func TestFilterApplyEmpty(t *testing.T) {
 	require.True(t, selected)
 }
 
+func TestFilterRoutes(t *testing.T) {
+	unrouted := metric.New("m", map[string]string{}, map[string]interface{}{"value": int64(1)}, time.Now())
+	routed := metric.New("m", map[string]string{}, map[string]interface{}{"value": int64(1)}, time.Now())
+	metric.SetRoute(routed, "a")
+
+	// Plugins without routes only select metrics without route
+	f := Filter{}
+	require.NoError(t, f.Compile())
+	selected, err := f.Select(unrouted)
+	require.NoError(t, err)
+	require.True(t, selected)
+	selected, err = f.Select(routed)
+	require.NoError(t, err)
+	require.False(t, selected)
+
+	// Plugins with routes only select metrics of those routes
+	f = Filter{Routes: []string{"a", "b"}}
+	require.NoError(t, f.Compile())
+	selected, err = f.Select(unrouted)
+	require.NoError(t, err)
+	require.False(t, selected)
+	selected, err = f.Select(routed)
+	require.NoError(t, err)
+	require.True(t, selected)
+	metric.SetRoute(routed, "c")
+	selected, err = f.Select(routed)
+	require.NoError(t, err)
+	require.False(t, selected)
+}
+
 func TestFilterApplyTagsDontPass(t *testing.T) {
 	filters := []TagFilter{
 		{
@@ -646,6 +676,69 @@ func TestFilterMetricPass(t *testing.T) {
 	}
 }
 
+func TestFilterMetricTransform(t *testing.T) {
+	f := Filter{
+		MetricTransform: []string{
+			`fields.used_pct = double(fields.used) / double(fields.total) * 100.0`,
+			`fields.free = fields.total - fields.used`,
+			`tags.host = tags.host.lowerAscii()`,
+			`tags["full"] = fields.used_pct > 50.0 ? "yes" : "no"`,
+		},
+	}
+	require.NoError(t, f.Compile())
+	require.True(t, f.IsActive())
+
+	m := testutil.MustMetric("mem",
+		map[string]string{"host": "Hugin"},
+		map[string]interface{}{"used": 75, "total": 100},
+		time.Unix(0, 0),
+	)
+	require.NoError(t, f.Transform(m))
+
+	expected := testutil.MustMetric("mem",
+		map[string]string{"host": "hugin", "full": "yes"},
+		map[string]interface{}{"used": 75, "total": 100, "used_pct": 75.0, "free": 25},
+		time.Unix(0, 0),
+	)
+	testutil.RequireMetricEqual(t, expected, m)
+}
+
+func TestFilterMetricTransformErrors(t *testing.T) {
+	f := Filter{
+		MetricTransform: []string{
+			`fields.missing = fields.unknown + 1`,
+			`tags.count = fields.used`,
+			`fields.ok = true`,
+		},
+	}
+	require.NoError(t, f.Compile())
+
+	m := testutil.MustMetric("mem",
+		map[string]string{},
+		map[string]interface{}{"used": 75},
+		time.Unix(0, 0),
+	)
+	err := f.Transform(m)
+	require.ErrorContains(t, err, "evaluating fields.missing failed")
+	require.ErrorContains(t, err, `invalid result type int64 for tag "count"`)
+
+	// Failing assignments do not prevent the remaining ones
+	require.Equal(t, map[string]interface{}{"used": int64(75), "ok": true}, m.Fields())
+	require.Empty(t, m.Tags())
+}
+
+func TestFilterMetricTransformInvalid(t *testing.T) {
+	for _, assignment := range []string{
+		`fields.value == 1`,
+		`name = "foo"`,
+		`fields.value = `,
+		`fields.value = fields.`,
+	} {
+		f := Filter{MetricTransform: []string{assignment}}
+		require.ErrorContainsf(t, f.Compile(), "metric_transform", "assignment %q", assignment)
+	}
+}
+
 func BenchmarkFilter(b *testing.B) {
 	tests := []struct {
 		name   string
@@ -0,0 +1,288 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	logging "github.com/influxdata/telegraf/logger"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// Policies for dispatching batches to the members of an output group
+const (
+	GroupPolicyFailover   = "failover"
+	GroupPolicyRoundRobin = "round_robin"
+	GroupPolicyHash       = "hash"
+)
+
+// OutputGroup is an output dispatching batches to its member outputs
+// according to a policy, so the members share the buffer of the group.
+// Members failing to write are marked unhealthy and are skipped until the
+// recovery interval passed.
+type OutputGroup struct {
+	Name             string
+	Policy           string
+	HashTag          string
+	RecoveryInterval time.Duration
+	Members          []*GroupMember
+
+	Log telegraf.Logger `toml:"-"`
+
+	next int
+}
+
+// GroupMember is an output belonging to an output group.
+type GroupMember struct {
+	Output telegraf.Output
+	Config *OutputConfig
+
+	Healthy     selfstat.Stat
+	WriteErrors selfstat.Stat
+
+	log       telegraf.Logger
+	connected bool
+	unhealthy bool
+	retryAt   time.Time
+	recovery  time.Duration
+}
+
+// NewGroupMember creates a member of the output group with the given name.
+func NewGroupMember(output telegraf.Output, config *OutputConfig, group string) *GroupMember {
+	tags := map[string]string{"group": group, "output": config.Name}
+	if config.Alias != "" {
+		tags["alias"] = config.Alias
+	}
+
+	logger := logging.New("outputs", config.Name, config.Alias)
+	if err := logger.SetLogLevel(config.LogLevel); err != nil {
+		logger.Error(err)
+	}
+	SetLoggerOnPlugin(output, logger)
+
+	m := &GroupMember{
+		Output:      output,
+		Config:      config,
+		Healthy:     selfstat.Register("output_group", "healthy", tags),
+		WriteErrors: selfstat.Register("output_group", "write_errors", tags),
+		log:         logger,
+	}
+	m.Healthy.Set(1)
+	return m
+}
+
+func (m *GroupMember) LogName() string {
+	return logName("outputs", m.Config.Name, m.Config.Alias)
+}
+
+func (m *GroupMember) ID() string {
+	if p, ok := m.Output.(telegraf.PluginWithID); ok {
+		return p.ID()
+	}
+	return m.Config.ID
+}
+
+// available returns true if the member is healthy or its recovery interval
+// passed.
+func (m *GroupMember) available(now time.Time) bool {
+	return !m.unhealthy || !now.Before(m.retryAt)
+}
+
+// write writes the metrics to the member, connecting first if necessary.
+// Partial write errors are considered a response of a healthy member.
+func (m *GroupMember) write(metrics []telegraf.Metric) error {
+	if !m.connected {
+		if err := m.Output.Connect(); err != nil {
+			m.failed(err)
+			return fmt.Errorf("connecting %s failed: %w", m.LogName(), err)
+		}
+		m.connected = true
+	}
+
+	err := m.Output.Write(metrics)
+	var writeErr *internal.PartialWriteError
+	if err != nil && !errors.As(err, &writeErr) {
+		m.failed(err)
+		return fmt.Errorf("writing to %s failed: %w", m.LogName(), err)
+	}
+
+	if m.unhealthy {
+		m.log.Info("Recovered, marking member healthy")
+		m.unhealthy = false
+		m.Healthy.Set(1)
+	}
+	return err
+}
+
+func (m *GroupMember) failed(err error) {
+	m.WriteErrors.Incr(1)
+	m.retryAt = time.Now().Add(m.recovery)
+	if !m.unhealthy {
+		m.log.Warnf("Marking member unhealthy for %s: %v", m.recovery, err)
+		m.unhealthy = true
+		m.Healthy.Set(0)
+	}
+}
+
+func (*OutputGroup) SampleConfig() string {
+	return ""
+}
+
+func (g *OutputGroup) Init() error {
+	switch g.Policy {
+	case "":
+		g.Policy = GroupPolicyFailover
+	case GroupPolicyFailover, GroupPolicyRoundRobin:
+	case GroupPolicyHash:
+		if g.HashTag == "" {
+			return errors.New("hash policy requires a hash tag")
+		}
+	default:
+		return fmt.Errorf("invalid policy %q", g.Policy)
+	}
+	if g.RecoveryInterval == 0 {
+		g.RecoveryInterval = 30 * time.Second
+	}
+	if len(g.Members) == 0 {
+		return fmt.Errorf("no members in output group %q", g.Name)
+	}
+
+	for _, m := range g.Members {
+		m.recovery = g.RecoveryInterval
+		if p, ok := m.Output.(telegraf.Initializer); ok {
+			if err := p.Init(); err != nil {
+				return fmt.Errorf("could not initialize member %s: %w", m.LogName(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// Connect connects all members of the group. Members failing to connect are
+// marked unhealthy and connecting them is retried on write. An error is only
+// returned if no member could be connected.
+func (g *OutputGroup) Connect() error {
+	errs := make([]error, 0, len(g.Members))
+	for _, m := range g.Members {
+		if err := m.Output.Connect(); err != nil {
+			m.failed(err)
+			errs = append(errs, fmt.Errorf("connecting %s failed: %w", m.LogName(), err))
+			continue
+		}
+		m.connected = true
+	}
+
+	if len(errs) == len(g.Members) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+func (g *OutputGroup) Close() error {
+	for _, m := range g.Members {
+		if !m.connected {
+			continue
+		}
+		if err := m.Output.Close(); err != nil {
+			m.log.Errorf("Error closing output: %v", err)
+		}
+		m.connected = false
+	}
+	return nil
+}
+
+func (g *OutputGroup) Write(metrics []telegraf.Metric) error {
+	switch g.Policy {
+	case GroupPolicyRoundRobin:
+		start := g.next
+		g.next = (g.next + 1) % len(g.Members)
+		return g.writeFrom(start, metrics)
+	case GroupPolicyHash:
+		return g.writeHashed(metrics)
+	}
+	return g.writeFrom(0, metrics)
+}
+
+// writeFrom writes the metrics to the first available member starting at the
+// given index and falls over to the following members on errors.
+func (g *OutputGroup) writeFrom(start int, metrics []telegraf.Metric) error {
+	now := time.Now()
+
+	var errs []error
+	for i := range g.Members {
+		m := g.Members[(start+i)%len(g.Members)]
+		if !m.available(now) {
+			continue
+		}
+
+		err := m.write(metrics)
+		var writeErr *internal.PartialWriteError
+		if err == nil || errors.As(err, &writeErr) {
+			return err
+		}
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		return errors.New("no healthy member available")
+	}
+	return errors.Join(errs...)
+}
+
+// writeHashed partitions the metrics by the value of the hash tag and writes
+// each partition to the member selected by the hash of the value. Metrics of
+// partitions that could not be written to any member are kept.
+func (g *OutputGroup) writeHashed(metrics []telegraf.Metric) error {
+	partitions := make([][]int, len(g.Members))
+	for i, m := range metrics {
+		value, _ := m.GetTag(g.HashTag)
+		h := fnv.New32a()
+		h.Write([]byte(value))
+		idx := int(h.Sum32() % uint32(len(g.Members)))
+		partitions[idx] = append(partitions[idx], i)
+	}
+
+	werr := &internal.PartialWriteError{MetricsAccept: make([]int, 0, len(metrics))}
+	for idx, indices := range partitions {
+		if len(indices) == 0 {
+			continue
+		}
+
+		batch := make([]telegraf.Metric, 0, len(indices))
+		for _, i := range indices {
+			batch = append(batch, metrics[i])
+		}
+
+		err := g.writeFrom(idx, batch)
+		if err == nil {
+			werr.MetricsAccept = append(werr.MetricsAccept, indices...)
+			continue
+		}
+		werr.Err = err
+
+		// Translate the indices of partially written partitions
+		var writeErr *internal.PartialWriteError
+		if !errors.As(err, &writeErr) {
+			continue
+		}
+		for _, i := range writeErr.MetricsAccept {
+			werr.MetricsAccept = append(werr.MetricsAccept, indices[i])
+		}
+		for j, i := range writeErr.MetricsReject {
+			reason := writeErr.Err
+			if j < len(writeErr.MetricsRejectErrors) && writeErr.MetricsRejectErrors[j] != nil {
+				reason = writeErr.MetricsRejectErrors[j]
+			}
+			werr.MetricsReject = append(werr.MetricsReject, indices[i])
+			werr.MetricsRejectErrors = append(werr.MetricsRejectErrors, reason)
+		}
+	}
+
+	if werr.Err == nil {
+		return nil
+	}
+	return werr
+}
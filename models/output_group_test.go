@@ -0,0 +1,131 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newTestGroup(t *testing.T, policy string, members ...*mockOutput) *OutputGroup {
+	g := &OutputGroup{
+		Name:             "test",
+		Policy:           policy,
+		HashTag:          "host",
+		RecoveryInterval: time.Hour,
+	}
+	for _, m := range members {
+		g.Members = append(g.Members, NewGroupMember(m, &OutputConfig{Name: "mock"}, g.Name))
+	}
+	require.NoError(t, g.Init())
+	require.NoError(t, g.Connect())
+	return g
+}
+
+func TestOutputGroupInvalid(t *testing.T) {
+	g := &OutputGroup{Name: "test", Policy: "foo"}
+	require.ErrorContains(t, g.Init(), "invalid policy")
+
+	g = &OutputGroup{Name: "test", Policy: GroupPolicyHash}
+	require.ErrorContains(t, g.Init(), "requires a hash tag")
+
+	g = &OutputGroup{Name: "test"}
+	require.ErrorContains(t, g.Init(), "no members")
+}
+
+func TestOutputGroupFailover(t *testing.T) {
+	primary := &mockOutput{}
+	secondary := &mockOutput{}
+	g := newTestGroup(t, GroupPolicyFailover, primary, secondary)
+
+	require.NoError(t, g.Write(first5))
+	require.Equal(t, first5, primary.Metrics())
+	require.Empty(t, secondary.Metrics())
+
+	// A failing primary is skipped until the recovery interval passed
+	primary.batchAcceptSize = -1
+	require.NoError(t, g.Write(next5))
+	require.Equal(t, next5, secondary.Metrics())
+	require.NoError(t, g.Write(first5))
+	require.Equal(t, 2, primary.writes)
+	require.Equal(t, append(next5, first5...), secondary.Metrics())
+	require.True(t, g.Members[0].unhealthy)
+
+	// The primary is used again after recovering
+	primary.batchAcceptSize = 0
+	g.Members[0].retryAt = time.Now()
+	require.NoError(t, g.Write(next5))
+	require.False(t, g.Members[0].unhealthy)
+	require.Equal(t, append(first5, next5...), primary.Metrics())
+
+	// Failing members keep the metrics in the buffer
+	primary.batchAcceptSize = -1
+	secondary.batchAcceptSize = -1
+	require.Error(t, g.Write(first5))
+	require.ErrorContains(t, g.Write(first5), "no healthy member available")
+}
+
+func TestOutputGroupRoundRobin(t *testing.T) {
+	a := &mockOutput{}
+	b := &mockOutput{}
+	g := newTestGroup(t, GroupPolicyRoundRobin, a, b)
+
+	require.NoError(t, g.Write(first5))
+	require.NoError(t, g.Write(next5))
+	require.NoError(t, g.Write(first5))
+	require.Equal(t, append(first5, first5...), a.Metrics())
+	require.Equal(t, next5, b.Metrics())
+}
+
+func TestOutputGroupHash(t *testing.T) {
+	a := &mockOutput{}
+	b := &mockOutput{}
+	g := newTestGroup(t, GroupPolicyHash, a, b)
+
+	metrics := make([]telegraf.Metric, 0, 20)
+	for _, host := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+		for range 2 {
+			metrics = append(metrics, testutil.MustMetric("cpu", map[string]string{"host": host}, map[string]interface{}{"value": 42}, time.Unix(0, 0)))
+		}
+	}
+
+	require.NoError(t, g.Write(metrics))
+	require.Len(t, append(a.Metrics(), b.Metrics()...), len(metrics))
+	require.NotEmpty(t, a.Metrics())
+	require.NotEmpty(t, b.Metrics())
+
+	// Metrics with the same tag value are written to the same member
+	seen := make(map[string]*mockOutput)
+	for _, m := range []*mockOutput{a, b} {
+		for _, x := range m.Metrics() {
+			host, _ := x.GetTag("host")
+			if prev, found := seen[host]; found {
+				require.Same(t, prev, m)
+			}
+			seen[host] = m
+		}
+	}
+}
+
+func TestOutputGroupHashPartialFailure(t *testing.T) {
+	a := &mockOutput{}
+	b := &mockOutput{}
+	g := newTestGroup(t, GroupPolicyHash, a, b)
+
+	// Metrics of partitions not written to any member are kept
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+	}
+	a.batchAcceptSize = -1
+	b.batchAcceptSize = -1
+	err := g.Write(metrics)
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.Empty(t, writeErr.MetricsAccept)
+	require.Empty(t, writeErr.MetricsReject)
+}
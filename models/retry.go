@@ -0,0 +1,74 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryConfig controls retrying failed writes of an output with an
+// exponentially increasing delay.
+type RetryConfig struct {
+	// Delay after the first failed attempt
+	InitialDelay time.Duration
+	// Upper limit of the delay between attempts
+	MaxDelay time.Duration
+	// Factor to increase the delay by for each failed attempt
+	Multiplier float64
+	// Fraction of the delay to randomly add or subtract
+	Jitter float64
+	// Number of attempts to write a batch before giving up, zero means
+	// retrying forever
+	MaxAttempts int
+	// Destination for batches exceeding the maximum number of attempts,
+	// either "outputs.<alias or name>" or a file. The batches are dropped
+	// if empty.
+	DeadLetter string
+}
+
+// retryState tracks the consecutive failed write attempts of an output.
+type retryState struct {
+	attempts int
+	next     time.Time
+}
+
+func (c *RetryConfig) init() error {
+	if c.InitialDelay == 0 {
+		c.InitialDelay = time.Second
+	}
+	if c.MaxDelay == 0 {
+		c.MaxDelay = 5 * time.Minute
+	}
+	if c.Multiplier == 0 {
+		c.Multiplier = 2
+	}
+
+	if c.InitialDelay < 0 {
+		return errors.New("retry initial delay must not be negative")
+	}
+	if c.MaxDelay < c.InitialDelay {
+		return errors.New("retry maximum delay must not be smaller than the initial delay")
+	}
+	if c.Multiplier < 1 {
+		return fmt.Errorf("invalid retry multiplier %v, must be at least 1", c.Multiplier)
+	}
+	if c.Jitter < 0 || c.Jitter > 1 {
+		return fmt.Errorf("invalid retry jitter %v, must be between 0 and 1", c.Jitter)
+	}
+	if c.MaxAttempts < 0 {
+		return errors.New("retry maximum attempts must not be negative")
+	}
+	return nil
+}
+
+// delay returns the time to wait after the given number of failed attempts.
+func (c *RetryConfig) delay(attempts int) time.Duration {
+	d := float64(c.InitialDelay) * math.Pow(c.Multiplier, float64(attempts-1))
+	d = math.Min(d, float64(c.MaxDelay))
+	if c.Jitter > 0 {
+		d += d * c.Jitter * (2*rand.Float64() - 1) //nolint:gosec // G404: not security critical
+	}
+	return time.Duration(d)
+}
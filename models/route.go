@@ -0,0 +1,32 @@
+package models
+
+import (
+	"slices"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// selectRoute returns true if the metric belongs to one of the given routes.
+// Plugins without routes only select metrics not belonging to any route.
+func selectRoute(routes []string, m telegraf.Metric) bool {
+	route := metric.Route(m)
+	if route == "" {
+		return len(routes) == 0
+	}
+	return slices.Contains(routes, route)
+}
+
+// routeAccumulator assigns metrics created by a plugin while processing a
+// routed metric to the route of that metric.
+type routeAccumulator struct {
+	telegraf.Accumulator
+	route string
+}
+
+func (a *routeAccumulator) AddMetric(m telegraf.Metric) {
+	if metric.Route(m) == "" {
+		metric.SetRoute(m, a.route)
+	}
+	a.Accumulator.AddMetric(m)
+}
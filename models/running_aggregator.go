@@ -32,6 +32,7 @@ func NewRunningAggregator(aggregator telegraf.Aggregator, config *AggregatorConf
 
 	aggErrorsRegister := selfstat.Register("aggregate", "errors", tags)
 	logger := logging.New("aggregators", config.Name, config.Alias)
+	logger.SetID(config.ID)
 	logger.RegisterErrorCallback(func() {
 		aggErrorsRegister.Incr(1)
 	})
@@ -130,6 +131,12 @@ func (r *RunningAggregator) MakeMetric(telegrafMetric telegraf.Metric) telegraf.
 		r.Config.Tags,
 		nil)
 
+	// Aggregates of a single route stay within the route, aggregates of
+	// multiple routes do not belong to any route
+	if len(r.Config.Filter.Routes) == 1 {
+		metric.SetRoute(m, r.Config.Filter.Routes[0])
+	}
+
 	r.MetricsPushed.Incr(1)
 
 	return m
@@ -151,6 +158,9 @@ func (r *RunningAggregator) Add(m telegraf.Metric) bool {
 	// aggregation to be pushed would introduce a hefty latency to delivery.
 	m = metric.FromMetric(m)
 
+	if err := r.Config.Filter.Transform(m); err != nil {
+		r.log.Errorf("transforming failed: %v", err)
+	}
 	r.Config.Filter.Modify(m)
 	if len(m.FieldList()) == 0 {
 		r.MetricsFiltered.Incr(1)
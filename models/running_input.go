@@ -8,6 +8,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	logging "github.com/influxdata/telegraf/logger"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
@@ -21,6 +22,10 @@ type RunningInput struct {
 	Input  telegraf.Input
 	Config *InputConfig
 
+	// Processors applied to the metrics of this input before passing them
+	// to the global processors
+	Processors RunningProcessors
+
 	log         telegraf.Logger
 	defaultTags map[string]string
 
@@ -29,11 +34,20 @@ type RunningInput struct {
 	retries     uint64
 	gatherStart time.Time
 	gatherEnd   time.Time
+	lastError   lastError
+
+	failures     int
+	backoffUntil time.Time
+	cardinality  *cardinalityLimiter
 
 	MetricsGathered selfstat.Stat
 	GatherTime      selfstat.Stat
 	GatherTimeouts  selfstat.Stat
 	StartupErrors   selfstat.Stat
+
+	// Only registered if the backoff is enabled
+	GatherBackoff       selfstat.Stat
+	ConsecutiveFailures selfstat.Stat
 }
 
 func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
@@ -44,6 +58,7 @@ func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
 
 	inputErrorsRegister := selfstat.Register("gather", "errors", tags)
 	logger := logging.New("inputs", config.Name, config.Alias)
+	logger.SetID(config.ID)
 	logger.RegisterErrorCallback(func() {
 		inputErrorsRegister.Incr(1)
 		GlobalGatherErrors.Incr(1)
@@ -53,7 +68,7 @@ func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
 	}
 	SetLoggerOnPlugin(input, logger)
 
-	return &RunningInput{
+	r := &RunningInput{
 		Input:  input,
 		Config: config,
 		MetricsGathered: selfstat.Register(
@@ -78,6 +93,14 @@ func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
 		),
 		log: logger,
 	}
+	if config.Backoff != nil {
+		r.GatherBackoff = selfstat.Register("gather", "backoff_ns", tags)
+		r.ConsecutiveFailures = selfstat.Register("gather", "consecutive_failures", tags)
+	}
+	if config.Cardinality != nil {
+		r.cardinality = newCardinalityLimiter(config.Cardinality, tags, logger)
+	}
+	return r
 }
 
 // InputConfig is the common config for all inputs.
@@ -101,6 +124,18 @@ type InputConfig struct {
 	Filter                  Filter
 	AlwaysIncludeLocalTags  bool
 	AlwaysIncludeGlobalTags bool
+
+	// Route the metrics of the input belong to
+	Route string
+
+	// Schedule triggering the gather instead of the interval
+	Schedule *internal.Schedule
+
+	// Backoff of the gather interval on consecutive failures, disabled if nil
+	Backoff *BackoffConfig
+
+	// Limit of the distinct series of the input, disabled if nil
+	Cardinality *CardinalityConfig
 }
 
 func (*RunningInput) metricFiltered(metric telegraf.Metric) {
@@ -126,6 +161,17 @@ func (r *RunningInput) Init() error {
 		return fmt.Errorf("invalid 'time_source' setting %q", r.Config.TimeSource)
 	}
 
+	if r.Config.Backoff != nil {
+		if err := r.Config.Backoff.init(); err != nil {
+			return err
+		}
+	}
+	if r.Config.Cardinality != nil {
+		if err := r.Config.Cardinality.init(); err != nil {
+			return err
+		}
+	}
+
 	if p, ok := r.Input.(telegraf.Initializer); ok {
 		return p.Init()
 	}
@@ -192,26 +238,33 @@ func (r *RunningInput) ID() string {
 	return r.Config.ID
 }
 
-func (r *RunningInput) MakeMetric(metric telegraf.Metric) telegraf.Metric {
-	ok, err := r.Config.Filter.Select(metric)
+func (r *RunningInput) MakeMetric(m telegraf.Metric) telegraf.Metric {
+	if overflow, ok := m.(*cardinalityOverflow); ok {
+		return r.makeOverflowMetric(overflow.Metric)
+	}
+
+	ok, err := r.Config.Filter.Select(m)
 	if err != nil {
 		r.log.Errorf("filtering failed: %v", err)
 	} else if !ok {
-		r.metricFiltered(metric)
+		r.metricFiltered(m)
 		return nil
 	}
 
 	makeMetric(
-		metric,
+		m,
 		r.Config.NameOverride,
 		r.Config.MeasurementPrefix,
 		r.Config.MeasurementSuffix,
 		r.Config.Tags,
 		r.defaultTags)
 
-	r.Config.Filter.Modify(metric)
-	if len(metric.FieldList()) == 0 {
-		r.metricFiltered(metric)
+	if err := r.Config.Filter.Transform(m); err != nil {
+		r.log.Errorf("transforming failed: %v", err)
+	}
+	r.Config.Filter.Modify(m)
+	if len(m.FieldList()) == 0 {
+		r.metricFiltered(m)
 		return nil
 	}
 
@@ -223,20 +276,29 @@ func (r *RunningInput) MakeMetric(metric telegraf.Metric) telegraf.Metric {
 		if r.Config.AlwaysIncludeGlobalTags {
 			global = r.defaultTags
 		}
-		makeMetric(metric, "", "", "", local, global)
+		makeMetric(m, "", "", "", local, global)
 	}
 
 	switch r.Config.TimeSource {
 	case "collection_start":
-		metric.SetTime(r.gatherStart)
+		m.SetTime(r.gatherStart)
 	case "collection_end":
-		metric.SetTime(r.gatherEnd)
+		m.SetTime(r.gatherEnd)
 	default:
 	}
 
+	if r.Config.Route != "" {
+		metric.SetRoute(m, r.Config.Route)
+	}
+
+	if r.cardinality != nil && !r.cardinality.apply(m, time.Now()) {
+		r.metricFiltered(m)
+		return nil
+	}
+
 	r.MetricsGathered.Incr(1)
 	GlobalMetricsGathered.Incr(1)
-	return metric
+	return m
 }
 
 func (r *RunningInput) Gather(acc telegraf.Accumulator) error {
@@ -259,11 +321,37 @@ func (r *RunningInput) Gather(acc telegraf.Accumulator) error {
 	r.gatherStart = time.Now()
 	err := r.Input.Gather(acc)
 	r.gatherEnd = time.Now()
+	r.AddCardinalityOverflow(acc, false)
 
 	r.GatherTime.Incr(r.gatherEnd.Sub(r.gatherStart).Nanoseconds())
 	return err
 }
 
+// AddCardinalityOverflow adds the series aggregating the metrics exceeding the
+// cardinality limit to the accumulator once per window or, if forced,
+// immediately.
+func (r *RunningInput) AddCardinalityOverflow(acc telegraf.Accumulator, force bool) {
+	if r.cardinality == nil {
+		return
+	}
+	for _, m := range r.cardinality.overflowMetrics(time.Now(), force) {
+		acc.AddMetric(&cardinalityOverflow{m})
+	}
+}
+
+// makeOverflowMetric adds the tags to the overflow series of the cardinality
+// limit. The aggregated metrics already passed the other processing steps.
+func (r *RunningInput) makeOverflowMetric(m telegraf.Metric) telegraf.Metric {
+	makeMetric(m, "", "", "", r.Config.Tags, r.defaultTags)
+	if r.Config.Route != "" {
+		metric.SetRoute(m, r.Config.Route)
+	}
+
+	r.MetricsGathered.Incr(1)
+	GlobalMetricsGathered.Incr(1)
+	return m
+}
+
 func (r *RunningInput) SetDefaultTags(tags map[string]string) {
 	r.defaultTags = tags
 }
@@ -272,6 +360,55 @@ func (r *RunningInput) Log() telegraf.Logger {
 	return r.log
 }
 
+// RecordError remembers the given error as the most recent gather error.
+func (r *RunningInput) RecordError(err error) {
+	r.lastError.set(err)
+}
+
+// LastError returns the time and the most recent gather error. The error is
+// nil if gathering never failed.
+func (r *RunningInput) LastError() (time.Time, error) {
+	return r.lastError.get()
+}
+
+// BackingOff returns true if gathering at the given time should be skipped
+// due to previous gather failures.
+func (r *RunningInput) BackingOff(now time.Time) bool {
+	return r.Config.Backoff != nil && now.Before(r.backoffUntil)
+}
+
+// UpdateBackoff updates the backoff state after gathering at the given time
+// with the given regular interval. The gather failed if an error was recorded
+// since that time.
+func (r *RunningInput) UpdateBackoff(start time.Time, interval time.Duration) {
+	cfg := r.Config.Backoff
+	if cfg == nil {
+		return
+	}
+
+	if ts, err := r.LastError(); err == nil || ts.Before(start) {
+		if r.failures > 0 {
+			r.log.Infof("Gathering succeeded after %d failures, resetting backoff", r.failures)
+		}
+		r.failures = 0
+		r.backoffUntil = time.Time{}
+		r.ConsecutiveFailures.Set(0)
+		r.GatherBackoff.Set(0)
+		return
+	}
+
+	r.failures++
+	effective := cfg.interval(interval, r.failures)
+	r.backoffUntil = start.Add(effective)
+	r.ConsecutiveFailures.Set(int64(r.failures))
+	r.GatherBackoff.Set(effective.Nanoseconds())
+	if r.failures == 1 {
+		r.log.Warnf("Gathering failed, backing off to an interval of %s", effective)
+	} else {
+		r.log.Debugf("Gathering failed %d times in a row, backing off to an interval of %s", r.failures, effective)
+	}
+}
+
 func (r *RunningInput) IncrGatherTimeouts() {
 	GlobalGatherTimeouts.Incr(1)
 	r.GatherTimeouts.Incr(1)
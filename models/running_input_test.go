@@ -533,6 +533,56 @@ func TestRunningInputProbingSuccess(t *testing.T) {
 	}
 }
 
+func TestRunningInputBackoff(t *testing.T) {
+	ri := NewRunningInput(&mockInput{}, &InputConfig{
+		Name:    "TestRunningInputBackoff",
+		Backoff: &BackoffConfig{MaxInterval: 35 * time.Second},
+	})
+	ri.log = testutil.Logger{}
+	require.NoError(t, ri.Init())
+
+	interval := 10 * time.Second
+
+	// Consecutive failures lengthen the interval up to the maximum
+	expected := []time.Duration{20 * time.Second, 35 * time.Second, 35 * time.Second}
+	for i, backoff := range expected {
+		tick := time.Now()
+		ri.RecordError(errors.New("failed"))
+		ri.UpdateBackoff(tick, interval)
+
+		require.True(t, ri.BackingOff(tick.Add(backoff-time.Second)))
+		require.False(t, ri.BackingOff(tick.Add(backoff)))
+		require.Equal(t, backoff.Nanoseconds(), ri.GatherBackoff.Get())
+		require.Equal(t, int64(i+1), ri.ConsecutiveFailures.Get())
+	}
+
+	// Succeeding resets the backoff
+	tick := time.Now().Add(time.Hour)
+	ri.UpdateBackoff(tick, interval)
+	require.False(t, ri.BackingOff(tick))
+	require.Zero(t, ri.GatherBackoff.Get())
+	require.Zero(t, ri.ConsecutiveFailures.Get())
+}
+
+func TestRunningInputBackoffDisabled(t *testing.T) {
+	ri := NewRunningInput(&mockInput{}, &InputConfig{Name: "TestRunningInputBackoffDisabled"})
+	require.NoError(t, ri.Init())
+
+	now := time.Now()
+	ri.RecordError(errors.New("failed"))
+	ri.UpdateBackoff(now, 10*time.Second)
+	require.False(t, ri.BackingOff(now))
+	require.Nil(t, ri.GatherBackoff)
+}
+
+func TestRunningInputBackoffInvalid(t *testing.T) {
+	ri := NewRunningInput(&mockInput{}, &InputConfig{
+		Name:    "TestRunningInputBackoffInvalid",
+		Backoff: &BackoffConfig{Multiplier: 0.5},
+	})
+	require.ErrorContains(t, ri.Init(), "invalid backoff multiplier")
+}
+
 type mockInput struct {
 	probeReturn error
 }
@@ -19,6 +19,9 @@ const (
 
 	// Default number of metrics kept. It should be a multiple of batch size.
 	DefaultMetricBufferLimit = 10000
+
+	// Tag containing the reason of metrics forwarded after being rejected
+	RejectReasonTag = "reject_reason"
 )
 
 // OutputConfig containing name and filter
@@ -39,8 +42,24 @@ type OutputConfig struct {
 	NamePrefix   string
 	NameSuffix   string
 
-	BufferStrategy  string
-	BufferDirectory string
+	BufferStrategy       string
+	BufferDirectory      string
+	BufferMaxDiskSize    int64
+	BufferEvictionPolicy string
+	BufferCompression    string
+
+	// Use a memory buffer independent of the buffer strategy until the
+	// buffer is opened using OpenBuffer
+	MemoryBuffer bool
+
+	Retry *RetryConfig
+
+	// Destination for metrics rejected by the output, either
+	// "outputs.<alias or name>" or a file
+	OnReject string
+
+	// Limit of the distinct series written to the output, disabled if nil
+	Cardinality *CardinalityConfig
 
 	LogLevel string
 }
@@ -62,11 +81,22 @@ type RunningOutput struct {
 
 	BatchReady chan time.Time
 
-	buffer Buffer
-	log    telegraf.Logger
+	// DeadLetter receives the batches exceeding the maximum number of write
+	// attempts if set
+	DeadLetter func(metrics []telegraf.Metric)
+
+	// OnReject receives the metrics rejected by the output, tagged with the
+	// reason, if set
+	OnReject func(metrics []telegraf.Metric)
+
+	buffer      Buffer
+	log         telegraf.Logger
+	cardinality *cardinalityLimiter
 
-	started bool
-	retries uint64
+	started   bool
+	retries   uint64
+	retry     retryState
+	lastError lastError
 
 	aggMutex sync.Mutex
 }
@@ -79,6 +109,7 @@ func NewRunningOutput(output telegraf.Output, config *OutputConfig, batchSize, b
 
 	writeErrorsRegister := selfstat.Register("write", "errors", tags)
 	logger := logging.New("outputs", config.Name, config.Alias)
+	logger.SetID(config.ID)
 	logger.RegisterErrorCallback(func() {
 		writeErrorsRegister.Incr(1)
 	})
@@ -100,7 +131,11 @@ func NewRunningOutput(output telegraf.Output, config *OutputConfig, batchSize, b
 		batchSize = DefaultMetricBatchSize
 	}
 
-	b, err := NewBuffer(config.Name, config.ID, config.Alias, bufferLimit, config.BufferStrategy, config.BufferDirectory)
+	strategy := config.BufferStrategy
+	if config.MemoryBuffer {
+		strategy = "memory"
+	}
+	b, err := newOutputBuffer(config, bufferLimit, strategy)
 	if err != nil {
 		panic(err)
 	}
@@ -129,10 +164,43 @@ func NewRunningOutput(output telegraf.Output, config *OutputConfig, batchSize, b
 		),
 		log: logger,
 	}
+	if config.Cardinality != nil {
+		ro.cardinality = newCardinalityLimiter(config.Cardinality, tags, logger)
+	}
 
 	return ro
 }
 
+// newOutputBuffer creates the buffer of an output using the given strategy.
+func newOutputBuffer(config *OutputConfig, capacity int, strategy string) (Buffer, error) {
+	diskCfg := DiskBufferConfig{
+		MaxSize:        config.BufferMaxDiskSize,
+		EvictionPolicy: config.BufferEvictionPolicy,
+		Compression:    config.BufferCompression,
+	}
+	return NewBuffer(config.Name, config.ID, config.Alias, capacity, strategy, config.BufferDirectory, diskCfg)
+}
+
+// OpenBuffer replaces the memory buffer of an output created with the
+// MemoryBuffer setting by a buffer using the configured strategy. Call this
+// before adding any metrics to the output.
+func (r *RunningOutput) OpenBuffer() error {
+	if !r.Config.MemoryBuffer {
+		return nil
+	}
+
+	b, err := newOutputBuffer(r.Config, r.MetricBufferLimit, r.Config.BufferStrategy)
+	if err != nil {
+		return err
+	}
+	if err := r.buffer.Close(); err != nil {
+		r.log.Errorf("Error closing output buffer: %v", err)
+	}
+	r.buffer = b
+	r.Config.MemoryBuffer = false
+	return nil
+}
+
 func (r *RunningOutput) LogName() string {
 	return logName("outputs", r.Config.Name, r.Config.Alias)
 }
@@ -156,6 +224,18 @@ func (r *RunningOutput) Init() error {
 		return fmt.Errorf("invalid 'startup_error_behavior' setting %q", r.Config.StartupErrorBehavior)
 	}
 
+	if r.Config.Retry != nil {
+		if err := r.Config.Retry.init(); err != nil {
+			return err
+		}
+	}
+
+	if r.Config.Cardinality != nil {
+		if err := r.Config.Cardinality.init(); err != nil {
+			return err
+		}
+	}
+
 	if p, ok := r.Output.(telegraf.Initializer); ok {
 		err := p.Init()
 		if err != nil {
@@ -206,6 +286,15 @@ func (r *RunningOutput) Close() {
 	}
 }
 
+// Discard releases the resources held by an output that was never connected,
+// e.g. an instance superseded by an identical and already running output when
+// reloading the configuration.
+func (r *RunningOutput) Discard() {
+	if err := r.buffer.Close(); err != nil {
+		r.log.Errorf("Error closing output buffer: %v", err)
+	}
+}
+
 // AddMetric adds a metric to the output.
 // The given metric will be copied if the output selects the metric.
 func (r *RunningOutput) AddMetric(metric telegraf.Metric) {
@@ -235,12 +324,25 @@ func (r *RunningOutput) AddMetricNoCopy(metric telegraf.Metric) {
 }
 
 func (r *RunningOutput) add(metric telegraf.Metric) {
+	if err := r.Config.Filter.Transform(metric); err != nil {
+		r.log.Errorf("transforming failed: %v", err)
+	}
 	r.Config.Filter.Modify(metric)
 	if len(metric.FieldList()) == 0 {
 		r.metricFiltered(metric)
 		return
 	}
 
+	if r.cardinality != nil && !r.cardinality.apply(metric, time.Now()) {
+		r.metricFiltered(metric)
+		return
+	}
+
+	r.store(metric)
+}
+
+// store passes the metric to the aggregating output or the buffer.
+func (r *RunningOutput) store(metric telegraf.Metric) {
 	if output, ok := r.Output.(telegraf.AggregatingOutput); ok {
 		r.aggMutex.Lock()
 		output.Add(metric)
@@ -276,6 +378,25 @@ func (r *RunningOutput) add(metric telegraf.Metric) {
 // Write writes all metrics to the output, stopping when all have been sent on
 // or error.
 func (r *RunningOutput) Write() error {
+	err := r.write(false)
+	if err != nil {
+		r.lastError.set(err)
+	}
+	return err
+}
+
+// Flush writes all metrics to the output like Write but ignores a delay due
+// to previously failed attempts. Use this for final and manually requested
+// flushes that must not be skipped.
+func (r *RunningOutput) Flush() error {
+	err := r.write(true)
+	if err != nil {
+		r.lastError.set(err)
+	}
+	return err
+}
+
+func (r *RunningOutput) write(force bool) error {
 	// Try to connect if we are not yet started up
 	if !r.started {
 		r.retries++
@@ -292,6 +413,8 @@ func (r *RunningOutput) Write() error {
 		}
 	}
 
+	r.addCardinalityOverflow(force)
+
 	if output, ok := r.Output.(telegraf.AggregatingOutput); ok {
 		r.aggMutex.Lock()
 		metrics := output.Push()
@@ -300,6 +423,10 @@ func (r *RunningOutput) Write() error {
 		r.aggMutex.Unlock()
 	}
 
+	if !force && r.backingOff() {
+		return nil
+	}
+
 	atomic.StoreInt64(&r.newMetricsCount, 0)
 
 	// Only process the metrics in the buffer now. Metrics added while we are
@@ -313,6 +440,7 @@ func (r *RunningOutput) Write() error {
 		}
 		err := r.writeMetrics(tx.Batch)
 		r.updateTransaction(tx, err)
+		r.updateRetry(tx, err)
 		r.buffer.EndTransaction(tx)
 		if err != nil {
 			return err
@@ -323,6 +451,14 @@ func (r *RunningOutput) Write() error {
 
 // WriteBatch writes a single batch of metrics to the output.
 func (r *RunningOutput) WriteBatch() error {
+	err := r.writeBatch()
+	if err != nil {
+		r.lastError.set(err)
+	}
+	return err
+}
+
+func (r *RunningOutput) writeBatch() error {
 	// Try to connect if we are not yet started up
 	if !r.started {
 		r.retries++
@@ -334,17 +470,35 @@ func (r *RunningOutput) WriteBatch() error {
 		r.log.Debugf("Successfully connected after %d attempts", r.retries)
 	}
 
+	r.addCardinalityOverflow(false)
+
+	if r.backingOff() {
+		return nil
+	}
+
 	tx := r.buffer.BeginTransaction(r.MetricBatchSize)
 	if len(tx.Batch) == 0 {
 		return nil
 	}
 	err := r.writeMetrics(tx.Batch)
 	r.updateTransaction(tx, err)
+	r.updateRetry(tx, err)
 	r.buffer.EndTransaction(tx)
 
 	return err
 }
 
+// addCardinalityOverflow stores the series aggregating the metrics exceeding
+// the cardinality limit once per window or, if forced, immediately.
+func (r *RunningOutput) addCardinalityOverflow(force bool) {
+	if r.cardinality == nil {
+		return
+	}
+	for _, m := range r.cardinality.overflowMetrics(time.Now(), force) {
+		r.store(m)
+	}
+}
+
 func (r *RunningOutput) writeMetrics(metrics []telegraf.Metric) error {
 	dropped := atomic.LoadInt64(&r.droppedMetrics)
 	if dropped > 0 {
@@ -363,7 +517,7 @@ func (r *RunningOutput) writeMetrics(metrics []telegraf.Metric) error {
 	return err
 }
 
-func (*RunningOutput) updateTransaction(tx *Transaction, err error) {
+func (r *RunningOutput) updateTransaction(tx *Transaction, err error) {
 	// No error indicates all metrics were written successfully
 	if err == nil {
 		tx.AcceptAll()
@@ -381,11 +535,88 @@ func (*RunningOutput) updateTransaction(tx *Transaction, err error) {
 	// Transfer the accepted and rejected indices based on the write error values
 	tx.Accept = writeErr.MetricsAccept
 	tx.Reject = writeErr.MetricsReject
+
+	if r.OnReject != nil && len(tx.Reject) > 0 {
+		r.OnReject(rejectedMetrics(tx, writeErr))
+	}
+}
+
+// rejectedMetrics returns copies of the rejected metrics of the transaction
+// tagged with the reason of the rejection.
+func rejectedMetrics(tx *Transaction, writeErr *internal.PartialWriteError) []telegraf.Metric {
+	metrics := make([]telegraf.Metric, 0, len(tx.Reject))
+	for i, idx := range tx.Reject {
+		reason := writeErr.Err
+		if i < len(writeErr.MetricsRejectErrors) && writeErr.MetricsRejectErrors[i] != nil {
+			reason = writeErr.MetricsRejectErrors[i]
+		}
+
+		m := untrackedCopy(tx.Batch[idx])
+		if reason != nil {
+			m.AddTag(RejectReasonTag, reason.Error())
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+// backingOff returns true if writing should be delayed after failed attempts.
+func (r *RunningOutput) backingOff() bool {
+	if r.retry.next.IsZero() || !time.Now().Before(r.retry.next) {
+		return false
+	}
+	r.log.Debugf("Delaying write after %d failed attempts until %s", r.retry.attempts, r.retry.next.Format(time.RFC3339))
+	return true
+}
+
+// updateRetry updates the retry state after writing the given transaction.
+// If the batch exceeds the maximum number of attempts, the kept metrics are
+// removed from the buffer and passed to the dead-letter destination.
+func (r *RunningOutput) updateRetry(tx *Transaction, err error) {
+	cfg := r.Config.Retry
+	if cfg == nil {
+		return
+	}
+
+	// Any accepted metric means the output is making progress
+	if err == nil || len(tx.Accept) > 0 {
+		r.retry = retryState{}
+		return
+	}
+
+	r.retry.attempts++
+	if cfg.MaxAttempts > 0 && r.retry.attempts >= cfg.MaxAttempts {
+		keep := tx.InferKeep()
+		r.log.Errorf("Giving up on batch of %d metrics after %d attempts: %v", len(keep), r.retry.attempts, err)
+		if r.DeadLetter != nil && len(keep) > 0 {
+			metrics := make([]telegraf.Metric, 0, len(keep))
+			for _, idx := range keep {
+				metrics = append(metrics, untrackedCopy(tx.Batch[idx]))
+			}
+			r.DeadLetter(metrics)
+		}
+		tx.Reject = append(tx.Reject, keep...)
+		r.retry = retryState{}
+		return
+	}
+
+	delay := cfg.delay(r.retry.attempts)
+	r.retry.next = time.Now().Add(delay)
+	r.log.Warnf("Write attempt %d failed, retrying in %s", r.retry.attempts, delay.Round(time.Millisecond))
+}
+
+// untrackedCopy returns a copy of the given metric without the tracking
+// information.
+func untrackedCopy(m telegraf.Metric) telegraf.Metric {
+	if wm, ok := m.(telegraf.UnwrappableMetric); ok {
+		return wm.Unwrap().Copy()
+	}
+	return m.Copy()
 }
 
 func (r *RunningOutput) LogBufferStatus() {
 	nBuffer := r.buffer.Len()
-	if r.Config.BufferStrategy == "disk" {
+	if r.Config.BufferStrategy == "disk" || r.Config.BufferStrategy == "hybrid" {
 		r.log.Debugf("Buffer fullness: %d metrics", nBuffer)
 	} else {
 		r.log.Debugf("Buffer fullness: %d / %d metrics", nBuffer, r.MetricBufferLimit)
@@ -399,3 +630,9 @@ func (r *RunningOutput) Log() telegraf.Logger {
 func (r *RunningOutput) BufferLength() int {
 	return r.buffer.Len()
 }
+
+// LastError returns the time and the most recent write error. The error is
+// nil if writing never failed.
+func (r *RunningOutput) LastError() (time.Time, error) {
+	return r.lastError.get()
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"errors"
+	"os"
 	"sync"
 	"testing"
 	"time"
@@ -864,6 +865,172 @@ func TestRunningOutputWriteBatchPartialSuccessAndLoss(t *testing.T) {
 	require.Zero(t, model.buffer.Len())
 }
 
+func TestRunningOutputOpenBuffer(t *testing.T) {
+	directory := t.TempDir()
+	conf := &OutputConfig{
+		Name:            "test",
+		ID:              "open_buffer",
+		BufferStrategy:  "disk",
+		BufferDirectory: directory,
+		MemoryBuffer:    true,
+	}
+
+	m := &mockOutput{}
+	ro := NewRunningOutput(m, conf, 100, 1000)
+	defer ro.Close()
+
+	// No buffer files must be created before opening the buffer
+	entries, err := os.ReadDir(directory)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	require.NoError(t, ro.OpenBuffer())
+	require.False(t, ro.Config.MemoryBuffer)
+	require.IsType(t, &DiskBuffer{}, ro.buffer)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	require.NoError(t, ro.Write())
+	require.Equal(t, first5, m.Metrics())
+}
+
+func TestRunningOutputRetryBackoff(t *testing.T) {
+	conf := &OutputConfig{
+		Retry: &RetryConfig{InitialDelay: time.Hour},
+	}
+
+	m := &mockOutput{batchAcceptSize: -1}
+	ro := NewRunningOutput(m, conf, 100, 1000)
+	require.NoError(t, ro.Init())
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+
+	// The first attempt fails and delays further writes
+	require.Error(t, ro.Write())
+	require.Equal(t, 1, m.writes)
+	require.NoError(t, ro.Write())
+	require.NoError(t, ro.WriteBatch())
+	require.Equal(t, 1, m.writes)
+	require.Equal(t, 5, ro.BufferLength())
+
+	// Writing again after the delay succeeds and resets the state
+	ro.retry.next = time.Now()
+	m.batchAcceptSize = 0
+	require.NoError(t, ro.Write())
+	require.Equal(t, 2, m.writes)
+	require.Equal(t, first5, m.Metrics())
+	require.Equal(t, retryState{}, ro.retry)
+}
+
+func TestRunningOutputRetryFlush(t *testing.T) {
+	conf := &OutputConfig{
+		Retry: &RetryConfig{InitialDelay: time.Hour},
+	}
+
+	m := &mockOutput{batchAcceptSize: -1}
+	ro := NewRunningOutput(m, conf, 100, 1000)
+	require.NoError(t, ro.Init())
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	require.Error(t, ro.Write())
+	require.Equal(t, 1, m.writes)
+
+	// Flushing ignores the delay of the failed attempt
+	m.batchAcceptSize = 0
+	require.NoError(t, ro.Flush())
+	require.Equal(t, 2, m.writes)
+	require.Equal(t, first5, m.Metrics())
+	require.Zero(t, ro.BufferLength())
+}
+
+func TestRunningOutputRetryDeadLetter(t *testing.T) {
+	conf := &OutputConfig{
+		Retry: &RetryConfig{InitialDelay: time.Nanosecond, MaxAttempts: 2},
+	}
+
+	m := &mockOutput{batchAcceptSize: -1}
+	ro := NewRunningOutput(m, conf, 100, 1000)
+	require.NoError(t, ro.Init())
+
+	var dead []telegraf.Metric
+	ro.DeadLetter = func(metrics []telegraf.Metric) {
+		dead = append(dead, metrics...)
+	}
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+
+	require.Error(t, ro.Write())
+	require.Empty(t, dead)
+	require.Equal(t, 5, ro.BufferLength())
+
+	// Exceeding the maximum attempts passes the batch to the dead-letter
+	// destination and removes it from the buffer
+	time.Sleep(time.Millisecond)
+	require.Error(t, ro.Write())
+	require.Equal(t, 2, m.writes)
+	testutil.RequireMetricsEqual(t, first5, dead)
+	require.Zero(t, ro.BufferLength())
+	require.Equal(t, retryState{}, ro.retry)
+}
+
+func TestRetryConfigDelay(t *testing.T) {
+	cfg := &RetryConfig{InitialDelay: time.Second, MaxDelay: 10 * time.Second}
+	require.NoError(t, cfg.init())
+	require.Equal(t, time.Second, cfg.delay(1))
+	require.Equal(t, 2*time.Second, cfg.delay(2))
+	require.Equal(t, 8*time.Second, cfg.delay(4))
+	require.Equal(t, 10*time.Second, cfg.delay(5))
+
+	cfg.Jitter = 0.5
+	for range 100 {
+		d := cfg.delay(2)
+		require.GreaterOrEqual(t, d, time.Second)
+		require.LessOrEqual(t, d, 3*time.Second)
+	}
+}
+
+func TestRetryConfigInvalid(t *testing.T) {
+	require.ErrorContains(t, (&RetryConfig{Jitter: 2}).init(), "invalid retry jitter")
+	require.ErrorContains(t, (&RetryConfig{Multiplier: 0.5}).init(), "invalid retry multiplier")
+	require.ErrorContains(t, (&RetryConfig{InitialDelay: time.Hour, MaxDelay: time.Minute}).init(), "maximum delay")
+}
+
+func TestRunningOutputOnReject(t *testing.T) {
+	lost := 0
+	plugin := &mockOutput{
+		batchAcceptSize:  4,
+		metricFatalIndex: &lost,
+	}
+	model := NewRunningOutput(plugin, &OutputConfig{}, 5, 10)
+	require.NoError(t, model.Init())
+	require.NoError(t, model.Connect())
+	defer model.Close()
+
+	var rejected []telegraf.Metric
+	model.OnReject = func(metrics []telegraf.Metric) {
+		rejected = append(rejected, metrics...)
+	}
+
+	for _, metric := range first5 {
+		model.AddMetric(metric)
+	}
+	require.ErrorIs(t, model.Write(), internal.ErrSizeLimitReached)
+
+	// The rejected metric is forwarded with the reason and the original
+	// metric is left untouched
+	expected := first5[0].Copy()
+	expected.AddTag(RejectReasonTag, internal.ErrSizeLimitReached.Error())
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, rejected)
+	require.False(t, first5[0].HasTag(RejectReasonTag))
+}
+
 // Benchmark adding metrics.
 func BenchmarkRunningOutputAddWrite(b *testing.B) {
 	conf := &OutputConfig{
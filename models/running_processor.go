@@ -5,6 +5,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	logging "github.com/influxdata/telegraf/logger"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
@@ -40,6 +41,7 @@ func NewRunningProcessor(processor telegraf.StreamingProcessor, config *Processo
 
 	processErrorsRegister := selfstat.Register("process", "errors", tags)
 	logger := logging.New("processors", config.Name, config.Alias)
+	logger.SetID(config.ID)
 	logger.RegisterErrorCallback(func() {
 		processErrorsRegister.Incr(1)
 	})
@@ -102,6 +104,9 @@ func (rp *RunningProcessor) Add(m telegraf.Metric, acc telegraf.Accumulator) err
 		return nil
 	}
 
+	if err := rp.Config.Filter.Transform(m); err != nil {
+		rp.log.Errorf("transforming failed: %v", err)
+	}
 	rp.Config.Filter.Modify(m)
 	if len(m.FieldList()) == 0 {
 		// drop metric
@@ -109,6 +114,9 @@ func (rp *RunningProcessor) Add(m telegraf.Metric, acc telegraf.Accumulator) err
 		return nil
 	}
 
+	if route := metric.Route(m); route != "" {
+		acc = &routeAccumulator{Accumulator: acc, route: route}
+	}
 	return rp.Processor.Add(m, acc)
 }
 
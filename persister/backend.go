@@ -0,0 +1,150 @@
+package persister
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// stateVersion is the version of the state format written by the persister.
+// Files written by older versions without a version field contain a plain
+// map of plugin IDs to base64 encoded states.
+const stateVersion = 1
+
+// stateFile is the format of the states stored as a single document.
+type stateFile struct {
+	Version int                        `json:"version"`
+	States  map[string]json.RawMessage `json:"states"`
+}
+
+// Backend stores the serialized states of plugins.
+type Backend interface {
+	// Load returns the serialized states by plugin ID. Errors wrap
+	// os.ErrNotExist if no states were stored yet.
+	Load() (map[string][]byte, error)
+
+	// Store writes the given serialized states by plugin ID.
+	Store(states map[string][]byte) error
+}
+
+// BackendCreator creates a backend using the settings of the given persister
+type BackendCreator func(p *Persister) (Backend, error)
+
+// Backends contains the registry of the backends available in addition to the
+// built-in "file" and "directory" backends
+var Backends = make(map[string]BackendCreator)
+
+// AddBackend adds a backend to the registry. Usually this function is called
+// in the backend's init function
+func AddBackend(name string, creator BackendCreator) {
+	Backends[name] = creator
+}
+
+// fileBackend stores the states of all plugins in a single file.
+type fileBackend struct {
+	filename string
+}
+
+func (b *fileBackend) Load() (map[string][]byte, error) {
+	in, err := os.ReadFile(b.filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading states file failed: %w", err)
+	}
+	return DecodeStates(in)
+}
+
+// Store writes the states to a temporary file first which then replaces the
+// state file, so the state file is never left in an incomplete state.
+func (b *fileBackend) Store(states map[string][]byte) error {
+	serialized, err := EncodeStates(states)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(b.filename, serialized)
+}
+
+// EncodeStates serializes the given states into a versioned document as
+// stored by the "file" backend.
+func EncodeStates(states map[string][]byte) ([]byte, error) {
+	file := stateFile{
+		Version: stateVersion,
+		States:  make(map[string]json.RawMessage, len(states)),
+	}
+	for id, state := range states {
+		file.States[id] = state
+	}
+
+	serialized, err := json.Marshal(file)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling states failed: %w", err)
+	}
+	return serialized, nil
+}
+
+// DecodeStates returns the serialized states of the given document by plugin
+// ID. Documents of older versions are supported.
+func DecodeStates(in []byte) (map[string][]byte, error) {
+	var file stateFile
+	if err := json.Unmarshal(in, &file); err != nil {
+		return nil, fmt.Errorf("unmarshalling states failed: %w", err)
+	}
+
+	switch file.Version {
+	case 0:
+		// Unmarshal the id to serialized states map of older versions
+		var states map[string][]byte
+		if err := json.Unmarshal(in, &states); err != nil {
+			return nil, fmt.Errorf("unmarshalling states failed: %w", err)
+		}
+		return states, nil
+	case stateVersion:
+		states := make(map[string][]byte, len(file.States))
+		for id, serialized := range file.States {
+			states[id] = serialized
+		}
+		return states, nil
+	}
+	return nil, fmt.Errorf("unsupported states file version %d", file.Version)
+}
+
+// writeFileAtomic writes the given data to a temporary file in the same
+// directory first which then replaces the file with the given name, so the
+// file is never left in an incomplete state.
+func writeFileAtomic(filename string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating temporary file failed: %w", err)
+	}
+	tmpfile := f.Name()
+
+	// Keep the permissions of an existing file
+	if info, err := os.Stat(filename); err == nil {
+		if err := f.Chmod(info.Mode().Perm()); err != nil {
+			f.Close()
+			os.Remove(tmpfile)
+			return fmt.Errorf("setting permissions of temporary file failed: %w", err)
+		}
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmpfile)
+		return fmt.Errorf("writing temporary file failed: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmpfile)
+		return fmt.Errorf("syncing temporary file failed: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpfile)
+		return fmt.Errorf("closing temporary file failed: %w", err)
+	}
+
+	if err := os.Rename(tmpfile, filename); err != nil {
+		os.Remove(tmpfile)
+		return fmt.Errorf("replacing file %q failed: %w", filename, err)
+	}
+	return nil
+}
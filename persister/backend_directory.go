@@ -0,0 +1,49 @@
+package persister
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// directoryBackend stores the state of each plugin in a separate file named
+// by the plugin ID. States of plugins not registered anymore are kept.
+type directoryBackend struct {
+	path string
+}
+
+func (b *directoryBackend) Load() (map[string][]byte, error) {
+	entries, err := os.ReadDir(b.path)
+	if err != nil {
+		return nil, fmt.Errorf("reading states directory failed: %w", err)
+	}
+
+	states := make(map[string][]byte, len(entries))
+	for _, entry := range entries {
+		id, found := strings.CutSuffix(entry.Name(), ".json")
+		if !found || !entry.Type().IsRegular() {
+			continue
+		}
+
+		state, err := os.ReadFile(filepath.Join(b.path, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("reading state of %q failed: %w", id, err)
+		}
+		states[id] = state
+	}
+	return states, nil
+}
+
+func (b *directoryBackend) Store(states map[string][]byte) error {
+	if err := os.MkdirAll(b.path, 0750); err != nil {
+		return fmt.Errorf("creating states directory failed: %w", err)
+	}
+
+	for id, state := range states {
+		if err := writeFileAtomic(filepath.Join(b.path, id+".json"), state); err != nil {
+			return fmt.Errorf("writing state of %q failed: %w", id, err)
+		}
+	}
+	return nil
+}
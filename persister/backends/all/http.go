@@ -0,0 +1,5 @@
+//go:build !custom || persister || persister.http
+
+package all
+
+import _ "github.com/influxdata/telegraf/persister/backends/http" // register backend
@@ -0,0 +1,5 @@
+//go:build !custom || persister || persister.sqlite
+
+package all
+
+import _ "github.com/influxdata/telegraf/persister/backends/sqlite" // register backend
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_signer "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	aws_config "github.com/aws/aws-sdk-go-v2/config"
+
+	"github.com/influxdata/telegraf/persister"
+)
+
+// backend stores the states of all plugins as a single document at an
+// HTTP endpoint using GET and PUT requests, e.g. an object in an S3 bucket.
+type backend struct {
+	url     *url.URL
+	headers map[string]string
+	region  string
+	awsCfg  *aws.Config
+	client  *http.Client
+}
+
+func newBackend(address string, headers map[string]string, region string) (*backend, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("parsing state URL failed: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid scheme %q for state URL", u.Scheme)
+	}
+
+	b := &backend{
+		url:     u,
+		headers: headers,
+		region:  region,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+
+	// Sign the requests for S3 using the default credential chain, e.g. the
+	// environment or the instance role
+	if region != "" {
+		cfg, err := aws_config.LoadDefaultConfig(context.Background(), aws_config.WithRegion(region))
+		if err != nil {
+			return nil, fmt.Errorf("loading AWS credentials failed: %w", err)
+		}
+		b.awsCfg = &cfg
+	}
+
+	return b, nil
+}
+
+func (b *backend) Load() (map[string][]byte, error) {
+	resp, err := b.request(http.MethodGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading states failed: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("states not found at %q: %w", b.url.Redacted(), os.ErrNotExist)
+	default:
+		return nil, fmt.Errorf("loading states failed with status %q: %s", resp.Status, body)
+	}
+	return persister.DecodeStates(body)
+}
+
+func (b *backend) Store(states map[string][]byte) error {
+	serialized, err := persister.EncodeStates(states)
+	if err != nil {
+		return err
+	}
+
+	resp, err := b.request(http.MethodPut, serialized)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		//nolint:errcheck // the body is only used for the error message
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("storing states failed with status %q: %s", resp.Status, body)
+	}
+	return nil
+}
+
+func (b *backend) request(method string, body []byte) (*http.Response, error) {
+	ctx := context.Background()
+
+	req, err := http.NewRequestWithContext(ctx, method, b.url.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("creating request failed: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range b.headers {
+		req.Header.Set(k, v)
+	}
+
+	if b.awsCfg != nil {
+		credentials, err := b.awsCfg.Credentials.Retrieve(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("retrieving AWS credentials failed: %w", err)
+		}
+
+		// The signature scheme requires the hex encoded sha256 of the body
+		sum := sha256.Sum256(body)
+		hash := hex.EncodeToString(sum[:])
+		req.Header.Set("X-Amz-Content-Sha256", hash)
+
+		signer := aws_signer.NewSigner()
+		if err := signer.SignHTTP(ctx, credentials, req, hash, "s3", b.region, time.Now().UTC()); err != nil {
+			return nil, fmt.Errorf("signing request failed: %w", err)
+		}
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sending request to %q failed: %w", b.url.Redacted(), err)
+	}
+	return resp, nil
+}
+
+func init() {
+	persister.AddBackend("http", func(p *persister.Persister) (persister.Backend, error) {
+		return newBackend(p.Filename, p.Headers, p.S3Region)
+	})
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/persister"
+)
+
+func TestStoreLoad(t *testing.T) {
+	var stored []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			if stored == nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(stored) //nolint:errcheck // ignore the returned error as we cannot do anything about it anyway
+		case http.MethodPut:
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			stored = body
+		}
+	}))
+	defer server.Close()
+
+	p := &persister.Persister{
+		Filename: server.URL + "/state.json",
+		Backend:  "http",
+		Headers:  map[string]string{"Authorization": "Bearer secret"},
+	}
+	b, err := persister.Backends["http"](p)
+	require.NoError(t, err)
+
+	// Loading must report missing states
+	_, err = b.Load()
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	states := map[string][]byte{"a": []byte(`{"offset":1}`)}
+	require.NoError(t, b.Store(states))
+
+	actual, err := b.Load()
+	require.NoError(t, err)
+	require.Equal(t, states, actual)
+}
+
+func TestInvalidScheme(t *testing.T) {
+	_, err := newBackend("ftp://localhost/state.json", nil, "")
+	require.ErrorContains(t, err, "invalid scheme")
+}
@@ -0,0 +1,97 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/influxdata/telegraf/persister"
+)
+
+// backend stores the states of the plugins in a SQLite database with
+// one row per plugin ID. States of plugins not registered anymore are kept.
+type backend struct {
+	filename string
+}
+
+func (b *backend) open() (*sql.DB, error) {
+	db, err := sql.Open("sqlite", b.filename)
+	if err != nil {
+		return nil, fmt.Errorf("opening states database failed: %w", err)
+	}
+
+	query := "CREATE TABLE IF NOT EXISTS states (id TEXT PRIMARY KEY, state BLOB NOT NULL)"
+	if _, err := db.Exec(query); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating states table failed: %w", err)
+	}
+	return db, nil
+}
+
+func (b *backend) Load() (map[string][]byte, error) {
+	// Do not create an empty database when loading
+	if _, err := os.Stat(b.filename); err != nil {
+		return nil, fmt.Errorf("opening states database failed: %w", err)
+	}
+
+	db, err := b.open()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT id, state FROM states")
+	if err != nil {
+		return nil, fmt.Errorf("querying states failed: %w", err)
+	}
+	defer rows.Close()
+
+	states := make(map[string][]byte)
+	for rows.Next() {
+		var id string
+		var state []byte
+		if err := rows.Scan(&id, &state); err != nil {
+			return nil, fmt.Errorf("reading states failed: %w", err)
+		}
+		states[id] = state
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading states failed: %w", err)
+	}
+	return states, nil
+}
+
+func (b *backend) Store(states map[string][]byte) error {
+	db, err := b.open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Write all states in a single transaction to not end up with a mix of
+	// old and new states
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("starting transaction failed: %w", err)
+	}
+	//nolint:errcheck // rollback is a no-op after committing
+	defer tx.Rollback()
+
+	query := "INSERT INTO states (id, state) VALUES (?, ?) ON CONFLICT(id) DO UPDATE SET state = excluded.state"
+	for id, state := range states {
+		if _, err := tx.Exec(query, id, state); err != nil {
+			return fmt.Errorf("writing state of %q failed: %w", id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing states failed: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	persister.AddBackend("sqlite", func(p *persister.Persister) (persister.Backend, error) {
+		return &backend{filename: p.Filename}, nil
+	})
+}
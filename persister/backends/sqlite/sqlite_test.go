@@ -0,0 +1,28 @@
+package sqlite
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "states.db")
+	b := &backend{filename: filename}
+
+	// Loading must report missing states without creating the database
+	_, err := b.Load()
+	require.ErrorIs(t, err, os.ErrNotExist)
+	require.NoFileExists(t, filename)
+
+	require.NoError(t, b.Store(map[string][]byte{"a": []byte(`{"offset":1}`), "b": []byte(`{"offset":2}`)}))
+	require.NoError(t, b.Store(map[string][]byte{"a": []byte(`{"offset":3}`)}))
+
+	// States of plugins not stored anymore are kept
+	states, err := b.Load()
+	require.NoError(t, err)
+	expected := map[string][]byte{"a": []byte(`{"offset":3}`), "b": []byte(`{"offset":2}`)}
+	require.Equal(t, expected, states)
+}
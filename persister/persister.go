@@ -3,25 +3,61 @@ package persister
 import (
 	"encoding/json"
 	"fmt"
-	"os"
+	"log"
 	"reflect"
+	"sync"
 
 	"github.com/influxdata/telegraf"
 )
 
 type Persister struct {
+	// Filename is the location of the states, i.e. the file, directory or
+	// URL depending on the backend.
 	Filename string
 
+	// Backend is the type of storage for the states, "file" by default.
+	Backend string
+
+	// Headers are additional headers to send with requests of the "http"
+	// backend.
+	Headers map[string]string
+
+	// S3Region enables signing requests of the "http" backend for storing
+	// the states in an S3 bucket of the given region.
+	S3Region string
+
+	backend  Backend
 	register map[string]telegraf.StatefulPlugin
+	mu       sync.Mutex
 }
 
 func (p *Persister) Init() error {
+	switch p.Backend {
+	case "", "file":
+		p.backend = &fileBackend{filename: p.Filename}
+	case "directory":
+		p.backend = &directoryBackend{path: p.Filename}
+	default:
+		creator, found := Backends[p.Backend]
+		if !found {
+			return fmt.Errorf("invalid state backend %q", p.Backend)
+		}
+		b, err := creator(p)
+		if err != nil {
+			return err
+		}
+		p.backend = b
+	}
+
 	p.register = make(map[string]telegraf.StatefulPlugin)
 
 	return nil
 }
 
 func (p *Persister) Register(id string, plugin telegraf.StatefulPlugin) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	if _, found := p.register[id]; found {
 		return fmt.Errorf("plugin with ID %q already registered", id)
 	}
@@ -30,18 +66,25 @@ func (p *Persister) Register(id string, plugin telegraf.StatefulPlugin) error {
 	return nil
 }
 
+func (p *Persister) Unregister(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.register, id)
+}
+
+// Load restores the states of the registered plugins from the backend.
+// Failing to restore the state of a single plugin does not prevent restoring
+// the states of the other plugins, instead the error is logged. Errors wrap
+// os.ErrNotExist if no states were stored yet.
 func (p *Persister) Load() error {
-	// Read the states from disk
-	in, err := os.ReadFile(p.Filename)
+	states, err := p.backend.Load()
 	if err != nil {
-		return fmt.Errorf("reading states file failed: %w", err)
+		return err
 	}
 
-	// Unmarshal the id to serialized states map
-	var states map[string][]byte
-	if err := json.Unmarshal(in, &states); err != nil {
-		return fmt.Errorf("unmarshalling states failed: %w", err)
-	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
 	// Get the initialized state as blueprint for unmarshalling
 	for id, serialized := range states {
@@ -51,53 +94,47 @@ func (p *Persister) Load() error {
 			continue
 		}
 
-		// Create a new empty state of the "state"-type. As we need a pointer
-		// of the state, we cannot dereference it here due to the unknown
-		// nature of the state-type.
-		nstate := reflect.New(reflect.TypeOf(plugin.GetState())).Interface()
-		if err := json.Unmarshal(serialized, &nstate); err != nil {
-			return fmt.Errorf("unmarshalling state for %q failed: %w", id, err)
+		if err := restore(plugin, serialized); err != nil {
+			log.Printf("E! [persister] Restoring state of plugin %q failed: %v", id, err)
 		}
-		state := reflect.ValueOf(nstate).Elem().Interface()
+	}
 
-		// Set the state in the plugin
-		if err := plugin.SetState(state); err != nil {
-			return fmt.Errorf("setting state of %q failed: %w", id, err)
-		}
+	return nil
+}
+
+// restore unmarshals the given state and sets it in the plugin.
+func restore(plugin telegraf.StatefulPlugin, serialized []byte) error {
+	// Create a new empty state of the "state"-type. As we need a pointer
+	// of the state, we cannot dereference it here due to the unknown
+	// nature of the state-type.
+	nstate := reflect.New(reflect.TypeOf(plugin.GetState())).Interface()
+	if err := json.Unmarshal(serialized, &nstate); err != nil {
+		return fmt.Errorf("unmarshalling state failed: %w", err)
 	}
+	state := reflect.ValueOf(nstate).Elem().Interface()
 
+	// Set the state in the plugin
+	if err := plugin.SetState(state); err != nil {
+		return fmt.Errorf("setting state failed: %w", err)
+	}
 	return nil
 }
 
+// Store writes the states of the registered plugins to the backend.
 func (p *Persister) Store() error {
 	states := make(map[string][]byte)
 
 	// Collect the states and serialize the individual data chunks
-	// to later serialize all items in the id / serialized-states map
+	p.mu.Lock()
 	for id, plugin := range p.register {
 		state, err := json.Marshal(plugin.GetState())
 		if err != nil {
+			p.mu.Unlock()
 			return fmt.Errorf("marshalling state for id %q failed: %w", id, err)
 		}
 		states[id] = state
 	}
+	p.mu.Unlock()
 
-	// Serialize the states
-	serialized, err := json.Marshal(states)
-	if err != nil {
-		return fmt.Errorf("marshalling states failed: %w", err)
-	}
-
-	// Write the states to disk
-	f, err := os.Create(p.Filename)
-	if err != nil {
-		return fmt.Errorf("creating states file %q failed: %w", p.Filename, err)
-	}
-	defer f.Close()
-
-	if _, err := f.Write(serialized); err != nil {
-		return fmt.Errorf("writing states failed: %w", err)
-	}
-
-	return nil
+	return p.backend.Store(states)
 }
@@ -0,0 +1,165 @@
+package persister
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockState struct {
+	Offset int64 `json:"offset"`
+}
+
+type mockPlugin struct {
+	state mockState
+	fail  bool
+}
+
+func (m *mockPlugin) GetState() interface{} {
+	return m.state
+}
+
+func (m *mockPlugin) SetState(state interface{}) error {
+	if m.fail {
+		return errors.New("failing on purpose")
+	}
+	m.state = state.(mockState)
+	return nil
+}
+
+func TestStoreLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+
+	store := &Persister{Filename: filename}
+	require.NoError(t, store.Init())
+	require.NoError(t, store.Register("a", &mockPlugin{state: mockState{Offset: 1}}))
+	require.NoError(t, store.Register("b", &mockPlugin{state: mockState{Offset: 2}}))
+	require.NoError(t, store.Store())
+
+	// Storing must replace the existing file without leaving temporary files
+	require.NoError(t, store.Store())
+	files, err := os.ReadDir(filepath.Dir(filename))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	buf, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"version":1,"states":{"a":{"offset":1},"b":{"offset":2}}}`, string(buf))
+
+	a := &mockPlugin{}
+	b := &mockPlugin{}
+	load := &Persister{Filename: filename}
+	require.NoError(t, load.Init())
+	require.NoError(t, load.Register("a", a))
+	require.NoError(t, load.Register("b", b))
+	require.NoError(t, load.Load())
+	require.Equal(t, mockState{Offset: 1}, a.state)
+	require.Equal(t, mockState{Offset: 2}, b.state)
+}
+
+func TestLoadLegacyFormat(t *testing.T) {
+	// States of older versions are base64 encoded, here '{"offset":42}'
+	filename := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(filename, []byte(`{"a":"eyJvZmZzZXQiOjQyfQ=="}`), 0640))
+
+	a := &mockPlugin{}
+	p := &Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register("a", a))
+	require.NoError(t, p.Load())
+	require.Equal(t, mockState{Offset: 42}, a.state)
+}
+
+func TestLoadIsolatesPluginErrors(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+	content := `{"version":1,"states":{"a":{"offset":"invalid"},"b":{"offset":2},"c":{"offset":3}}}`
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0640))
+
+	a := &mockPlugin{}
+	b := &mockPlugin{}
+	c := &mockPlugin{fail: true}
+	p := &Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register("a", a))
+	require.NoError(t, p.Register("b", b))
+	require.NoError(t, p.Register("c", c))
+	require.NoError(t, p.Load())
+	require.Equal(t, mockState{}, a.state)
+	require.Equal(t, mockState{Offset: 2}, b.state)
+	require.Equal(t, mockState{}, c.state)
+}
+
+func TestLoadUnsupportedVersion(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(filename, []byte(`{"version":99,"states":{}}`), 0640))
+
+	p := &Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.ErrorContains(t, p.Load(), "unsupported states file version 99")
+}
+
+func TestDirectoryBackend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "states")
+
+	// Loading must report missing states
+	a := &mockPlugin{}
+	load := &Persister{Filename: path, Backend: "directory"}
+	require.NoError(t, load.Init())
+	require.NoError(t, load.Register("a", a))
+	require.ErrorIs(t, load.Load(), os.ErrNotExist)
+
+	store := &Persister{Filename: path, Backend: "directory"}
+	require.NoError(t, store.Init())
+	require.NoError(t, store.Register("a", &mockPlugin{state: mockState{Offset: 1}}))
+	require.NoError(t, store.Store())
+
+	require.NoError(t, load.Load())
+	require.Equal(t, mockState{Offset: 1}, a.state)
+	require.FileExists(t, filepath.Join(path, "a.json"))
+}
+
+type mockBackend struct {
+	states map[string][]byte
+}
+
+func (b *mockBackend) Load() (map[string][]byte, error) {
+	if b.states == nil {
+		return nil, os.ErrNotExist
+	}
+	return b.states, nil
+}
+
+func (b *mockBackend) Store(states map[string][]byte) error {
+	b.states = states
+	return nil
+}
+
+func TestRegisteredBackend(t *testing.T) {
+	backend := &mockBackend{}
+	AddBackend("mock", func(*Persister) (Backend, error) {
+		return backend, nil
+	})
+	defer delete(Backends, "mock")
+
+	a := &mockPlugin{}
+	p := &Persister{Backend: "mock"}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register("a", a))
+	require.ErrorIs(t, p.Load(), os.ErrNotExist)
+
+	a.state = mockState{Offset: 1}
+	require.NoError(t, p.Store())
+	require.Contains(t, backend.states, "a")
+
+	a.state = mockState{}
+	require.NoError(t, p.Load())
+	require.Equal(t, mockState{Offset: 1}, a.state)
+}
+
+func TestInvalidBackend(t *testing.T) {
+	p := &Persister{Filename: "foo", Backend: "foo"}
+	require.ErrorContains(t, p.Init(), "invalid state backend")
+}
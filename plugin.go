@@ -50,6 +50,10 @@ type StatefulPlugin interface {
 	// your plugin.
 	// Note: This function has to be callable directly after the
 	// plugin's Init() function if there is any!
+	// Note: This function is called periodically while the plugin is
+	// running if the 'statefile_interval' agent setting is used, i.e.
+	// concurrently to the plugin's other functions! Return a copy of the
+	// state taken while holding the lock protecting it.
 	GetState() interface{}
 
 	// SetState is called by the Persister once after loading and
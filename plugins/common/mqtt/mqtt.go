@@ -29,7 +29,7 @@ type MqttConfig struct {
 	Password            config.Secret      `toml:"password"`
 	Timeout             config.Duration    `toml:"timeout"`
 	ConnectionTimeout   config.Duration    `toml:"connection_timeout"`
-	QoS                 int                `toml:"qos"`
+	QoS                 int                `toml:"qos" minimum:"0" maximum:"2"`
 	ClientID            string             `toml:"client_id"`
 	Retain              bool               `toml:"retain"`
 	KeepAlive           int64              `toml:"keep_alive"`
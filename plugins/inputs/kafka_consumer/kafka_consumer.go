@@ -47,7 +47,7 @@ type KafkaConsumer struct {
 	TopicTag                             string          `toml:"topic_tag"`
 	MsgHeadersAsTags                     []string        `toml:"msg_headers_as_tags"`
 	MsgHeaderAsMetricName                string          `toml:"msg_header_as_metric_name"`
-	TimestampSource                      string          `toml:"timestamp_source"`
+	TimestampSource                      string          `toml:"timestamp_source" enum:"metric,inner,outer"`
 	ConsumerFetchDefault                 config.Size     `toml:"consumer_fetch_default"`
 	ConnectionStrategy                   string          `toml:"connection_strategy" deprecated:"1.33.0;1.40.0;use 'startup_error_behavior' instead"`
 	ResolveCanonicalBootstrapServersOnly bool            `toml:"resolve_canonical_bootstrap_servers_only"`
@@ -38,7 +38,7 @@ type MQTTConsumer struct {
 	TopicParserConfig      []topicParsingConfig `toml:"topic_parsing"`
 	Username               config.Secret        `toml:"username"`
 	Password               config.Secret        `toml:"password"`
-	QoS                    int                  `toml:"qos"`
+	QoS                    int                  `toml:"qos" minimum:"0" maximum:"2"`
 	ConnectionTimeout      config.Duration      `toml:"connection_timeout"`
 	KeepAliveInterval      config.Duration      `toml:"keepalive"`
 	PingTimeout            config.Duration      `toml:"ping_timeout"`
@@ -29,15 +29,15 @@ const (
 )
 
 type Ping struct {
-	Urls         []string `toml:"urls"`          // URLs to ping
-	Method       string   `toml:"method"`        // Method defines how to ping (native or exec)
-	Count        int      `toml:"count"`         // Number of pings to send (ping -c <COUNT>)
-	PingInterval float64  `toml:"ping_interval"` // Interval at which to ping (ping -i <INTERVAL>)
-	Timeout      float64  `toml:"timeout"`       // Per-ping timeout, in seconds. 0 means no timeout (ping -W <TIMEOUT>)
-	Deadline     int      `toml:"deadline"`      // Ping deadline, in seconds. 0 means no deadline. (ping -w <DEADLINE>)
-	Interface    string   `toml:"interface"`     // Interface or source address to send ping from (ping -I/-S <INTERFACE/SRC_ADDR>)
-	Percentiles  []int    `toml:"percentiles"`   // Calculate the given percentiles when using native method
-	Binary       string   `toml:"binary"`        // Ping executable binary
+	Urls         []string `toml:"urls"`                      // URLs to ping
+	Method       string   `toml:"method" enum:"exec,native"` // Method defines how to ping (native or exec)
+	Count        int      `toml:"count" minimum:"1"`         // Number of pings to send (ping -c <COUNT>)
+	PingInterval float64  `toml:"ping_interval"`             // Interval at which to ping (ping -i <INTERVAL>)
+	Timeout      float64  `toml:"timeout"`                   // Per-ping timeout, in seconds. 0 means no timeout (ping -W <TIMEOUT>)
+	Deadline     int      `toml:"deadline"`                  // Ping deadline, in seconds. 0 means no deadline. (ping -w <DEADLINE>)
+	Interface    string   `toml:"interface"`                 // Interface or source address to send ping from (ping -I/-S <INTERFACE/SRC_ADDR>)
+	Percentiles  []int    `toml:"percentiles"`               // Calculate the given percentiles when using native method
+	Binary       string   `toml:"binary"`                    // Ping executable binary
 	// Arguments for ping command. When arguments are not empty, system binary will be used and other options (ping_interval, timeout, etc.) will be ignored
 	Arguments []string        `toml:"arguments"`
 	IPv4      bool            `toml:"ipv4"` // Whether to resolve addresses using ipv4 or not.
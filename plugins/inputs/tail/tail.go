@@ -38,9 +38,9 @@ var (
 type Tail struct {
 	Files               []string `toml:"files"`
 	FromBeginning       bool     `toml:"from_beginning" deprecated:"1.34.0;1.40.0;use 'initial_read_offset' with value 'beginning' instead"`
-	InitialReadOffset   string   `toml:"initial_read_offset"`
+	InitialReadOffset   string   `toml:"initial_read_offset" enum:"beginning,end,save-or-end,save-or-beginning"`
 	Pipe                bool     `toml:"pipe"`
-	WatchMethod         string   `toml:"watch_method"`
+	WatchMethod         string   `toml:"watch_method" enum:"inotify,poll"`
 	MaxUndeliveredLines int      `toml:"max_undelivered_lines"`
 	CharacterEncoding   string   `toml:"character_encoding"`
 	PathTag             string   `toml:"path_tag"`
@@ -51,6 +51,7 @@ type Tail struct {
 	Log        telegraf.Logger `toml:"-"`
 	tailers    map[string]*tail.Tail
 	offsets    map[string]int64
+	mu         sync.Mutex // protects the tailers and offsets
 	parserFunc telegraf.ParserFunc
 	wg         sync.WaitGroup
 
@@ -167,7 +168,23 @@ func (t *Tail) getSeekInfo(file string) (*tail.SeekInfo, error) {
 }
 
 func (t *Tail) GetState() interface{} {
-	return t.offsets
+	// The state is requested periodically while the plugin is running, so
+	// return a copy including the current positions of the running tailers
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state := make(map[string]int64, len(t.offsets)+len(t.tailers))
+	for k, v := range t.offsets {
+		state[k] = v
+	}
+	if !t.Pipe {
+		for _, tailer := range t.tailers {
+			if offset, err := tailer.Tell(); err == nil {
+				state[tailer.Filename] = offset
+			}
+		}
+	}
+	return state
 }
 
 func (t *Tail) SetState(state interface{}) error {
@@ -175,6 +192,9 @@ func (t *Tail) SetState(state interface{}) error {
 	if !ok {
 		return errors.New("state has to be of type 'map[string]int64'")
 	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	for k, v := range offsetsState {
 		t.offsets[k] = v
 	}
@@ -186,7 +206,10 @@ func (t *Tail) Gather(_ telegraf.Accumulator) error {
 }
 
 func (t *Tail) Stop() {
+	t.mu.Lock()
+	tailers := make([]*tail.Tail, 0, len(t.tailers))
 	for _, tailer := range t.tailers {
+		tailers = append(tailers, tailer)
 		if !t.Pipe {
 			// store offset for resume
 			offset, err := tailer.Tell()
@@ -197,6 +220,11 @@ func (t *Tail) Stop() {
 				t.Log.Errorf("Recording offset for %q: %s", tailer.Filename, err.Error())
 			}
 		}
+	}
+	t.tailers = make(map[string]*tail.Tail)
+	t.mu.Unlock()
+
+	for _, tailer := range tailers {
 		err := tailer.Stop()
 		if err != nil {
 			t.Log.Errorf("Stopping tail on %q: %s", tailer.Filename, err.Error())
@@ -208,9 +236,11 @@ func (t *Tail) Stop() {
 
 	// persist offsets
 	offsetsMutex.Lock()
+	t.mu.Lock()
 	for k, v := range t.offsets {
 		offsets[k] = v
 	}
+	t.mu.Unlock()
 	offsetsMutex.Unlock()
 }
 
@@ -220,6 +250,9 @@ func (t *Tail) tailNewFiles() error {
 		poll = true
 	}
 
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	// Create a "tailer" for each file
 	for _, filepath := range t.Files {
 		g, err := globpath.Compile(filepath)
@@ -277,7 +310,9 @@ func (t *Tail) tailNewFiles() error {
 				if err := tailer.Err(); err != nil {
 					if strings.HasSuffix(err.Error(), "permission denied") {
 						t.Log.Errorf("Deleting tailer for %q due to: %v", tailer.Filename, err)
+						t.mu.Lock()
 						delete(t.tailers, tailer.Filename)
+						t.mu.Unlock()
 					} else {
 						t.Log.Errorf("Tailing %q: %s", tailer.Filename, err.Error())
 					}
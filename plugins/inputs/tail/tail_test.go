@@ -785,6 +785,12 @@ func TestStatePersistence(t *testing.T) {
 	require.Eventuallyf(t, func() bool {
 		return acc.NMetrics() >= uint64(len(expected))
 	}, time.Second, 100*time.Millisecond, "Expected %d metrics found %d", len(expected), acc.NMetrics())
+
+	// The state of the running plugin contains the current position
+	require.Eventually(t, func() bool {
+		current, ok := pi.GetState().(map[string]int64)
+		return ok && current[inputFilename] == expectedState[inputFilename]
+	}, time.Second, 10*time.Millisecond)
 	plugin.Stop()
 
 	// Check the result
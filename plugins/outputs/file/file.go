@@ -24,7 +24,7 @@ type File struct {
 	RotationMaxSize      config.Size     `toml:"rotation_max_size"`
 	RotationMaxArchives  int             `toml:"rotation_max_archives"`
 	UseBatchFormat       bool            `toml:"use_batch_format"`
-	CompressionAlgorithm string          `toml:"compression_algorithm"`
+	CompressionAlgorithm string          `toml:"compression_algorithm" enum:"identity,gzip,zlib,zstd"`
 	CompressionLevel     int             `toml:"compression_level"`
 	Log                  telegraf.Logger `toml:"-"`
 
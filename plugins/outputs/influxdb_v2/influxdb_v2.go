@@ -38,7 +38,7 @@ type InfluxDB struct {
 	HTTPHeaders      map[string]string `toml:"http_headers"`
 	HTTPProxy        string            `toml:"http_proxy"`
 	UserAgent        string            `toml:"user_agent"`
-	ContentEncoding  string            `toml:"content_encoding"`
+	ContentEncoding  string            `toml:"content_encoding" enum:"gzip,identity"`
 	UintSupport      bool              `toml:"influx_uint_support"`
 	OmitTimestamp    bool              `toml:"influx_omit_timestamp"`
 	PingTimeout      config.Duration   `toml:"ping_timeout"`
@@ -28,13 +28,15 @@ type MQTT struct {
 	TopicPrefix     string          `toml:"topic_prefix" deprecated:"1.25.0;1.35.0;use 'topic' instead"`
 	Topic           string          `toml:"topic"`
 	BatchMessage    bool            `toml:"batch" deprecated:"1.25.2;1.35.0;use 'layout = \"batch\"' instead"`
-	Layout          string          `toml:"layout"`
+	Layout          string          `toml:"layout" enum:"batch,non-batch,field,homie-v4"`
 	HomieDeviceName string          `toml:"homie_device_name"`
 	HomieNodeID     string          `toml:"homie_node_id"`
 	Log             telegraf.Logger `toml:"-"`
 	mqtt.MqttConfig
 
 	client     mqtt.Client
+	newClient  func(*mqtt.MqttConfig) (mqtt.Client, error)
+	reconnect  bool
 	serializer telegraf.Serializer
 	generator  *TopicNameGenerator
 
@@ -98,6 +100,10 @@ func (m *MQTT) Init() error {
 		return fmt.Errorf("invalid layout %q", m.Layout)
 	}
 
+	if m.newClient == nil {
+		m.newClient = mqtt.NewClient
+	}
+
 	return nil
 }
 
@@ -107,7 +113,12 @@ func (m *MQTT) Connect() error {
 
 	m.homieSeen = make(map[string]map[string]bool)
 
-	client, err := mqtt.NewClient(&m.MqttConfig)
+	return m.connect()
+}
+
+// connect creates a new client and connects to the broker.
+func (m *MQTT) connect() error {
+	client, err := m.newClient(&m.MqttConfig)
 	if err != nil {
 		return err
 	}
@@ -117,6 +128,16 @@ func (m *MQTT) Connect() error {
 	return err
 }
 
+// SecretChanged reconnects to the broker on the next write to use the
+// changed credentials.
+func (m *MQTT) SecretChanged(ref string) {
+	m.Log.Debugf("Secret %s changed, reconnecting...", ref)
+
+	m.Lock()
+	defer m.Unlock()
+	m.reconnect = true
+}
+
 func (m *MQTT) SetSerializer(serializer telegraf.Serializer) {
 	m.serializer = serializer
 }
@@ -144,6 +165,16 @@ func (m *MQTT) Write(metrics []telegraf.Metric) error {
 		return nil
 	}
 
+	if m.reconnect {
+		if err := m.client.Close(); err != nil {
+			m.Log.Warnf("Closing connection for reconnecting failed: %v", err)
+		}
+		if err := m.connect(); err != nil {
+			return fmt.Errorf("reconnecting to MQTT server failed: %w", err)
+		}
+		m.reconnect = false
+	}
+
 	hostname, ok := metrics[0].Tags()["host"]
 	if !ok {
 		hostname = ""
@@ -815,6 +815,74 @@ func createMetricMessageHandler(acc telegraf.Accumulator, parser telegraf.Parser
 	}
 }
 
+func TestSecretChangedReconnect(t *testing.T) {
+	var clients []*mockClient
+	plugin := &MQTT{
+		MqttConfig: mqtt.MqttConfig{
+			Servers: []string{"tcp://localhost:1883"},
+		},
+		Topic: "telegraf",
+		Log:   testutil.Logger{},
+		newClient: func(*mqtt.MqttConfig) (mqtt.Client, error) {
+			client := &mockClient{}
+			clients = append(clients, client)
+			return client, nil
+		},
+	}
+	serializer := &serializers_influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	m := testutil.TestMetric(1.0, "test")
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	require.Len(t, clients, 1)
+	require.Equal(t, 1, clients[0].published)
+
+	// A changed secret causes a reconnect with a new client on the next write
+	plugin.SecretChanged("@{vault:mqtt_password}")
+	require.Len(t, clients, 1)
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	require.Len(t, clients, 2)
+	require.True(t, clients[0].closed)
+	require.True(t, clients[1].connected)
+	require.Equal(t, 1, clients[1].published)
+
+	// Further writes keep the connection
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	require.Len(t, clients, 2)
+	require.Equal(t, 2, clients[1].published)
+}
+
+type mockClient struct {
+	connected bool
+	closed    bool
+	published int
+}
+
+func (c *mockClient) Connect() (bool, error) {
+	c.connected = true
+	return false, nil
+}
+
+func (c *mockClient) Publish(string, []byte) error {
+	c.published++
+	return nil
+}
+
+func (*mockClient) SubscribeMultiple(map[string]byte, paho.MessageHandler) error {
+	return nil
+}
+
+func (*mockClient) AddRoute(string, paho.MessageHandler) {}
+
+func (c *mockClient) Close() error {
+	c.closed = true
+	return nil
+}
+
 func TestMissingServers(t *testing.T) {
 	plugin := &MQTT{}
 	require.ErrorContains(t, plugin.Init(), "no servers specified")
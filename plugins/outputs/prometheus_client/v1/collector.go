@@ -12,6 +12,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/influxdata/telegraf"
+	internal_prometheus "github.com/influxdata/telegraf/internal/prometheus"
 	serializers_prometheus "github.com/influxdata/telegraf/plugins/serializers/prometheus"
 )
 
@@ -235,7 +236,7 @@ func (c *Collector) addMetrics(metrics []telegraf.Metric) {
 
 		labels := make(map[string]string)
 		for k, v := range tags {
-			name, ok := serializers_prometheus.SanitizeLabelName(k)
+			name, ok := internal_prometheus.SanitizeLabelName(k)
 			if !ok {
 				continue
 			}
@@ -251,7 +252,7 @@ func (c *Collector) addMetrics(metrics []telegraf.Metric) {
 					continue
 				}
 
-				name, ok := serializers_prometheus.SanitizeLabelName(fn)
+				name, ok := internal_prometheus.SanitizeLabelName(fn)
 				if !ok {
 					continue
 				}
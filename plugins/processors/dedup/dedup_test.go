@@ -2,6 +2,8 @@ package dedup
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -530,3 +532,35 @@ func TestStatePersistence(t *testing.T) {
 	}
 	require.Len(t, actualState, expectedLen)
 }
+
+func TestStateConcurrentToApply(t *testing.T) {
+	plugin := &Dedup{
+		DedupInterval: config.Duration(10 * time.Minute),
+		FlushTime:     time.Now(),
+		Cache:         make(map[uint64]telegraf.Metric),
+	}
+
+	// Request the state while processing metrics, the race detector must not
+	// complain
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			m := metric.New("metric",
+				map[string]string{"tag": strconv.Itoa(i)},
+				map[string]interface{}{"foo": i},
+				time.Now(),
+			)
+			plugin.Apply(m)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		_, ok := plugin.GetState().([]byte)
+		require.True(t, ok)
+	}
+	<-done
+
+	state, ok := plugin.GetState().([]byte)
+	require.True(t, ok)
+	require.Len(t, strings.Split(strings.TrimSpace(string(state)), "\n"), 100)
+}
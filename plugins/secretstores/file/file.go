@@ -0,0 +1,283 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package file
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const (
+	formatVersion = 1
+	kdfScrypt     = "scrypt"
+	cipherXChaCha = "xchacha20-poly1305"
+
+	// Parameters for the key derivation of new files
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// header describes the encryption of the secrets and is authenticated
+// together with the encrypted data.
+type header struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	Salt    []byte `json:"salt"`
+	N       int    `json:"n"`
+	R       int    `json:"r"`
+	P       int    `json:"p"`
+	Cipher  string `json:"cipher"`
+}
+
+// content is the structure of the secrets file, the data contains the
+// encrypted JSON object of secrets.
+type content struct {
+	header
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+type File struct {
+	ID       string        `toml:"id"`
+	Path     string        `toml:"path"`
+	Password config.Secret `toml:"password"`
+
+	header  header
+	key     []byte
+	secrets map[string][]byte
+	sync.Mutex
+}
+
+func (*File) SampleConfig() string {
+	return sampleConfig
+}
+
+// Init initializes all internals of the secret-store
+func (f *File) Init() error {
+	defer f.Password.Destroy()
+
+	if f.ID == "" {
+		return errors.New("id missing")
+	}
+	if f.Path == "" {
+		return errors.New("path missing")
+	}
+
+	// Use the global password if none is specified for the store
+	password := &f.Password
+	if password.Empty() {
+		password = &config.Password
+	}
+	if password.Empty() {
+		return errors.New("password missing")
+	}
+	passwd, err := password.Get()
+	if err != nil {
+		return fmt.Errorf("getting password failed: %w", err)
+	}
+	defer passwd.Destroy()
+
+	// Read the existing secrets, the file is created when setting the first
+	// secret
+	var c content
+	buf, err := os.ReadFile(f.Path)
+	exists := err == nil
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return fmt.Errorf("generating salt failed: %w", err)
+		}
+		c.header = header{
+			Version: formatVersion,
+			KDF:     kdfScrypt,
+			Salt:    salt,
+			N:       scryptN,
+			R:       scryptR,
+			P:       scryptP,
+			Cipher:  cipherXChaCha,
+		}
+	case err != nil:
+		return fmt.Errorf("reading secrets failed: %w", err)
+	default:
+		if err := json.Unmarshal(buf, &c); err != nil {
+			return fmt.Errorf("parsing secrets file %q failed: %w", f.Path, err)
+		}
+		if c.Version != formatVersion {
+			return fmt.Errorf("unsupported secrets file version %d", c.Version)
+		}
+		if c.KDF != kdfScrypt {
+			return fmt.Errorf("unsupported key derivation %q", c.KDF)
+		}
+		if c.Cipher != cipherXChaCha {
+			return fmt.Errorf("unsupported cipher %q", c.Cipher)
+		}
+	}
+	f.header = c.header
+
+	f.key, err = scrypt.Key(passwd.Bytes(), f.header.Salt, f.header.N, f.header.R, f.header.P, chacha20poly1305.KeySize)
+	if err != nil {
+		return fmt.Errorf("deriving key failed: %w", err)
+	}
+
+	if !exists {
+		f.secrets = make(map[string][]byte)
+		return nil
+	}
+	f.secrets, err = f.decrypt(c.Nonce, c.Data)
+	return err
+}
+
+// Get searches for the given key and return the secret
+func (f *File) Get(key string) ([]byte, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	value, found := f.secrets[key]
+	if !found {
+		return nil, errors.New("not found")
+	}
+
+	// Return a copy as the caller might wipe the secret
+	return append([]byte(nil), value...), nil
+}
+
+// Set sets the given secret for the given key and writes the secrets file
+func (f *File) Set(key, value string) error {
+	f.Lock()
+	defer f.Unlock()
+
+	previous, found := f.secrets[key]
+	f.secrets[key] = []byte(value)
+	if err := f.write(); err != nil {
+		if found {
+			f.secrets[key] = previous
+		} else {
+			delete(f.secrets, key)
+		}
+		return err
+	}
+	return nil
+}
+
+// List lists all known secret keys
+func (f *File) List() ([]string, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	keys := make([]string, 0, len(f.secrets))
+	for k := range f.secrets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// GetResolver returns a function to resolve the given key.
+func (f *File) GetResolver(key string) (telegraf.ResolveFunc, error) {
+	resolver := func() ([]byte, bool, error) {
+		s, err := f.Get(key)
+		return s, false, err
+	}
+	return resolver, nil
+}
+
+func (f *File) decrypt(nonce, data []byte) (map[string][]byte, error) {
+	aead, err := chacha20poly1305.NewX(f.key)
+	if err != nil {
+		return nil, err
+	}
+	aad, err := json.Marshal(f.header)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid nonce in secrets file")
+	}
+
+	plaintext, err := aead.Open(nil, nonce, data, aad)
+	if err != nil {
+		return nil, errors.New("decrypting secrets failed, wrong password or corrupted file")
+	}
+
+	var secrets map[string][]byte
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("parsing secrets failed: %w", err)
+	}
+	if secrets == nil {
+		secrets = make(map[string][]byte)
+	}
+	return secrets, nil
+}
+
+// write encrypts the secrets and atomically replaces the secrets file
+func (f *File) write() error {
+	aead, err := chacha20poly1305.NewX(f.key)
+	if err != nil {
+		return err
+	}
+	aad, err := json.Marshal(f.header)
+	if err != nil {
+		return err
+	}
+	plaintext, err := json.Marshal(f.secrets)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("generating nonce failed: %w", err)
+	}
+	c := content{
+		header: f.header,
+		Nonce:  nonce,
+		Data:   aead.Seal(nil, nonce, plaintext, aad),
+	}
+	buf, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), filepath.Base(f.Path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating secrets file failed: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(buf); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing secrets file failed: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing secrets file failed: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.Path); err != nil {
+		return fmt.Errorf("replacing secrets file failed: %w", err)
+	}
+	return nil
+}
+
+// Register the secret-store on load.
+func init() {
+	secretstores.Add("file", func(id string) telegraf.SecretStore {
+		return &File{ID: id}
+	})
+}
@@ -0,0 +1,172 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+func TestSampleConfig(t *testing.T) {
+	plugin := &File{}
+	require.NotEmpty(t, plugin.SampleConfig())
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *File
+		expected string
+	}{
+		{
+			name:     "invalid id",
+			plugin:   &File{},
+			expected: "id missing",
+		},
+		{
+			name: "missing path",
+			plugin: &File{
+				ID: "test",
+			},
+			expected: "path missing",
+		},
+		{
+			name: "missing password",
+			plugin: &File{
+				ID:   "test",
+				Path: filepath.Join(t.TempDir(), "secrets.json"),
+			},
+			expected: "password missing",
+		},
+		{
+			name: "invalid password",
+			plugin: &File{
+				ID:       "test",
+				Path:     filepath.Join(t.TempDir(), "secrets.json"),
+				Password: config.NewSecret([]byte("@{unresolvable:secret}")),
+			},
+			expected: "getting password failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.plugin.Init()
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestSetListGet(t *testing.T) {
+	secrets := map[string]string{
+		"a secret":    "I won't tell",
+		"another one": "secret",
+		"foo":         "bar",
+	}
+	path := filepath.Join(t.TempDir(), "secrets.json")
+
+	// Initialize the plugin, the file is not created before setting secrets
+	plugin := &File{
+		ID:       "test",
+		Path:     path,
+		Password: config.NewSecret([]byte("test")),
+	}
+	require.NoError(t, plugin.Init())
+	require.NoFileExists(t, path)
+
+	// Store the secrets
+	for k, v := range secrets {
+		require.NoError(t, plugin.Set(k, v))
+	}
+
+	// The secrets must not be stored in clear text
+	buf, err := os.ReadFile(path)
+	require.NoError(t, err)
+	for k, v := range secrets {
+		require.NotContains(t, string(buf), k)
+		require.NotContains(t, string(buf), v)
+	}
+
+	// Check the secrets using a new instance reading the file
+	plugin = &File{
+		ID:       "test",
+		Path:     path,
+		Password: config.NewSecret([]byte("test")),
+	}
+	require.NoError(t, plugin.Init())
+
+	keys, err := plugin.List()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"a secret", "another one", "foo"}, keys)
+
+	for k, expected := range secrets {
+		resolver, err := plugin.GetResolver(k)
+		require.NoError(t, err)
+		s, dynamic, err := resolver()
+		require.NoError(t, err)
+		require.False(t, dynamic)
+		require.Equal(t, expected, string(s))
+	}
+
+	// Modify a secret
+	require.NoError(t, plugin.Set("foo", "baz"))
+	s, err := plugin.Get("foo")
+	require.NoError(t, err)
+	require.Equal(t, "baz", string(s))
+}
+
+func TestGetNotFound(t *testing.T) {
+	plugin := &File{
+		ID:       "test",
+		Path:     filepath.Join(t.TempDir(), "secrets.json"),
+		Password: config.NewSecret([]byte("test")),
+	}
+	require.NoError(t, plugin.Init())
+
+	_, err := plugin.Get("foo")
+	require.EqualError(t, err, "not found")
+}
+
+func TestWrongPassword(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.json")
+
+	plugin := &File{
+		ID:       "test",
+		Path:     path,
+		Password: config.NewSecret([]byte("test")),
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Set("foo", "bar"))
+
+	plugin = &File{
+		ID:       "test",
+		Path:     path,
+		Password: config.NewSecret([]byte("wrong")),
+	}
+	require.ErrorContains(t, plugin.Init(), "decrypting secrets failed")
+}
+
+func TestGlobalPassword(t *testing.T) {
+	config.Password = config.NewSecret([]byte("global"))
+	defer func() { config.Password = config.Secret{} }()
+
+	path := filepath.Join(t.TempDir(), "secrets.json")
+	plugin := &File{
+		ID:   "test",
+		Path: path,
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Set("foo", "bar"))
+
+	// The global password is not destroyed by the store
+	plugin = &File{
+		ID:   "test",
+		Path: path,
+	}
+	require.NoError(t, plugin.Init())
+	s, err := plugin.Get("foo")
+	require.NoError(t, err)
+	require.Equal(t, "bar", string(s))
+}
@@ -0,0 +1,618 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package vault
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const defaultIdleConnTimeoutMinutes = 5
+
+// leaseRetryInterval is the time to wait before retrying to renew or read a
+// lease after a failure in the background
+const leaseRetryInterval = 10 * time.Second
+
+type SecretConfig struct {
+	Key    string `toml:"key"`
+	Engine string `toml:"engine"`
+	Path   string `toml:"path"`
+	Field  string `toml:"field"`
+}
+
+type Vault struct {
+	Address            string          `toml:"address"`
+	Namespace          string          `toml:"namespace"`
+	AuthMethod         string          `toml:"auth_method"`
+	AuthMount          string          `toml:"auth_mount"`
+	Token              config.Secret   `toml:"token"`
+	RoleID             config.Secret   `toml:"role_id"`
+	SecretID           config.Secret   `toml:"secret_id"`
+	KubernetesRole     string          `toml:"kubernetes_role"`
+	KubernetesJWTPath  string          `toml:"kubernetes_token_file"`
+	LeaseRenewalMargin config.Duration `toml:"lease_renewal_margin"`
+	Secrets            []SecretConfig  `toml:"secret"`
+	Log                telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client      *http.Client
+	secrets     map[string]SecretConfig
+	paths       map[string]*sync.Mutex
+	loginMu     sync.Mutex
+	leases      map[string]*lease
+	token       string
+	tokenExpiry time.Time
+	callback    func(key string)
+	added       chan struct{}
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+
+	// Mutex guards the leases, the client token and the callback but is
+	// never held during requests. Requests for the same path are serialized
+	// using the path's lock and logins using the login lock.
+	sync.Mutex
+}
+
+// lease contains the data read from a path along with the lease information
+// for dynamic secrets. Static secrets never expire. Leases are not modified
+// after storing them but replaced.
+type lease struct {
+	id        string
+	renewable bool
+	expiry    time.Time
+	data      map[string]interface{}
+}
+
+// response is the common structure of Vault API responses
+type response struct {
+	LeaseID       string          `json:"lease_id"`
+	LeaseDuration int64           `json:"lease_duration"`
+	Renewable     bool            `json:"renewable"`
+	Data          json.RawMessage `json:"data"`
+	Auth          *struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int64  `json:"lease_duration"`
+	} `json:"auth"`
+	Errors []string `json:"errors"`
+}
+
+func (*Vault) SampleConfig() string {
+	return sampleConfig
+}
+
+// Init initializes all internals of the secret-store
+func (v *Vault) Init() error {
+	if v.Address == "" {
+		return errors.New("'address' missing")
+	}
+	v.Address = strings.TrimSuffix(v.Address, "/")
+
+	// Check the authentication settings
+	switch v.AuthMethod {
+	case "", "token":
+		v.AuthMethod = "token"
+		if v.Token.Empty() {
+			return errors.New("'token' required for token authentication")
+		}
+	case "approle":
+		if v.RoleID.Empty() {
+			return errors.New("'role_id' required for AppRole authentication")
+		}
+		if v.AuthMount == "" {
+			v.AuthMount = "approle"
+		}
+	case "kubernetes":
+		if v.KubernetesRole == "" {
+			return errors.New("'kubernetes_role' required for Kubernetes authentication")
+		}
+		if v.KubernetesJWTPath == "" {
+			v.KubernetesJWTPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+		}
+		if v.AuthMount == "" {
+			v.AuthMount = "kubernetes"
+		}
+	default:
+		return fmt.Errorf("authentication method %q not supported", v.AuthMethod)
+	}
+
+	// Check the secret definitions
+	v.secrets = make(map[string]SecretConfig, len(v.Secrets))
+	v.paths = make(map[string]*sync.Mutex)
+	for _, s := range v.Secrets {
+		if s.Key == "" {
+			return errors.New("'key' not specified")
+		}
+		if _, found := v.secrets[s.Key]; found {
+			return fmt.Errorf("secret with key %q already defined", s.Key)
+		}
+		switch s.Engine {
+		case "":
+			s.Engine = "kv2"
+		case "kv1", "kv2", "dynamic":
+		default:
+			return fmt.Errorf("engine %q of key %q not supported", s.Engine, s.Key)
+		}
+		if s.Path == "" {
+			return fmt.Errorf("'path' not specified for key %q", s.Key)
+		}
+		if s.Field == "" {
+			return fmt.Errorf("'field' not specified for key %q", s.Key)
+		}
+		s.Path = strings.Trim(s.Path, "/")
+		v.secrets[s.Key] = s
+		if _, found := v.paths[s.Path]; !found {
+			v.paths[s.Path] = &sync.Mutex{}
+		}
+	}
+	v.leases = make(map[string]*lease)
+	v.added = make(chan struct{}, 1)
+
+	// Prevent idle connections from hanging around forever on telegraf reload
+	if v.HTTPClientConfig.IdleConnTimeout == 0 {
+		v.HTTPClientConfig.IdleConnTimeout = config.Duration(defaultIdleConnTimeoutMinutes * time.Minute)
+	}
+	client, err := v.HTTPClientConfig.CreateClient(context.Background(), v.Log)
+	if err != nil {
+		return err
+	}
+	v.client = client
+
+	return nil
+}
+
+// Start renews the leases of dynamic secrets in the background
+func (v *Vault) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	v.cancel = cancel
+
+	v.wg.Add(1)
+	go func() {
+		defer v.wg.Done()
+		v.renewLeases(ctx)
+	}()
+	return nil
+}
+
+// Stop stops renewing the leases in the background
+func (v *Vault) Stop() {
+	if v.cancel != nil {
+		v.cancel()
+	}
+	v.wg.Wait()
+}
+
+// Get searches for the given key and return the secret
+func (v *Vault) Get(key string) ([]byte, error) {
+	s, found := v.secrets[key]
+	if !found {
+		return nil, errors.New("not found")
+	}
+
+	l, changed, err := v.lease(s)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		v.notify(s.Path)
+	}
+
+	value, found := l.data[s.Field]
+	if !found {
+		return nil, fmt.Errorf("field %q not found in %q", s.Field, s.Path)
+	}
+	if str, ok := value.(string); ok {
+		return []byte(str), nil
+	}
+	return json.Marshal(value)
+}
+
+// Set sets the given secret for the given key
+func (*Vault) Set(_, _ string) error {
+	return errors.New("setting secrets not supported")
+}
+
+// List lists all known secret keys
+func (v *Vault) List() ([]string, error) {
+	keys := make([]string, 0, len(v.secrets))
+	for k := range v.secrets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// GetResolver returns a function to resolve the given key.
+func (v *Vault) GetResolver(key string) (telegraf.ResolveFunc, error) {
+	s, found := v.secrets[key]
+	if !found {
+		return nil, fmt.Errorf("secret %q not defined", key)
+	}
+
+	// Dynamic secrets change whenever their lease expires
+	dynamic := s.Engine == "dynamic"
+	resolver := func() ([]byte, bool, error) {
+		s, err := v.Get(key)
+		return s, dynamic, err
+	}
+	return resolver, nil
+}
+
+// SetSecretChangeCallback registers the function to call when dynamic
+// secrets are read again due to an expiring lease.
+func (v *Vault) SetSecretChangeCallback(callback func(key string)) {
+	v.Lock()
+	defer v.Unlock()
+	v.callback = callback
+}
+
+// lease returns the data for the path of the given secret. Leases about to
+// expire are renewed if possible, otherwise the secret is read again and the
+// returned flag signals the changed data.
+func (v *Vault) lease(s SecretConfig) (*lease, bool, error) {
+	margin := time.Duration(v.LeaseRenewalMargin)
+
+	mu := v.paths[s.Path]
+	mu.Lock()
+	defer mu.Unlock()
+
+	v.Lock()
+	l, found := v.leases[s.Path]
+	v.Unlock()
+	if found && (l.expiry.IsZero() || time.Until(l.expiry) > margin) {
+		return l, false, nil
+	}
+
+	if found && l.renewable {
+		renewed, err := v.renew(l)
+		if err != nil {
+			v.Log.Debugf("Renewing lease for %q failed: %v", s.Path, err)
+		} else if time.Until(renewed.expiry) > margin {
+			v.Lock()
+			v.leases[s.Path] = renewed
+			v.Unlock()
+			return renewed, false, nil
+		}
+	}
+
+	l, err := v.read(s)
+	if err != nil {
+		return nil, false, err
+	}
+	v.Lock()
+	v.leases[s.Path] = l
+	v.Unlock()
+
+	// Wake up the background renewal to consider the new lease
+	select {
+	case v.added <- struct{}{}:
+	default:
+	}
+	return l, found, nil
+}
+
+// renewLeases renews or reads the leases again before they expire until the
+// context is cancelled. Changes of secrets read again are signaled.
+func (v *Vault) renewLeases(ctx context.Context) {
+	for {
+		timer := time.NewTimer(v.nextRenewal())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-v.added:
+			timer.Stop()
+			continue
+		case <-timer.C:
+		}
+
+		var changed []string
+		for _, s := range v.leasedSecrets() {
+			_, c, err := v.lease(s)
+			if err != nil {
+				v.Log.Errorf("Refreshing lease for %q failed: %v", s.Path, err)
+				continue
+			}
+			if c {
+				changed = append(changed, s.Path)
+			}
+		}
+
+		// Signal the new credentials
+		for _, path := range changed {
+			v.notify(path)
+		}
+	}
+}
+
+// nextRenewal returns the time until the next lease needs to be renewed. If
+// a lease is already due, e.g. after a failed renewal, the retry interval is
+// returned. Without expiring leases the renewal waits for new leases.
+func (v *Vault) nextRenewal() time.Duration {
+	v.Lock()
+	defer v.Unlock()
+
+	margin := time.Duration(v.LeaseRenewalMargin)
+	next := time.Duration(math.MaxInt64)
+	for _, l := range v.leases {
+		if l.expiry.IsZero() {
+			continue
+		}
+		next = min(next, time.Until(l.expiry)-margin)
+	}
+	if next <= 0 {
+		return leaseRetryInterval
+	}
+	return next
+}
+
+// leasedSecrets returns one secret for each path with an expiring lease
+func (v *Vault) leasedSecrets() []SecretConfig {
+	v.Lock()
+	defer v.Unlock()
+
+	paths := make(map[string]bool, len(v.leases))
+	secrets := make([]SecretConfig, 0, len(v.leases))
+	for _, s := range v.secrets {
+		l, found := v.leases[s.Path]
+		if !found || l.expiry.IsZero() || paths[s.Path] {
+			continue
+		}
+		paths[s.Path] = true
+		secrets = append(secrets, s)
+	}
+	return secrets
+}
+
+// notify signals the change of all secrets of the given path
+func (v *Vault) notify(path string) {
+	v.Lock()
+	callback := v.callback
+	v.Unlock()
+	if callback == nil {
+		return
+	}
+
+	for key, s := range v.secrets {
+		if s.Path == path {
+			callback(key)
+		}
+	}
+}
+
+// read queries the data of the secret's path and creates a lease for it
+func (v *Vault) read(s SecretConfig) (*lease, error) {
+	resp, err := v.request(http.MethodGet, s.Path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("reading %q failed: %w", s.Path, err)
+	}
+
+	l := &lease{}
+	switch s.Engine {
+	case "kv1":
+		err = json.Unmarshal(resp.Data, &l.data)
+	case "kv2":
+		var kv struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		err = json.Unmarshal(resp.Data, &kv)
+		l.data = kv.Data
+	case "dynamic":
+		err = json.Unmarshal(resp.Data, &l.data)
+		l.id = resp.LeaseID
+		l.renewable = resp.Renewable
+		if resp.LeaseDuration > 0 {
+			l.expiry = time.Now().Add(time.Duration(resp.LeaseDuration) * time.Second)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing data of %q failed: %w", s.Path, err)
+	}
+	if l.data == nil {
+		return nil, fmt.Errorf("no data found in %q", s.Path)
+	}
+	return l, nil
+}
+
+// renew extends the given lease and returns the renewed lease
+func (v *Vault) renew(l *lease) (*lease, error) {
+	body := map[string]interface{}{"lease_id": l.id}
+	resp, err := v.request(http.MethodPut, "sys/leases/renew", body)
+	if err != nil {
+		return nil, err
+	}
+	renewed := *l
+	renewed.renewable = resp.Renewable
+	renewed.expiry = time.Now().Add(time.Duration(resp.LeaseDuration) * time.Second)
+	return &renewed, nil
+}
+
+// clientToken returns the current client token and whether the token is
+// valid, i.e. not missing and not about to expire
+func (v *Vault) clientToken() (string, bool) {
+	v.Lock()
+	defer v.Unlock()
+
+	expired := !v.tokenExpiry.IsZero() && time.Until(v.tokenExpiry) <= time.Duration(v.LeaseRenewalMargin)
+	return v.token, v.token != "" && !expired
+}
+
+// login acquires a new client token replacing the given stale token using
+// the configured authentication method. Concurrent logins are serialized
+// and the token of another login is returned if it replaced the stale token
+// in the meantime.
+func (v *Vault) login(stale string) (string, error) {
+	v.loginMu.Lock()
+	defer v.loginMu.Unlock()
+
+	if current, valid := v.clientToken(); valid && current != stale {
+		return current, nil
+	}
+
+	if v.AuthMethod == "token" {
+		secret, err := v.Token.Get()
+		if err != nil {
+			return "", fmt.Errorf("getting token failed: %w", err)
+		}
+		token := strings.TrimSpace(secret.String())
+		secret.Destroy()
+
+		v.Lock()
+		v.token = token
+		v.Unlock()
+		return token, nil
+	}
+
+	body := make(map[string]interface{})
+	switch v.AuthMethod {
+	case "approle":
+		roleID, err := v.RoleID.Get()
+		if err != nil {
+			return "", fmt.Errorf("getting role ID failed: %w", err)
+		}
+		body["role_id"] = roleID.String()
+		roleID.Destroy()
+
+		if !v.SecretID.Empty() {
+			secretID, err := v.SecretID.Get()
+			if err != nil {
+				return "", fmt.Errorf("getting secret ID failed: %w", err)
+			}
+			body["secret_id"] = secretID.String()
+			secretID.Destroy()
+		}
+	case "kubernetes":
+		jwt, err := os.ReadFile(v.KubernetesJWTPath)
+		if err != nil {
+			return "", fmt.Errorf("reading service account token failed: %w", err)
+		}
+		body["role"] = v.KubernetesRole
+		body["jwt"] = strings.TrimSpace(string(jwt))
+	}
+
+	resp, err := v.do("", http.MethodPost, "auth/"+strings.Trim(v.AuthMount, "/")+"/login", body)
+	if err != nil {
+		return "", fmt.Errorf("login failed: %w", err)
+	}
+	if resp.Auth == nil || resp.Auth.ClientToken == "" {
+		return "", errors.New("login failed: no client token received")
+	}
+
+	var expiry time.Time
+	if resp.Auth.LeaseDuration > 0 {
+		expiry = time.Now().Add(time.Duration(resp.Auth.LeaseDuration) * time.Second)
+	}
+	v.Lock()
+	v.token = resp.Auth.ClientToken
+	v.tokenExpiry = expiry
+	v.Unlock()
+	return resp.Auth.ClientToken, nil
+}
+
+// request performs an authenticated request to the given API path. The
+// client token is acquired if missing or about to expire and, except for
+// static tokens, the login is retried once if the token was rejected.
+func (v *Vault) request(method, path string, body interface{}) (*response, error) {
+	token, valid := v.clientToken()
+	if !valid {
+		var err error
+		if token, err = v.login(token); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := v.do(token, method, path, body)
+	var serr *statusError
+	if v.AuthMethod != "token" && errors.As(err, &serr) && serr.code == http.StatusForbidden {
+		if token, err = v.login(token); err != nil {
+			return nil, err
+		}
+		return v.do(token, method, path, body)
+	}
+	return resp, err
+}
+
+type statusError struct {
+	code   int
+	errors []string
+}
+
+func (e *statusError) Error() string {
+	msg := fmt.Sprintf("received status code %d (%s)", e.code, http.StatusText(e.code))
+	if len(e.errors) > 0 {
+		msg += ": " + strings.Join(e.errors, "; ")
+	}
+	return msg
+}
+
+func (v *Vault) do(token, method, path string, body interface{}) (*response, error) {
+	var reader io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("encoding request failed: %w", err)
+		}
+		reader = bytes.NewReader(buf)
+	}
+
+	request, err := http.NewRequest(method, v.Address+"/v1/"+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("creating request failed: %w", err)
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" && !strings.HasPrefix(path, "auth/") {
+		request.Header.Set("X-Vault-Token", token)
+	}
+	if v.Namespace != "" {
+		request.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+
+	resp, err := v.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("executing request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading body failed: %w", err)
+	}
+
+	var r response
+	if len(buf) > 0 {
+		if err := json.Unmarshal(buf, &r); err != nil && resp.StatusCode == http.StatusOK {
+			return nil, fmt.Errorf("parsing response failed: %w", err)
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &statusError{code: resp.StatusCode, errors: r.Errors}
+	}
+	return &r, nil
+}
+
+// Register the secret-store on load.
+func init() {
+	secretstores.Add("vault", func(string) telegraf.SecretStore {
+		return &Vault{LeaseRenewalMargin: config.Duration(time.Minute)}
+	})
+}
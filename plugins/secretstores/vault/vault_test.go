@@ -0,0 +1,571 @@
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestSampleConfig(t *testing.T) {
+	plugin := &Vault{}
+	require.NotEmpty(t, plugin.SampleConfig())
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Vault
+		expected string
+	}{
+		{
+			name:     "no address",
+			plugin:   &Vault{},
+			expected: "'address' missing",
+		},
+		{
+			name:     "no token",
+			plugin:   &Vault{Address: "http://localhost:8200"},
+			expected: "'token' required for token authentication",
+		},
+		{
+			name:     "invalid auth method",
+			plugin:   &Vault{Address: "http://localhost:8200", AuthMethod: "foo"},
+			expected: `authentication method "foo" not supported`,
+		},
+		{
+			name:     "approle without role ID",
+			plugin:   &Vault{Address: "http://localhost:8200", AuthMethod: "approle"},
+			expected: "'role_id' required for AppRole authentication",
+		},
+		{
+			name:     "kubernetes without role",
+			plugin:   &Vault{Address: "http://localhost:8200", AuthMethod: "kubernetes"},
+			expected: "'kubernetes_role' required for Kubernetes authentication",
+		},
+		{
+			name: "secret without key",
+			plugin: &Vault{
+				Address: "http://localhost:8200",
+				Token:   config.NewSecret([]byte("root")),
+				Secrets: []SecretConfig{{}},
+			},
+			expected: "'key' not specified",
+		},
+		{
+			name: "invalid engine",
+			plugin: &Vault{
+				Address: "http://localhost:8200",
+				Token:   config.NewSecret([]byte("root")),
+				Secrets: []SecretConfig{{Key: "test", Engine: "foo"}},
+			},
+			expected: `engine "foo" of key "test" not supported`,
+		},
+		{
+			name: "secret without path",
+			plugin: &Vault{
+				Address: "http://localhost:8200",
+				Token:   config.NewSecret([]byte("root")),
+				Secrets: []SecretConfig{{Key: "test"}},
+			},
+			expected: `'path' not specified for key "test"`,
+		},
+		{
+			name: "secret without field",
+			plugin: &Vault{
+				Address: "http://localhost:8200",
+				Token:   config.NewSecret([]byte("root")),
+				Secrets: []SecretConfig{{Key: "test", Path: "secret/data/test"}},
+			},
+			expected: `'field' not specified for key "test"`,
+		},
+		{
+			name: "duplicate key",
+			plugin: &Vault{
+				Address: "http://localhost:8200",
+				Token:   config.NewSecret([]byte("root")),
+				Secrets: []SecretConfig{
+					{Key: "test", Path: "secret/data/test", Field: "a"},
+					{Key: "test", Path: "secret/data/test", Field: "b"},
+				},
+			},
+			expected: `secret with key "test" already defined`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestSetUnsupported(t *testing.T) {
+	plugin := &Vault{
+		Address: "http://localhost:8200",
+		Token:   config.NewSecret([]byte("root")),
+	}
+	require.NoError(t, plugin.Init())
+	require.ErrorContains(t, plugin.Set("key", "value"), "setting secrets not supported")
+}
+
+func TestGetKV(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" || r.Header.Get("X-Vault-Namespace") != "team" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"errors":["permission denied"]}`)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/myapp":
+			fmt.Fprint(w, `{"data":{"data":{"password":"s3cr3t","port":5432},"metadata":{"version":1}}}`)
+		case "/v1/kv/myapp":
+			fmt.Fprint(w, `{"lease_duration":2764800,"data":{"password":"0ld"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors":[]}`)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Vault{
+		Address:   server.URL,
+		Namespace: "team",
+		Token:     config.NewSecret([]byte("root")),
+		Secrets: []SecretConfig{
+			{Key: "password", Path: "secret/data/myapp", Field: "password"},
+			{Key: "port", Path: "/secret/data/myapp/", Field: "port"},
+			{Key: "old", Engine: "kv1", Path: "kv/myapp", Field: "password"},
+			{Key: "missing", Path: "secret/data/missing", Field: "password"},
+			{Key: "nofield", Path: "secret/data/myapp", Field: "user"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	keys, err := plugin.List()
+	require.NoError(t, err)
+	require.Equal(t, []string{"missing", "nofield", "old", "password", "port"}, keys)
+
+	resolver, err := plugin.GetResolver("password")
+	require.NoError(t, err)
+	s, dynamic, err := resolver()
+	require.NoError(t, err)
+	require.False(t, dynamic)
+	require.Equal(t, "s3cr3t", string(s))
+
+	s, err = plugin.Get("port")
+	require.NoError(t, err)
+	require.Equal(t, "5432", string(s))
+
+	s, err = plugin.Get("old")
+	require.NoError(t, err)
+	require.Equal(t, "0ld", string(s))
+
+	_, err = plugin.Get("missing")
+	require.ErrorContains(t, err, "received status code 404")
+
+	_, err = plugin.Get("nofield")
+	require.ErrorContains(t, err, `field "user" not found`)
+
+	_, err = plugin.Get("foo")
+	require.ErrorContains(t, err, "not found")
+	_, err = plugin.GetResolver("foo")
+	require.ErrorContains(t, err, `secret "foo" not defined`)
+}
+
+func TestGetPermissionDenied(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"errors":["permission denied"]}`)
+	}))
+	defer server.Close()
+
+	plugin := &Vault{
+		Address: server.URL,
+		Token:   config.NewSecret([]byte("root")),
+		Secrets: []SecretConfig{{Key: "password", Path: "secret/data/myapp", Field: "password"}},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	_, err := plugin.Get("password")
+	require.ErrorContains(t, err, "received status code 403 (Forbidden): permission denied")
+}
+
+func TestDynamicRenewal(t *testing.T) {
+	var reads, renewals int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/database/creds/readonly":
+			reads++
+			fmt.Fprintf(w, `{
+				"lease_id":"database/creds/readonly/%d",
+				"lease_duration":10,
+				"renewable":true,
+				"data":{"username":"user%d","password":"pass%d"}
+			}`, reads, reads, reads)
+		case "/v1/sys/leases/renew":
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["lease_id"] != "database/creds/readonly/1" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			renewals++
+			fmt.Fprintf(w, `{"lease_id":%q,"lease_duration":3600,"renewable":true}`, body["lease_id"])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Vault{
+		Address:            server.URL,
+		Token:              config.NewSecret([]byte("root")),
+		LeaseRenewalMargin: config.Duration(time.Minute),
+		Secrets: []SecretConfig{
+			{Key: "username", Engine: "dynamic", Path: "database/creds/readonly", Field: "username"},
+			{Key: "password", Engine: "dynamic", Path: "database/creds/readonly", Field: "password"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	resolver, err := plugin.GetResolver("username")
+	require.NoError(t, err)
+	s, dynamic, err := resolver()
+	require.NoError(t, err)
+	require.True(t, dynamic)
+	require.Equal(t, "user1", string(s))
+
+	// The lease expires within the margin so it is renewed, keeping the
+	// credentials of the first read
+	s, err = plugin.Get("password")
+	require.NoError(t, err)
+	require.Equal(t, "pass1", string(s))
+	s, err = plugin.Get("username")
+	require.NoError(t, err)
+	require.Equal(t, "user1", string(s))
+
+	require.Equal(t, 1, reads)
+	require.Equal(t, 1, renewals)
+}
+
+func TestDynamicRefetch(t *testing.T) {
+	var reads int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/database/creds/readonly" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		reads++
+		fmt.Fprintf(w, `{
+			"lease_id":"database/creds/readonly/%d",
+			"lease_duration":10,
+			"renewable":false,
+			"data":{"username":"user%d","password":"pass%d"}
+		}`, reads, reads, reads)
+	}))
+	defer server.Close()
+
+	plugin := &Vault{
+		Address:            server.URL,
+		Token:              config.NewSecret([]byte("root")),
+		LeaseRenewalMargin: config.Duration(time.Minute),
+		Secrets: []SecretConfig{
+			{Key: "password", Engine: "dynamic", Path: "database/creds/readonly", Field: "password"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var changed []string
+	plugin.SetSecretChangeCallback(func(key string) {
+		changed = append(changed, key)
+	})
+
+	// The lease is not renewable and expires within the margin so the
+	// credentials are read again and the change is signaled
+	for i := 1; i <= 3; i++ {
+		s, err := plugin.Get("password")
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("pass%d", i), string(s))
+	}
+	require.Equal(t, 3, reads)
+	require.Equal(t, []string{"password", "password"}, changed)
+}
+
+func TestDynamicBackgroundRenewal(t *testing.T) {
+	var reads, renewals atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/database/creds/readonly":
+			n := reads.Add(1)
+			fmt.Fprintf(w, `{
+				"lease_id":"database/creds/readonly/%d",
+				"lease_duration":2,
+				"renewable":true,
+				"data":{"username":"user%d","password":"pass%d"}
+			}`, n, n, n)
+		case "/v1/sys/leases/renew":
+			renewals.Add(1)
+			fmt.Fprint(w, `{"lease_id":"database/creds/readonly/1","lease_duration":3600,"renewable":true}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Vault{
+		Address:            server.URL,
+		Token:              config.NewSecret([]byte("root")),
+		LeaseRenewalMargin: config.Duration(time.Second),
+		Secrets: []SecretConfig{
+			{Key: "password", Engine: "dynamic", Path: "database/creds/readonly", Field: "password"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Start())
+	defer plugin.Stop()
+
+	s, err := plugin.Get("password")
+	require.NoError(t, err)
+	require.Equal(t, "pass1", string(s))
+
+	// The lease is renewed in the background one second before it expires
+	// without accessing the secret
+	require.Eventually(t, func() bool {
+		return renewals.Load() == 1
+	}, 5*time.Second, 100*time.Millisecond)
+
+	s, err = plugin.Get("password")
+	require.NoError(t, err)
+	require.Equal(t, "pass1", string(s))
+	require.Equal(t, int32(1), reads.Load())
+	require.Equal(t, int32(1), renewals.Load())
+}
+
+func TestDynamicBackgroundRefetch(t *testing.T) {
+	var reads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/database/creds/readonly" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		n := reads.Add(1)
+		duration := 2
+		if n > 1 {
+			duration = 3600
+		}
+		fmt.Fprintf(w, `{
+			"lease_id":"database/creds/readonly/%d",
+			"lease_duration":%d,
+			"renewable":false,
+			"data":{"username":"user%d","password":"pass%d"}
+		}`, n, duration, n, n)
+	}))
+	defer server.Close()
+
+	plugin := &Vault{
+		Address:            server.URL,
+		Token:              config.NewSecret([]byte("root")),
+		LeaseRenewalMargin: config.Duration(time.Second),
+		Secrets: []SecretConfig{
+			{Key: "username", Engine: "dynamic", Path: "database/creds/readonly", Field: "username"},
+			{Key: "password", Engine: "dynamic", Path: "database/creds/readonly", Field: "password"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	changed := make(chan string, 2)
+	plugin.SetSecretChangeCallback(func(key string) {
+		changed <- key
+	})
+	require.NoError(t, plugin.Start())
+	defer plugin.Stop()
+
+	s, err := plugin.Get("password")
+	require.NoError(t, err)
+	require.Equal(t, "pass1", string(s))
+
+	// The lease is not renewable so the credentials are read again in the
+	// background and the change of all keys of the path is signaled
+	keys := make([]string, 0, 2)
+	for len(keys) < 2 {
+		select {
+		case key := <-changed:
+			keys = append(keys, key)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "change not signaled")
+		}
+	}
+	require.ElementsMatch(t, []string{"username", "password"}, keys)
+
+	s, err = plugin.Get("password")
+	require.NoError(t, err)
+	require.Equal(t, "pass2", string(s))
+	require.Equal(t, int32(2), reads.Load())
+	require.Empty(t, changed)
+}
+
+func TestGetNotBlockedBySlowRequest(t *testing.T) {
+	requested := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/database/creds/readonly":
+			close(requested)
+			<-release
+			fmt.Fprint(w, `{"lease_id":"database/creds/readonly/1","lease_duration":3600,"data":{"password":"pass1"}}`)
+		case "/v1/secret/data/myapp":
+			fmt.Fprint(w, `{"data":{"data":{"password":"secret"}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Vault{
+		Address:            server.URL,
+		Token:              config.NewSecret([]byte("root")),
+		LeaseRenewalMargin: config.Duration(time.Minute),
+		Secrets: []SecretConfig{
+			{Key: "dynamic", Engine: "dynamic", Path: "database/creds/readonly", Field: "password"},
+			{Key: "static", Path: "secret/data/myapp", Field: "password"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	slow := make(chan error, 1)
+	go func() {
+		_, err := plugin.Get("dynamic")
+		slow <- err
+	}()
+	<-requested
+
+	// Secrets of other paths must be resolvable while the request is pending
+	s, err := plugin.Get("static")
+	require.NoError(t, err)
+	require.Equal(t, "secret", string(s))
+
+	close(release)
+	require.NoError(t, <-slow)
+}
+
+func TestAppRole(t *testing.T) {
+	var logins int
+	var token string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/custom/login":
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if body["role_id"] != "myrole" || body["secret_id"] != "mysecret" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"errors":["invalid role or secret ID"]}`)
+				return
+			}
+			logins++
+			token = fmt.Sprintf("token%d", logins)
+			fmt.Fprintf(w, `{"auth":{"client_token":%q,"lease_duration":3600,"renewable":true}}`, token)
+		case "/v1/secret/data/myapp":
+			if r.Header.Get("X-Vault-Token") != token {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"errors":["permission denied"]}`)
+				return
+			}
+			fmt.Fprint(w, `{"data":{"data":{"password":"s3cr3t"}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Vault{
+		Address:    server.URL,
+		AuthMethod: "approle",
+		AuthMount:  "custom",
+		RoleID:     config.NewSecret([]byte("myrole")),
+		SecretID:   config.NewSecret([]byte("mysecret")),
+		Secrets: []SecretConfig{
+			{Key: "password", Path: "secret/data/myapp", Field: "password"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	s, err := plugin.Get("password")
+	require.NoError(t, err)
+	require.Equal(t, "s3cr3t", string(s))
+	require.Equal(t, 1, logins)
+
+	// Revoke the token and make sure the store logs in again
+	token = "revoked"
+	delete(plugin.leases, "secret/data/myapp")
+	s, err = plugin.Get("password")
+	require.NoError(t, err)
+	require.Equal(t, "s3cr3t", string(s))
+	require.Equal(t, 2, logins)
+}
+
+func TestKubernetes(t *testing.T) {
+	jwtFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(jwtFile, []byte("my.jwt.token\n"), 0600))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/kubernetes/login":
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if body["role"] != "telegraf" || body["jwt"] != "my.jwt.token" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"auth":{"client_token":"k8stoken","lease_duration":3600}}`)
+		case "/v1/secret/data/myapp":
+			if r.Header.Get("X-Vault-Token") != "k8stoken" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			fmt.Fprint(w, `{"data":{"data":{"password":"s3cr3t"}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Vault{
+		Address:           server.URL,
+		AuthMethod:        "kubernetes",
+		KubernetesRole:    "telegraf",
+		KubernetesJWTPath: jwtFile,
+		Secrets: []SecretConfig{
+			{Key: "password", Path: "secret/data/myapp", Field: "password"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	s, err := plugin.Get("password")
+	require.NoError(t, err)
+	require.Equal(t, "s3cr3t", string(s))
+}
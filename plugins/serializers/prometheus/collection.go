@@ -11,6 +11,7 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	"github.com/influxdata/telegraf"
+	internal_prometheus "github.com/influxdata/telegraf/internal/prometheus"
 )
 
 const helpString = "Telegraf collected metric"
@@ -137,7 +138,7 @@ func (c *Collection) createLabels(metric telegraf.Metric) []labelPair {
 			}
 		}
 
-		name, ok := SanitizeLabelName(tag.Key)
+		name, ok := internal_prometheus.SanitizeLabelName(tag.Key)
 		if !ok {
 			continue
 		}
@@ -156,7 +157,7 @@ func (c *Collection) createLabels(metric telegraf.Metric) []labelPair {
 			continue
 		}
 
-		name, ok := SanitizeLabelName(field.Key)
+		name, ok := internal_prometheus.SanitizeLabelName(field.Key)
 		if !ok {
 			continue
 		}
@@ -184,7 +185,7 @@ func (c *Collection) Add(metric telegraf.Metric, now time.Time) {
 	labels := c.createLabels(metric)
 	for _, field := range metric.FieldList() {
 		metricName := MetricName(metric.Name(), field.Key, metric.Type())
-		metricName, ok := SanitizeMetricName(metricName)
+		metricName, ok := internal_prometheus.SanitizeMetricName(metricName)
 		if !ok {
 			continue
 		}
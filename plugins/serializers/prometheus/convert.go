@@ -2,106 +2,44 @@ package prometheus
 
 import (
 	"strings"
-	"unicode"
 
 	dto "github.com/prometheus/client_model/go"
-	"github.com/prometheus/common/model"
 
 	"github.com/influxdata/telegraf"
+	internal_prometheus "github.com/influxdata/telegraf/internal/prometheus"
 )
 
-type Table struct {
-	First *unicode.RangeTable
-	Rest  *unicode.RangeTable
-}
-
-var MetricNameTable = Table{
-	First: &unicode.RangeTable{
-		R16: []unicode.Range16{
-			{0x003A, 0x003A, 1}, // :
-			{0x0041, 0x005A, 1}, // A-Z
-			{0x005F, 0x005F, 1}, // _
-			{0x0061, 0x007A, 1}, // a-z
-		},
-		LatinOffset: 4,
-	},
-	Rest: &unicode.RangeTable{
-		R16: []unicode.Range16{
-			{0x0030, 0x003A, 1}, // 0-:
-			{0x0041, 0x005A, 1}, // A-Z
-			{0x005F, 0x005F, 1}, // _
-			{0x0061, 0x007A, 1}, // a-z
-		},
-		LatinOffset: 4,
-	},
-}
+// Table is the character table used for sanitizing names.
+//
+// Deprecated: Use the Table type of the internal/prometheus package instead.
+type Table = internal_prometheus.Table
 
-var LabelNameTable = Table{
-	First: &unicode.RangeTable{
-		R16: []unicode.Range16{
-			{0x0041, 0x005A, 1}, // A-Z
-			{0x005F, 0x005F, 1}, // _
-			{0x0061, 0x007A, 1}, // a-z
-		},
-		LatinOffset: 3,
-	},
-	Rest: &unicode.RangeTable{
-		R16: []unicode.Range16{
-			{0x0030, 0x0039, 1}, // 0-9
-			{0x0041, 0x005A, 1}, // A-Z
-			{0x005F, 0x005F, 1}, // _
-			{0x0061, 0x007A, 1}, // a-z
-		},
-		LatinOffset: 4,
-	},
-}
+// MetricNameTable contains the characters allowed in Prometheus metric names.
+//
+// Deprecated: Use the MetricNameTable of the internal/prometheus package instead.
+var MetricNameTable = internal_prometheus.MetricNameTable
 
-// Sanitize checks if the name is valid according to the table.  If not, it
-// attempts to replaces invalid runes with an underscore to create a valid
-// name.
-func sanitize(name string, table Table) (string, bool) {
-	var b strings.Builder
+// LabelNameTable contains the characters allowed in Prometheus label names.
+//
+// Deprecated: Use the LabelNameTable of the internal/prometheus package instead.
+var LabelNameTable = internal_prometheus.LabelNameTable
 
-	for i, r := range name {
-		switch {
-		case i == 0:
-			if unicode.In(r, table.First) {
-				b.WriteRune(r)
-			}
-		default:
-			if unicode.In(r, table.Rest) {
-				b.WriteRune(r)
-			} else {
-				b.WriteString("_")
-			}
-		}
-	}
-
-	name = strings.Trim(b.String(), "_")
-	if name == "" {
-		return "", false
-	}
-	return name, true
-}
-
-// SanitizeMetricName checks if the name is a valid Prometheus metric name.  If
-// not, it attempts to replaces invalid runes with an underscore to create a
+// SanitizeMetricName checks if the name is a valid Prometheus metric name. If
+// not, it attempts to replace invalid runes with an underscore to create a
 // valid name.
+//
+// Deprecated: Use SanitizeMetricName of the internal/prometheus package instead.
 func SanitizeMetricName(name string) (string, bool) {
-	if model.IsValidMetricName(model.LabelValue(name)) {
-		return name, true
-	}
-	return sanitize(name, MetricNameTable)
+	return internal_prometheus.SanitizeMetricName(name)
 }
 
-// SanitizeLabelName checks if the name is a valid Prometheus label name.  If
-// not, it attempts to replaces invalid runes with an underscore to create a
+// SanitizeLabelName checks if the name is a valid Prometheus label name. If
+// not, it attempts to replace invalid runes with an underscore to create a
 // valid name.
+//
+// Deprecated: Use SanitizeLabelName of the internal/prometheus package instead.
 func SanitizeLabelName(name string) (string, bool) {
-	if model.LabelName(name).IsValidLegacy() {
-		return name, true
-	}
-	return sanitize(name, LabelNameTable)
+	return internal_prometheus.SanitizeLabelName(name)
 }
 
 // MetricName returns the Prometheus metric name.
@@ -13,6 +13,7 @@ import (
 	"github.com/prometheus/prometheus/prompb"
 
 	"github.com/influxdata/telegraf"
+	internal_prometheus "github.com/influxdata/telegraf/internal/prometheus"
 	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/telegraf/plugins/serializers/prometheus"
 )
@@ -47,7 +48,7 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 		var promts prompb.TimeSeries
 		for _, field := range metric.FieldList() {
 			metricName := prometheus.MetricName(metric.Name(), field.Key, metric.Type())
-			metricName, ok := prometheus.SanitizeMetricName(metricName)
+			metricName, ok := internal_prometheus.SanitizeMetricName(metricName)
 			if !ok {
 				traceAndKeepErr("failed to parse metric name %q", metricName)
 				continue
@@ -266,7 +267,7 @@ func (s *Serializer) appendCommonLabels(labels []prompb.Label, metric telegraf.M
 			}
 		}
 
-		name, ok := prometheus.SanitizeLabelName(tag.Key)
+		name, ok := internal_prometheus.SanitizeLabelName(tag.Key)
 		if !ok {
 			continue
 		}
@@ -289,7 +290,7 @@ func (s *Serializer) appendCommonLabels(labels []prompb.Label, metric telegraf.M
 			continue
 		}
 
-		name, ok := prometheus.SanitizeLabelName(field.Key)
+		name, ok := internal_prometheus.SanitizeLabelName(field.Key)
 		if !ok {
 			continue
 		}
@@ -23,3 +23,33 @@ type SecretStore interface {
 // the secret will not change over time, or dynamic (true) to handle
 // secrets that change over time (e.g. TOTP).
 type ResolveFunc func() ([]byte, bool, error)
+
+// SecretStoreService is an optional interface for secret-stores running
+// background tasks, e.g. to renew leases of their secrets.
+type SecretStoreService interface {
+	// Start the background tasks of the secret-store. Secrets must be
+	// resolvable before starting and after stopping the secret-store.
+	Start() error
+
+	// Stop the background tasks of the secret-store
+	Stop()
+}
+
+// SecretChangeNotifier is an optional interface for secret-stores able to
+// signal changes of their secrets, e.g. after rotating credentials.
+type SecretChangeNotifier interface {
+	// SetSecretChangeCallback registers the function to call with the key of
+	// a secret whenever the secret's value changed. The callback must not be
+	// called while holding locks required for resolving secrets.
+	SetSecretChangeCallback(callback func(key string))
+}
+
+// SecretChangeHandler is an optional interface for plugins to be notified
+// about changes of dynamic secrets referenced in their configuration, e.g.
+// to reconnect using the new credentials.
+type SecretChangeHandler interface {
+	// SecretChanged is called with the reference of the changed secret in
+	// the form "@{<store-id>:<key>}". The function is called asynchronously
+	// and might be called concurrently to other plugin functions.
+	SecretChanged(ref string)
+}
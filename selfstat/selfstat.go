@@ -102,6 +102,54 @@ func Metrics() []telegraf.Metric {
 	return metrics
 }
 
+// Histogram is the cumulative distribution of the values of a timing stat.
+type Histogram struct {
+	// Count is the number of values received
+	Count uint64
+	// Sum is the sum of all values received
+	Sum int64
+	// Buckets contains the number of values less than or equal to the
+	// bucket's upper bound
+	Buckets map[float64]uint64
+}
+
+// Sample is the current state of a registered stat.
+type Sample struct {
+	Measurement string
+	Field       string
+	Tags        map[string]string
+	// Value is the value of regular stats
+	Value int64
+	// Histogram is the distribution of the values of timing stats
+	Histogram *Histogram
+}
+
+// Samples returns the current state of all registered stats. In contrast to
+// Metrics(), timing stats are not reset but reported as histogram of all
+// timings received since registering the stat.
+func Samples() []Sample {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	samples := make([]Sample, 0, len(registry.stats))
+	for _, stats := range registry.stats {
+		for _, stat := range stats {
+			s := Sample{
+				Measurement: stat.Name(),
+				Field:       stat.FieldName(),
+				Tags:        stat.Tags(),
+			}
+			if ts, ok := stat.(*timingStat); ok {
+				s.Histogram = ts.histogram()
+			} else {
+				s.Value = stat.Get()
+			}
+			samples = append(samples, s)
+		}
+	}
+	return samples
+}
+
 type Registry struct {
 	stats map[uint64]map[string]Stat
 	mu    sync.Mutex
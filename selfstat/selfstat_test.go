@@ -211,3 +211,51 @@ func TestRegisterCopy(t *testing.T) {
 	tags["new"] = "value"
 	require.NotEqual(t, tags, stat.Tags())
 }
+
+func TestSamples(t *testing.T) {
+	testLock.Lock()
+	defer testCleanup()
+	s := Register("test", "counter", map[string]string{"test": "foo"})
+	ts := RegisterTiming("test", "duration_ns", map[string]string{"test": "foo"})
+
+	s.Incr(3)
+	ts.Incr(1_000_000)
+	ts.Incr(200_000_000)
+	ts.Incr(20_000_000_000)
+
+	// Other tests might leave stats in the registry
+	var samples []Sample
+	for _, sample := range Samples() {
+		if sample.Measurement == "internal_test" {
+			samples = append(samples, sample)
+		}
+	}
+	require.Len(t, samples, 2)
+	for _, sample := range samples {
+		require.Equal(t, map[string]string{"test": "foo"}, sample.Tags)
+		switch sample.Field {
+		case "counter":
+			require.Equal(t, int64(3), sample.Value)
+			require.Nil(t, sample.Histogram)
+		case "duration_ns":
+			require.NotNil(t, sample.Histogram)
+			require.Equal(t, uint64(3), sample.Histogram.Count)
+			require.Equal(t, int64(20_201_000_000), sample.Histogram.Sum)
+			require.Len(t, sample.Histogram.Buckets, len(timingBuckets))
+			require.Equal(t, uint64(1), sample.Histogram.Buckets[5e6])
+			require.Equal(t, uint64(1), sample.Histogram.Buckets[1e8])
+			require.Equal(t, uint64(2), sample.Histogram.Buckets[2.5e8])
+			require.Equal(t, uint64(2), sample.Histogram.Buckets[1e10])
+		default:
+			require.Failf(t, "unexpected field", "field %q", sample.Field)
+		}
+	}
+
+	// The histogram is not reset by collecting the metrics
+	require.Equal(t, int64(6_733_666_666), ts.Get())
+	for _, sample := range Samples() {
+		if sample.Histogram != nil {
+			require.Equal(t, uint64(3), sample.Histogram.Count)
+		}
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"sync"
 )
 
+// timingBuckets are the upper bounds of the histogram buckets of timing stats
+// in nanoseconds, ranging from 5 milliseconds to 10 seconds
+var timingBuckets = []float64{5e6, 1e7, 2.5e7, 5e7, 1e8, 2.5e8, 5e8, 1e9, 2.5e9, 5e9, 1e10}
+
 type timingStat struct {
 	measurement string
 	field       string
@@ -12,12 +16,29 @@ type timingStat struct {
 	prev        int64
 	count       int64
 	mu          sync.Mutex
+
+	// Cumulative histogram of all timings, not reset by Get()
+	buckets []uint64
+	sum     int64
+	total   uint64
 }
 
 func (s *timingStat) Incr(v int64) {
 	s.mu.Lock()
 	s.v += v
 	s.count++
+
+	if s.buckets == nil {
+		s.buckets = make([]uint64, len(timingBuckets))
+	}
+	for i, bound := range timingBuckets {
+		if float64(v) <= bound {
+			s.buckets[i]++
+			break
+		}
+	}
+	s.sum += v
+	s.total++
 	s.mu.Unlock()
 }
 
@@ -39,6 +60,26 @@ func (s *timingStat) Get() int64 {
 	return avg
 }
 
+// histogram returns the cumulative histogram of all timings received
+func (s *timingStat) histogram() *Histogram {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h := &Histogram{
+		Count:   s.total,
+		Sum:     s.sum,
+		Buckets: make(map[float64]uint64, len(timingBuckets)),
+	}
+	var cumulative uint64
+	for i, bound := range timingBuckets {
+		if s.buckets != nil {
+			cumulative += s.buckets[i]
+		}
+		h.Buckets[bound] = cumulative
+	}
+	return h
+}
+
 func (s *timingStat) Name() string {
 	return s.measurement
 }
@@ -175,6 +175,30 @@ func (s *selection) extractPluginsFromConfig(buf []byte) error {
 	}
 
 	for category, subtbl := range table.Fields {
+		// The state backend and features of the agent might require optional
+		// packages
+		if category == "agent" {
+			if backend := extractStatefileBackend(subtbl); backend != "" {
+				key := "persister." + backend
+				cfg := instance{
+					category: "persister",
+					name:     backend,
+					enabled:  true,
+				}
+				s.plugins[key] = append(s.plugins[key], cfg)
+			}
+			if extractAgentOption(subtbl, "selfstat_address") != "" {
+				key := "agent.selfstat_server"
+				cfg := instance{
+					category: "agent",
+					name:     "selfstat_server",
+					enabled:  true,
+				}
+				s.plugins[key] = append(s.plugins[key], cfg)
+			}
+			continue
+		}
+
 		// Check if we should handle the category, i.e. it contains plugins
 		// to configure.
 		var valid bool
@@ -227,6 +251,35 @@ func (s *selection) extractPluginsFromConfig(buf []byte) error {
 	return nil
 }
 
+// extractStatefileBackend returns the state backend configured in the given
+// agent table if it is not one of the built-in backends.
+func extractStatefileBackend(data interface{}) string {
+	switch backend := extractAgentOption(data, "statefile_backend"); backend {
+	case "", "file", "directory":
+		return ""
+	default:
+		return backend
+	}
+}
+
+// extractAgentOption returns the value of the given string option of the
+// agent table or an empty string if the option is not set.
+func extractAgentOption(data interface{}, name string) string {
+	tbl, ok := data.(*ast.Table)
+	if !ok {
+		return ""
+	}
+	kv, ok := tbl.Fields[name].(*ast.KeyValue)
+	if !ok {
+		return ""
+	}
+	option, ok := kv.Value.(*ast.String)
+	if !ok {
+		return ""
+	}
+	return option.Value
+}
+
 func trimBOM(f []byte) []byte {
 	return bytes.TrimPrefix(f, []byte("\xef\xbb\xbf"))
 }
@@ -148,9 +148,53 @@ func (p *packageCollection) CollectAvailable() error {
 		}
 	}
 
+	if err := p.collectStateBackends(); err != nil {
+		return err
+	}
+	p.collectAgentFeatures()
+
+	return nil
+}
+
+// collectStateBackends adds the optional backends for storing the states of
+// plugins, the "file" and "directory" backends are always built in.
+func (p *packageCollection) collectStateBackends() error {
+	var entries []packageInfo
+
+	backendDir := filepath.Join(p.root, "persister", "backends")
+	elements, err := os.ReadDir(backendDir)
+	if err != nil {
+		return err
+	}
+
+	for _, element := range elements {
+		if !element.IsDir() || element.Name() == "all" {
+			continue
+		}
+		entries = append(entries, packageInfo{
+			Category: "persister",
+			Plugin:   element.Name(),
+			Path:     filepath.ToSlash(filepath.Join("persister", "backends", element.Name())),
+			Tag:      "persister." + element.Name(),
+		})
+	}
+	p.packages["persister"] = entries
+
 	return nil
 }
 
+// collectAgentFeatures adds the optional features of the agent
+func (p *packageCollection) collectAgentFeatures() {
+	p.packages["agent"] = []packageInfo{
+		{
+			Category: "agent",
+			Plugin:   "selfstat_server",
+			Path:     "agent",
+			Tag:      "agent.selfstat_server",
+		},
+	}
+}
+
 func (p *packageCollection) ExtractTags() []string {
 	var tags []string
 	for category, pkgs := range p.packages {